@@ -0,0 +1,68 @@
+// Command docxer-gen generates a Go struct describing the data of a DOCX
+// template and a typed function rendering the template from it. It is meant
+// to be run by go generate:
+//
+//	//go:generate go run github.com/aliamerj/docxer/cmd/docxer-gen -template invoice.docx -type Invoice
+//
+// Top-level placeholders become string fields, {{#if}} conditions bool fields
+// and {{#each}} loops slices of an element struct. The code is written to
+// <template>_docx.go unless -o is given; "-o -" writes it to standard output.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aliamerj/docxer/internal/codegen"
+	"github.com/aliamerj/docxer/internal/lint"
+)
+
+func main() {
+	templatePath := flag.String("template", "", "DOCX template to generate the types of")
+	typeName := flag.String("type", "", "name of the generated struct")
+	packageName := flag.String("package", os.Getenv("GOPACKAGE"), "package of the generated file")
+	output := flag.String("o", "", "output file")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: docxer-gen -template template.docx -type Name [-package name] [-o file]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *templatePath == "" || *typeName == "" || *packageName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	references, err := lint.References(*templatePath)
+	if err != nil {
+		fatal(fmt.Errorf("%s: %w", *templatePath, err))
+	}
+	source, err := codegen.Generate(references, codegen.Options{
+		Package:  *packageName,
+		Type:     *typeName,
+		Template: filepath.Base(*templatePath),
+	})
+	if err != nil {
+		fatal(err)
+	}
+
+	switch *output {
+	case "-":
+		_, err = os.Stdout.Write(source)
+	case "":
+		name := strings.TrimSuffix(*templatePath, filepath.Ext(*templatePath)) + "_docx.go"
+		err = os.WriteFile(name, source, 0o644)
+	default:
+		err = os.WriteFile(*output, source, 0o644)
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "docxer-gen:", err)
+	os.Exit(2)
+}
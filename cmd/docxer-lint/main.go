@@ -0,0 +1,68 @@
+// Command docxer-lint checks DOCX templates for placeholder mistakes before
+// they are used.
+//
+//	docxer-lint [-data sample.json] [-schema schema.json] template.docx...
+//
+// It prints one line per issue and exits with status 1 when any were found.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/aliamerj/docxer/pkg/docxer"
+)
+
+func main() {
+	dataPath := flag.String("data", "", "JSON file with a sample of the data the template is filled with")
+	schemaPath := flag.String("schema", "", "JSON Schema describing the data the template is filled with")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: docxer-lint [-data sample.json] [-schema schema.json] template.docx...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var options docxer.LintOptions
+	if *dataPath != "" {
+		content, err := os.ReadFile(*dataPath)
+		if err != nil {
+			fatal(err)
+		}
+		if err := json.Unmarshal(content, &options.Data); err != nil {
+			fatal(fmt.Errorf("reading %s: %w", *dataPath, err))
+		}
+	}
+	if *schemaPath != "" {
+		content, err := os.ReadFile(*schemaPath)
+		if err != nil {
+			fatal(err)
+		}
+		options.Schema = content
+	}
+
+	found := false
+	for _, template := range flag.Args() {
+		issues, err := docxer.Lint(template, options)
+		if err != nil {
+			fatal(fmt.Errorf("%s: %w", template, err))
+		}
+		for _, issue := range issues {
+			fmt.Printf("%s: %s\n", template, issue)
+			found = true
+		}
+	}
+	if found {
+		os.Exit(1)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "docxer-lint:", err)
+	os.Exit(2)
+}
@@ -2,6 +2,4 @@ module github.com/aliamerj/docxer
 
 go 1.22.1
 
-require (
-	golang.org/x/text v0.14.0 // indirect
-)
+require golang.org/x/text v0.14.0
@@ -0,0 +1,97 @@
+package barcode
+
+import "fmt"
+
+// code128Patterns are the bar and space widths of the Code 128 symbols, in
+// modules, starting with a bar. The last one is the stop symbol.
+var code128Patterns = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128CodeC  = 99
+	code128CodeB  = 100
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// Code128 encodes printable ASCII text as a Code 128 barcode, using code set
+// C for runs of digits. It returns the modules from left to right, true for a
+// bar, without a quiet zone.
+func Code128(data string) ([]bool, error) {
+	if data == "" {
+		return nil, fmt.Errorf("code128 needs at least one character")
+	}
+	for i := 0; i < len(data); i++ {
+		if data[i] < 32 || data[i] > 126 {
+			return nil, fmt.Errorf("code128 supports printable ASCII only, not %q", data[i])
+		}
+	}
+
+	var symbols []int
+	set := 0
+	for i := 0; i < len(data); {
+		run := 0
+		for i+run < len(data) && isDigit(data[i+run]) {
+			run++
+		}
+		useC := run >= 4 && (set == 0 || run >= 6 || i+run == len(data))
+		if set == code128CodeC && run >= 2 {
+			useC = true
+		}
+		if useC {
+			switch set {
+			case 0:
+				symbols = append(symbols, code128StartC)
+			case code128CodeB:
+				symbols = append(symbols, code128CodeC)
+			}
+			set = code128CodeC
+			for ; run >= 2; run -= 2 {
+				symbols = append(symbols, int(data[i]-'0')*10+int(data[i+1]-'0'))
+				i += 2
+			}
+			continue
+		}
+		switch set {
+		case 0:
+			symbols = append(symbols, code128StartB)
+		case code128CodeC:
+			symbols = append(symbols, code128CodeB)
+		}
+		set = code128CodeB
+		symbols = append(symbols, int(data[i]-32))
+		i++
+	}
+
+	checksum := symbols[0]
+	for i, symbol := range symbols[1:] {
+		checksum += (i + 1) * symbol
+	}
+	symbols = append(symbols, checksum%103, code128Stop)
+
+	var modules []bool
+	for _, symbol := range symbols {
+		for i, width := range code128Patterns[symbol] {
+			for j := 0; j < int(width-'0'); j++ {
+				modules = append(modules, i%2 == 0)
+			}
+		}
+	}
+	return modules, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,74 @@
+package barcode
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// code128Symbols reads the symbols back from the modules of a barcode.
+func code128Symbols(t *testing.T, modules []bool) []int {
+	var widths strings.Builder
+	for i := 0; i < len(modules); {
+		j := i
+		for j < len(modules) && modules[j] == modules[i] {
+			j++
+		}
+		widths.WriteByte(byte('0' + j - i))
+		i = j
+	}
+	var symbols []int
+	for rest := widths.String(); rest != ""; {
+		found := false
+		for symbol, pattern := range code128Patterns {
+			if strings.HasPrefix(rest, pattern) {
+				symbols = append(symbols, symbol)
+				rest = rest[len(pattern):]
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("Unknown Code 128 pattern at %s", rest)
+		}
+	}
+	return symbols
+}
+
+func TestCode128(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected []int
+	}{
+		{"HI345678", []int{104, 40, 41, 99, 34, 56, 78, 68, 106}},
+		{"12345", []int{105, 12, 34, 100, 21, 54, 106}},
+		{"Ab", []int{104, 33, 66, 63, 106}},
+	}
+	for _, test := range tests {
+		modules, err := Code128(test.data)
+		if err != nil {
+			t.Fatalf("Code128(%q) returned an error: %v", test.data, err)
+		}
+		if got := code128Symbols(t, modules); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("Code128(%q) = %v, want %v", test.data, got, test.expected)
+		}
+	}
+
+	for _, data := range []string{"", "tab\t", "café"} {
+		if _, err := Code128(data); err == nil {
+			t.Errorf("Expected an error for %q", data)
+		}
+	}
+}
+
+func TestCode128Patterns(t *testing.T) {
+	for symbol, pattern := range code128Patterns {
+		sum := 0
+		for _, width := range pattern {
+			sum += int(width - '0')
+		}
+		if expected := 11 + 2*(symbol/code128Stop); sum != expected {
+			t.Errorf("Symbol %d is %d modules wide, want %d", symbol, sum, expected)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// eanL are the left-hand odd parity digit patterns of EAN-13; the even parity
+// (G) patterns are the reversed right-hand (R) ones, which are their complements.
+var eanL = [10]string{
+	"0001101", "0011001", "0010011", "0111101", "0100011",
+	"0110001", "0101111", "0111011", "0110111", "0001011",
+}
+
+// eanParity gives, for the first digit, which of the next six use G patterns.
+var eanParity = [10]string{
+	"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG",
+	"LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL",
+}
+
+// EAN13Check returns the check digit of the first 12 digits of an EAN-13.
+func EAN13Check(digits string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(digits[i]-'0') * weight
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// EAN13 encodes 12 digits, or 13 with a valid check digit, as an EAN-13
+// barcode. It returns the 95 modules from left to right, true for a bar,
+// without a quiet zone.
+func EAN13(data string) ([]bool, error) {
+	if len(data) != 12 && len(data) != 13 {
+		return nil, fmt.Errorf("ean13 needs 12 or 13 digits, got %q", data)
+	}
+	for i := 0; i < len(data); i++ {
+		if !isDigit(data[i]) {
+			return nil, fmt.Errorf("ean13 needs 12 or 13 digits, got %q", data)
+		}
+	}
+	check := EAN13Check(data)
+	if len(data) == 13 && data[12] != check {
+		return nil, fmt.Errorf("invalid ean13 check digit in %q, want %c", data, check)
+	}
+	digits := data[:12] + string(check)
+
+	var pattern strings.Builder
+	pattern.WriteString("101")
+	parity := eanParity[digits[0]-'0']
+	for i := 1; i <= 6; i++ {
+		code := eanL[digits[i]-'0']
+		if parity[i-1] == 'G' {
+			code = reverse(complement(code))
+		}
+		pattern.WriteString(code)
+	}
+	pattern.WriteString("01010")
+	for i := 7; i <= 12; i++ {
+		pattern.WriteString(complement(eanL[digits[i]-'0']))
+	}
+	pattern.WriteString("101")
+
+	modules := make([]bool, pattern.Len())
+	for i, c := range pattern.String() {
+		modules[i] = c == '1'
+	}
+	return modules, nil
+}
+
+func complement(code string) string {
+	return strings.Map(func(r rune) rune { return '0' + '1' - r }, code)
+}
+
+func reverse(code string) string {
+	result := []byte(code)
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return string(result)
+}
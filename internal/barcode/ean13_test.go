@@ -0,0 +1,46 @@
+package barcode
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEAN13(t *testing.T) {
+	if check := EAN13Check("400638133393"); check != '1' {
+		t.Errorf("EAN13Check() = %c, want 1", check)
+	}
+
+	modules, err := EAN13("400638133393")
+	if err != nil {
+		t.Fatalf("EAN13 returned an error: %v", err)
+	}
+	withCheck, err := EAN13("4006381333931")
+	if err != nil {
+		t.Fatalf("EAN13 returned an error: %v", err)
+	}
+	got := ""
+	for _, bar := range withCheck {
+		if bar {
+			got += "1"
+		} else {
+			got += "0"
+		}
+	}
+	expected := "101" +
+		"0001101" + "0100111" + "0101111" + "0111101" + "0001001" + "0110011" +
+		"01010" +
+		"1000010" + "1000010" + "1000010" + "1110100" + "1000010" + "1100110" +
+		"101"
+	if got != expected {
+		t.Errorf("EAN13() = %s, want %s", got, expected)
+	}
+	if !reflect.DeepEqual(modules, withCheck) {
+		t.Errorf("Expected the check digit to be computed when missing")
+	}
+
+	for _, data := range []string{"4006381333932", "12345", "40063813339a"} {
+		if _, err := EAN13(data); err == nil {
+			t.Errorf("Expected an error for %q", data)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package barcode
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// Quiet zones, in modules, around each kind of code.
+const (
+	QRQuietZone     = 4
+	LinearQuietZone = 11
+)
+
+var palette = color.Palette{color.White, color.Black}
+
+// MatrixPNG draws a QR code matrix with its quiet zone, scale pixels per module.
+func MatrixPNG(matrix [][]bool, scale int) ([]byte, error) {
+	size := (len(matrix) + 2*QRQuietZone) * scale
+	img := image.NewPaletted(image.Rect(0, 0, size, size), palette)
+	for y, row := range matrix {
+		for x, dark := range row {
+			if dark {
+				fill(img, (x+QRQuietZone)*scale, (y+QRQuietZone)*scale, scale, scale)
+			}
+		}
+	}
+	return encode(img)
+}
+
+// LinearPNG draws the bars of a one-dimensional barcode with its quiet zone,
+// scale pixels per module and height pixels high.
+func LinearPNG(modules []bool, scale, height int) ([]byte, error) {
+	width := (len(modules) + 2*LinearQuietZone) * scale
+	img := image.NewPaletted(image.Rect(0, 0, width, height), palette)
+	for x, bar := range modules {
+		if bar {
+			fill(img, (x+LinearQuietZone)*scale, 0, scale, height)
+		}
+	}
+	return encode(img)
+}
+
+func fill(img *image.Paletted, x, y, width, height int) {
+	for dy := 0; dy < height; dy++ {
+		for dx := 0; dx < width; dx++ {
+			img.SetColorIndex(x+dx, y+dy, 1)
+		}
+	}
+}
+
+func encode(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
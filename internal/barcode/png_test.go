@@ -0,0 +1,44 @@
+package barcode
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func TestMatrixPNG(t *testing.T) {
+	data, err := MatrixPNG([][]bool{{true, false}, {false, true}}, 3)
+	if err != nil {
+		t.Fatalf("MatrixPNG returned an error: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Expected a PNG: %v", err)
+	}
+	if size := img.Bounds().Size(); size.X != 30 || size.Y != 30 {
+		t.Errorf("Expected a 30x30 image, got %v", size)
+	}
+	if r, _, _, _ := img.At(12, 12).RGBA(); r != 0 {
+		t.Errorf("Expected the first module to be dark")
+	}
+	if r, _, _, _ := img.At(15, 12).RGBA(); r == 0 {
+		t.Errorf("Expected the second module to be light")
+	}
+}
+
+func TestLinearPNG(t *testing.T) {
+	data, err := LinearPNG([]bool{true, false, true}, 2, 40)
+	if err != nil {
+		t.Fatalf("LinearPNG returned an error: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Expected a PNG: %v", err)
+	}
+	if size := img.Bounds().Size(); size.X != 50 || size.Y != 40 {
+		t.Errorf("Expected a 50x40 image, got %v", size)
+	}
+	if r, _, _, _ := img.At(22, 39).RGBA(); r != 0 {
+		t.Errorf("Expected a bar at the first module")
+	}
+}
@@ -0,0 +1,88 @@
+package barcode
+
+import "testing"
+
+func TestQR(t *testing.T) {
+	// The code rsc.io/qr draws for the same text, level and mask.
+	expected := []string{
+		"#######....##..#..#######",
+		"#.....#.##..#.###.#.....#",
+		"#.###.#..#.##.#...#.###.#",
+		"#.###.#..###.#.#..#.###.#",
+		"#.###.#.#....###..#.###.#",
+		"#.....#..#.##.##..#.....#",
+		"#######.#.#.#.#.#.#######",
+		".........#.#...##........",
+		"#.#.#.#..#......#...#..#.",
+		"..###....##..#..###.....#",
+		"##..#.###.##..#.##..#.###",
+		"..#....##.#..##.#.#....#.",
+		"##.#..##.#.#..#####..#.##",
+		"...#.#.###.#..#..##..#..#",
+		"#..##.#####..#..#.##..###",
+		".##....#...#......#.#..#.",
+		"#...####....#...######...",
+		"........#..###..#...##.##",
+		"#######...###.###.#.##.##",
+		"#.....#..##.###.#...##.##",
+		"#.###.#.##.#..#.######..#",
+		"#.###.#..###..#....####..",
+		"#.###.#.###..#.###..#...#",
+		"#.....#...##...####.##.#.",
+		"#######.#...#..##.##...##",
+	}
+	matrix := encodeQR([]byte("https://docxer.dev"), 2, LevelM, 0)
+	if len(matrix) != len(expected) {
+		t.Fatalf("Expected a %d module code, got %d", len(expected), len(matrix))
+	}
+	for y, row := range matrix {
+		got := ""
+		for _, dark := range row {
+			if dark {
+				got += "#"
+			} else {
+				got += "."
+			}
+		}
+		if got != expected[y] {
+			t.Errorf("Row %d = %s, want %s", y, got, expected[y])
+		}
+	}
+}
+
+func TestQR_Version(t *testing.T) {
+	tests := []struct {
+		length int
+		level  Level
+		size   int
+	}{
+		{17, LevelL, 21},
+		{18, LevelL, 25},
+		{14, LevelM, 21},
+		{2953, LevelL, 177},
+	}
+	for _, test := range tests {
+		matrix, err := QR(string(make([]byte, test.length)), test.level)
+		if err != nil {
+			t.Fatalf("QR returned an error: %v", err)
+		}
+		if len(matrix) != test.size {
+			t.Errorf("QR(%d bytes) is %d modules, want %d", test.length, len(matrix), test.size)
+		}
+	}
+	if _, err := QR(string(make([]byte, 2954)), LevelL); err == nil {
+		t.Errorf("Expected an error for data too long for a QR code")
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	if level, err := ParseLevel("h"); err != nil || level != LevelH {
+		t.Errorf("ParseLevel(h) = %v, %v", level, err)
+	}
+	if level, err := ParseLevel(""); err != nil || level != LevelM {
+		t.Errorf("ParseLevel() = %v, %v", level, err)
+	}
+	if _, err := ParseLevel("X"); err == nil {
+		t.Errorf("Expected an error for an unknown level")
+	}
+}
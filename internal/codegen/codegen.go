@@ -0,0 +1,264 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/aliamerj/docxer/internal/lint"
+)
+
+// Options names the generated code. Template is the template file name shown
+// in comments.
+type Options struct {
+	Package  string
+	Type     string
+	Template string
+}
+
+type field struct {
+	Name string
+	Type string
+	Key  string
+	Kind string
+	// Item is the element struct of a loop field.
+	Item *model
+}
+
+type model struct {
+	Name   string
+	Loop   string
+	Fields []*field
+	byKey  map[string]*field
+	names  map[string]bool
+}
+
+func newModel(name string) *model {
+	return &model{Name: name, byKey: map[string]*field{}, names: map[string]bool{}}
+}
+
+// add returns the field for a key, creating it on first use. A key used both
+// as a placeholder and as a condition stays a string.
+func (m *model) add(kind, key, goType string) *field {
+	id := kind + ":" + key
+	if kind == "if" || kind == "" {
+		id = ":" + key
+	}
+	if f, ok := m.byKey[id]; ok {
+		if kind == "" {
+			f.Type, f.Kind = "string", ""
+		}
+		return f
+	}
+	f := &field{Name: m.fieldName(key), Type: goType, Key: key, Kind: kind}
+	m.byKey[id] = f
+	m.Fields = append(m.Fields, f)
+	return f
+}
+
+// fieldName turns a key such as "CUSTOMER_NAME" or "firstName" into a unique
+// exported Go identifier.
+func (m *model) fieldName(key string) string {
+	var name strings.Builder
+	upper := true
+	for _, r := range key {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if upper {
+				name.WriteRune(unicode.ToUpper(r))
+			} else if isUpperKey(key) {
+				name.WriteRune(unicode.ToLower(r))
+			} else {
+				name.WriteRune(r)
+			}
+			upper = false
+		default:
+			upper = true
+		}
+	}
+	base := name.String()
+	if base == "" || !unicode.IsLetter([]rune(base)[0]) {
+		base = "Field" + base
+	}
+	unique := base
+	for i := 2; m.names[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", base, i)
+	}
+	m.names[unique] = true
+	return unique
+}
+
+func isUpperKey(key string) bool {
+	return strings.ToUpper(key) == key
+}
+
+// Generate returns the Go source of a struct describing the data of a template
+// with the given references, and of a function rendering the template from it.
+func Generate(references []lint.Reference, options Options) ([]byte, error) {
+	if !token.IsIdentifier(options.Package) {
+		return nil, fmt.Errorf("invalid package name %q", options.Package)
+	}
+	if !token.IsIdentifier(options.Type) || !token.IsExported(options.Type) {
+		return nil, fmt.Errorf("invalid type name %q, expected an exported identifier", options.Type)
+	}
+
+	root := newModel(options.Type)
+	topLevel := map[string]bool{}
+	for _, ref := range references {
+		if ref.Loop == "" && (ref.Kind == "" || ref.Kind == "if") {
+			topLevel[ref.Key] = true
+		}
+	}
+	loops := map[string]*field{}
+	var models []*model
+	for _, ref := range references {
+		// Links, tables and barcodes are filled once for the whole document,
+		// so they belong to the root even inside loops.
+		target := root
+		if ref.Loop != "" && !topLevel[ref.Key] && (ref.Kind == "" || ref.Kind == "if" || ref.Kind == "each") {
+			loop, ok := loops[ref.Loop]
+			if !ok {
+				loop = addLoop(root, ref.Loop, loops)
+				models = append(models, loop.Item)
+			}
+			target = loop.Item
+		}
+		switch ref.Kind {
+		case "each":
+			if _, ok := loops[ref.Key]; !ok {
+				models = append(models, addLoop(root, ref.Key, loops).Item)
+			}
+		case "if":
+			target.add("if", ref.Key, "bool")
+		case "link":
+			target.add("link", ref.Key, "docxer.Link")
+		case "table":
+			target.add("table", ref.Key, "docxer.Table")
+		case "qr", "barcode":
+			// One value serves every kind of code drawn for a key.
+			target.add("barcode", ref.Key, "docxer.Barcode")
+		default:
+			target.add("", ref.Key, "string")
+		}
+	}
+
+	var source bytes.Buffer
+	err := sourceTemplate.Execute(&source, map[string]any{
+		"Options": options,
+		"Root":    root,
+		"Models":  append([]*model{root}, models...),
+		"Uses":    uses(root),
+	})
+	if err != nil {
+		return nil, err
+	}
+	formatted, err := format.Source(source.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return formatted, nil
+}
+
+func addLoop(root *model, key string, loops map[string]*field) *field {
+	loop := root.add("each", key, "")
+	loop.Item = newModel(root.Name + loop.Name + "Item")
+	loop.Item.Loop = key
+	loop.Type = "[]" + loop.Item.Name
+	loops[key] = loop
+	return loop
+}
+
+// uses reports which holder methods the generated Render needs.
+func uses(root *model) map[string]bool {
+	kinds := map[string]bool{}
+	for _, f := range root.Fields {
+		kinds[f.Kind] = true
+	}
+	return kinds
+}
+
+var sourceTemplate = template.Must(template.New("source").Parse(`// Code generated by docxer-gen{{with .Options.Template}} from {{.}}{{end}}. DO NOT EDIT.
+
+package {{.Options.Package}}
+
+import (
+	"context"
+
+	"github.com/aliamerj/docxer/pkg/docxer"
+)
+{{range .Models}}
+{{if eq .Name $.Root.Name}}// {{.Name}} is the data of the{{with $.Options.Template}} {{.}}{{end}} template.{{else}}// {{.Name}} is an item of the {{.Loop}} loop of {{$.Root.Name}}.{{end}}
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} ` + "`" + `docx:"{{if and .Kind (ne .Kind "each") (ne .Kind "if")}}{{.Kind}}:{{end}}{{.Key}}"` + "`" + `
+{{- end}}
+}
+{{end}}
+// Render{{.Root.Name}} fills the template at filePath with data. Like the
+// other docxer placeholder methods it updates the file in place.
+func Render{{.Root.Name}}(ctx context.Context, filePath string, data {{.Root.Name}}) error {
+	holder := docxer.Placeholder(filePath)
+{{- if .Uses.link}}
+	links := map[string]docxer.Link{
+{{- range .Root.Fields}}{{if eq .Kind "link"}}
+		{{printf "%q" .Key}}: data.{{.Name}},
+{{- end}}{{end}}
+	}
+	if err := holder.Links(links); err != nil {
+		return err
+	}
+{{- end}}
+{{- if .Uses.table}}
+	tables := map[string]docxer.Table{
+{{- range .Root.Fields}}{{if eq .Kind "table"}}
+		{{printf "%q" .Key}}: data.{{.Name}},
+{{- end}}{{end}}
+	}
+	if err := holder.Tables(tables); err != nil {
+		return err
+	}
+{{- end}}
+{{- if .Uses.barcode}}
+	barcodes := map[string]docxer.Barcode{
+{{- range .Root.Fields}}{{if eq .Kind "barcode"}}
+		{{printf "%q" .Key}}: data.{{.Name}},
+{{- end}}{{end}}
+	}
+	if err := holder.Barcodes(barcodes); err != nil {
+		return err
+	}
+{{- end}}
+
+	values := map[string]any{}
+{{- range .Root.Fields}}
+{{- if eq .Kind "each"}}
+	values[{{printf "%q" .Key}}] = itemValues{{$.Root.Name}}(data.{{.Name}})
+{{- else if or (eq .Kind "") (eq .Kind "if")}}
+	values[{{printf "%q" .Key}}] = data.{{.Name}}
+{{- end}}
+{{- end}}
+	return holder.Resolve(ctx, docxer.MapResolver(values))
+}
+{{range slice .Models 1}}
+func (item {{.Name}}) values() map[string]any {
+	return map[string]any{
+{{- range .Fields}}
+		{{printf "%q" .Key}}: item.{{.Name}},
+{{- end}}
+	}
+}
+{{end}}
+{{- if gt (len .Models) 1}}
+func itemValues{{.Root.Name}}[T interface{ values() map[string]any }](items []T) []map[string]any {
+	values := make([]map[string]any, len(items))
+	for i, item := range items {
+		values[i] = item.values()
+	}
+	return values
+}
+{{- end}}
+`))
@@ -0,0 +1,89 @@
+package codegen
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/aliamerj/docxer/internal/lint"
+)
+
+func TestGenerate(t *testing.T) {
+	references := []lint.Reference{
+		{Key: "CUSTOMER_NAME"},
+		{Kind: "if", Key: "paid"},
+		{Kind: "link", Key: "portal"},
+		{Kind: "each", Key: "line_items"},
+		{Key: "price", Loop: "line_items"},
+		{Kind: "if", Key: "gift", Loop: "line_items"},
+		{Key: "CUSTOMER_NAME", Loop: "line_items"},
+		{Kind: "table", Key: "totals", Loop: "line_items"},
+		{Kind: "if", Key: "note"},
+		{Key: "note"},
+		{Kind: "qr", Key: "SKU"},
+		{Kind: "barcode", Key: "SKU", Loop: "line_items"},
+	}
+
+	source, err := Generate(references, Options{Package: "invoice", Type: "Invoice", Template: "invoice.docx"})
+	if err != nil {
+		t.Fatalf("Generate returned an error: %v", err)
+	}
+	code := string(source)
+	normalized := strings.Join(strings.Fields(code), " ")
+	if _, err := parser.ParseFile(token.NewFileSet(), "invoice_gen.go", source, parser.AllErrors); err != nil {
+		t.Fatalf("Generated code does not parse: %v\n%s", err, code)
+	}
+
+	expected := []string{
+		"// Code generated by docxer-gen from invoice.docx. DO NOT EDIT.",
+		"package invoice",
+		"CustomerName string                  `docx:\"CUSTOMER_NAME\"`",
+		"Paid         bool                    `docx:\"paid\"`",
+		"Portal       docxer.Link             `docx:\"link:portal\"`",
+		"LineItems    []InvoiceLineItemsItem  `docx:\"line_items\"`",
+		"Totals       docxer.Table            `docx:\"table:totals\"`",
+		"Note         string                  `docx:\"note\"`",
+		"Sku          docxer.Barcode          `docx:\"barcode:SKU\"`",
+		"// InvoiceLineItemsItem is an item of the line_items loop of Invoice.",
+		"Price string `docx:\"price\"`",
+		"Gift  bool   `docx:\"gift\"`",
+		"func RenderInvoice(ctx context.Context, filePath string, data Invoice) error {",
+		"if err := holder.Links(links); err != nil {",
+		"if err := holder.Tables(tables); err != nil {",
+		"if err := holder.Barcodes(barcodes); err != nil {",
+		`values["line_items"] = itemValuesInvoice(data.LineItems)`,
+		"return holder.Resolve(ctx, docxer.MapResolver(values))",
+	}
+	for _, text := range expected {
+		if !strings.Contains(normalized, strings.Join(strings.Fields(text), " ")) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", text, code)
+		}
+	}
+	if strings.Count(code, "Sku") != 2 {
+		t.Errorf("Expected one barcode field for SKU, got:\n%s", code)
+	}
+	if strings.Count(code, "CustomerName") != 2 {
+		t.Errorf("Expected CUSTOMER_NAME to stay a single top-level field, got:\n%s", code)
+	}
+}
+
+func TestGenerate_InvalidNames(t *testing.T) {
+	if _, err := Generate(nil, Options{Package: "my-pkg", Type: "Data"}); err == nil {
+		t.Errorf("Expected an error for an invalid package name")
+	}
+	if _, err := Generate(nil, Options{Package: "pkg", Type: "data"}); err == nil {
+		t.Errorf("Expected an error for an unexported type name")
+	}
+
+	source, err := Generate([]lint.Reference{{Key: "1st"}, {Key: "first"}, {Key: "First"}}, Options{Package: "pkg", Type: "Data"})
+	if err != nil {
+		t.Fatalf("Generate returned an error: %v", err)
+	}
+	normalized := strings.Join(strings.Fields(string(source)), " ")
+	for _, name := range []string{"Field1st string", "First string", "First2 string"} {
+		if !strings.Contains(normalized, name) {
+			t.Errorf("Expected field %q, got:\n%s", name, source)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package contentcontrol
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aliamerj/docxer/internal/placeholder"
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+var (
+	showingPlcHdrPattern    = regexp.MustCompile(`<w:showingPlcHdr(?:\s[^>]*)?/>`)
+	placeholderStylePattern = regexp.MustCompile(`<w:rStyle w:val="PlaceholderText"\s*/>`)
+	sdtIDPattern            = regexp.MustCompile(`<w:id w:val="[^"]*"\s*/>`)
+)
+
+// ContentControlWriter fills the Word content controls (w:sdt) whose tag or
+// alias matches a key of values. Controls without a matching key are left
+// untouched, so they keep showing their placeholder text.
+func ContentControlWriter(values map[string]any) placeholder.PlaceholderAction {
+	return func() utils.DocxWriter {
+		return func(fileContent string) (string, error) {
+			return fill(fileContent, values), nil
+		}
+	}
+}
+
+func fill(content string, values map[string]any) string {
+	controls := utils.FindElements(content, "w:sdt")
+	if len(controls) == 0 {
+		return content
+	}
+	var result strings.Builder
+	last := 0
+	for _, control := range controls {
+		result.WriteString(content[last:control.Start])
+		result.WriteString(fillControl(control.Outer(content), values))
+		last = control.End
+	}
+	result.WriteString(content[last:])
+	return result.String()
+}
+
+func fillControl(sdt string, values map[string]any) string {
+	props, ok := utils.FindElement(sdt, "w:sdtPr", 0)
+	if !ok {
+		return sdt
+	}
+	body, ok := utils.FindElement(sdt, "w:sdtContent", props.End)
+	if !ok {
+		return sdt
+	}
+	properties := props.Outer(sdt)
+	content := body.Inner(sdt)
+
+	value, found := lookup(properties, values)
+	if !found || value == nil {
+		return sdt[:body.InnerStart] + fill(content, values) + sdt[body.InnerEnd:]
+	}
+	return setValue(sdt, props, body, value)
+}
+
+// setValue sets the value of a single control according to its type and
+// clears its showing-placeholder state.
+func setValue(sdt string, props, body utils.XMLElement, value any) string {
+	properties := props.Outer(sdt)
+	content := body.Inner(sdt)
+
+	switch {
+	case strings.Contains(properties, "<w15:repeatingSection"):
+		content = repeatSection(content, value)
+	case strings.Contains(properties, "<w14:checkbox"):
+		properties, content = setCheckbox(properties, content, value)
+	case strings.Contains(properties, "<w:dropDownList") || strings.Contains(properties, "<w:comboBox"):
+		properties, content = setListItem(properties, content, value)
+	case strings.Contains(properties, "<w:date"):
+		properties, content = setDate(properties, content, value)
+	default:
+		content = setText(properties, content, utils.FormatValue(value))
+	}
+	properties = showingPlcHdrPattern.ReplaceAllString(properties, "")
+
+	return sdt[:props.Start] + properties + sdt[props.End:body.InnerStart] + content + sdt[body.InnerEnd:]
+}
+
+// lookup finds the value for a control, preferring its tag over its alias.
+func lookup(properties string, values map[string]any) (any, bool) {
+	for _, name := range []string{"w:tag", "w:alias"} {
+		element, ok := utils.FindElement(properties, name, 0)
+		if !ok {
+			continue
+		}
+		key, ok := utils.Attr(element.StartTag(properties), "w:val")
+		if !ok {
+			continue
+		}
+		if value, ok := values[key]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// setText replaces the content of a control with text, keeping the paragraph
+// and run formatting of the first paragraph and run it contains.
+func setText(properties, content, text string) string {
+	runProps := runProperties(properties, content)
+
+	paragraphs := utils.FindElements(content, "w:p")
+	if len(paragraphs) == 0 {
+		return utils.TextRun(runProps, text)
+	}
+
+	first := paragraphs[0]
+	if strings.Contains(content, "<w:tc") || strings.Contains(content, "<w:tr") {
+		// Row and cell level controls must keep their table structure, so
+		// only the text of the first paragraph is replaced.
+		return content[:first.Start] + paragraph(first.Outer(content), runProps, text) + content[first.End:]
+	}
+
+	var result strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		result.WriteString(paragraph(first.Outer(content), runProps, line))
+	}
+	return content[:first.Start] + result.String() + content[paragraphs[len(paragraphs)-1].End:]
+}
+
+// paragraph rebuilds p with its paragraph properties and a single run of text.
+func paragraph(p, runProps, text string) string {
+	start := strings.IndexByte(p, '>') + 1
+	paragraphProps := ""
+	if pPr, ok := utils.FindElement(p, "w:pPr", 0); ok {
+		paragraphProps = pPr.Outer(p)
+	}
+	return p[:start] + paragraphProps + utils.TextRun(runProps, text) + "</w:p>"
+}
+
+// runProperties returns the formatting of the first run in the control, or the
+// formatting stored on the control itself, without the placeholder style.
+func runProperties(properties, content string) string {
+	rPr := ""
+	if run, ok := utils.FindElement(content, "w:r", 0); ok {
+		if element, ok := utils.FindElement(run.Outer(content), "w:rPr", 0); ok {
+			rPr = element.Outer(run.Outer(content))
+		}
+	}
+	if rPr == "" {
+		if element, ok := utils.FindElement(properties, "w:rPr", 0); ok {
+			rPr = element.Outer(properties)
+		}
+	}
+	rPr = placeholderStylePattern.ReplaceAllString(rPr, "")
+	if rPr == "<w:rPr></w:rPr>" {
+		return ""
+	}
+	return rPr
+}
+
+func setCheckbox(properties, content string, value any) (string, string) {
+	checked := isChecked(value)
+	state := "0"
+	glyphState, defaultGlyph := "w14:uncheckedState", "2610"
+	if checked {
+		state = "1"
+		glyphState, defaultGlyph = "w14:checkedState", "2612"
+	}
+
+	if element, ok := utils.FindElement(properties, "w14:checked", 0); ok {
+		tag := utils.SetAttr(element.StartTag(properties), "w14:val", state)
+		properties = properties[:element.Start] + tag + properties[element.Start+len(element.StartTag(properties)):]
+	} else if element, ok := utils.FindElement(properties, "w14:checkbox", 0); ok {
+		properties = properties[:element.InnerStart] + `<w14:checked w14:val="` + state + `"/>` + properties[element.InnerStart:]
+	}
+
+	glyph := defaultGlyph
+	if element, ok := utils.FindElement(properties, glyphState, 0); ok {
+		if code, ok := utils.Attr(element.StartTag(properties), "w14:val"); ok {
+			glyph = code
+		}
+	}
+	char, err := strconv.ParseUint(glyph, 16, 32)
+	if err != nil {
+		char, _ = strconv.ParseUint(defaultGlyph, 16, 32)
+	}
+	return properties, setText(properties, content, string(rune(char)))
+}
+
+func isChecked(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "1", "true", "yes", "x", "on", "checked":
+			return true
+		}
+		return false
+	default:
+		return utils.FormatValue(v) != "0" && utils.FormatValue(v) != ""
+	}
+}
+
+// setListItem selects the dropdown or combo box entry whose value or display
+// text equals value. Combo boxes accept free text that matches no entry.
+func setListItem(properties, content string, value any) (string, string) {
+	selected := utils.FormatValue(value)
+	display := selected
+	for _, item := range utils.FindElements(properties, "w:listItem") {
+		tag := item.StartTag(properties)
+		itemValue, _ := utils.Attr(tag, "w:value")
+		itemText, hasText := utils.Attr(tag, "w:displayText")
+		if !hasText {
+			itemText = itemValue
+		}
+		if itemValue == selected || itemText == selected {
+			selected, display = itemValue, itemText
+			break
+		}
+	}
+	for _, name := range []string{"w:dropDownList", "w:comboBox"} {
+		if element, ok := utils.FindElement(properties, name, 0); ok {
+			start := element.StartTag(properties)
+			properties = properties[:element.Start] + utils.SetAttr(start, "w:lastValue", selected) + properties[element.Start+len(start):]
+		}
+	}
+	return properties, setText(properties, content, display)
+}
+
+func setDate(properties, content string, value any) (string, string) {
+	element, _ := utils.FindElement(properties, "w:date", 0)
+	date, ok := utils.ToTime(value)
+	if !ok {
+		return properties, setText(properties, content, utils.FormatValue(value))
+	}
+
+	format := "M/d/yyyy"
+	if dateFormat, ok := utils.FindElement(properties, "w:dateFormat", element.Start); ok {
+		if val, ok := utils.Attr(dateFormat.StartTag(properties), "w:val"); ok && val != "" {
+			format = val
+		}
+	}
+	start := element.StartTag(properties)
+	fullDate := date.Format("2006-01-02") + "T00:00:00Z"
+	properties = properties[:element.Start] + utils.SetAttr(start, "w:fullDate", fullDate) + properties[element.Start+len(start):]
+	return properties, setText(properties, content, FormatWordDate(date, format))
+}
+
+// repeatSection renders the first repeating section item once per entry of
+// value, filling the controls inside each copy from that entry.
+func repeatSection(content string, value any) string {
+	items := sectionItems(value)
+
+	template, before, after := content, "", ""
+	var found []utils.XMLElement
+	for _, element := range utils.FindElements(content, "w:sdt") {
+		if strings.Contains(element.Outer(content), "<w15:repeatingSectionItem") {
+			found = append(found, element)
+		}
+	}
+	if len(found) > 0 {
+		first, last := found[0], found[len(found)-1]
+		template = first.Outer(content)
+		before, after = content[:first.Start], content[last.End:]
+	}
+
+	var result strings.Builder
+	result.WriteString(before)
+	for i, item := range items {
+		section := fill(template, item)
+		if i > 0 {
+			// Copies must not share the identifiers of the original controls.
+			section = sdtIDPattern.ReplaceAllString(section, "")
+		}
+		result.WriteString(section)
+	}
+	result.WriteString(after)
+	return result.String()
+}
+
+func sectionItems(value any) []map[string]any {
+	switch v := value.(type) {
+	case []map[string]any:
+		return v
+	case []map[string]string:
+		items := make([]map[string]any, len(v))
+		for i, item := range v {
+			items[i] = make(map[string]any, len(item))
+			for key, val := range item {
+				items[i][key] = val
+			}
+		}
+		return items
+	case []any:
+		var items []map[string]any
+		for _, item := range v {
+			if m, ok := item.(map[string]any); ok {
+				items = append(items, m)
+			}
+		}
+		return items
+	}
+	return nil
+}
+
+// FormatWordDate formats t using a Word date picture such as "dddd, MMMM d, yyyy".
+// Text in single quotes is copied literally.
+func FormatWordDate(t time.Time, format string) string {
+	tokens := []struct {
+		token  string
+		format func(time.Time) string
+	}{
+		{"yyyy", func(t time.Time) string { return fmt.Sprintf("%04d", t.Year()) }},
+		{"yy", func(t time.Time) string { return fmt.Sprintf("%02d", t.Year()%100) }},
+		{"MMMM", func(t time.Time) string { return t.Month().String() }},
+		{"MMM", func(t time.Time) string { return t.Month().String()[:3] }},
+		{"MM", func(t time.Time) string { return fmt.Sprintf("%02d", int(t.Month())) }},
+		{"M", func(t time.Time) string { return strconv.Itoa(int(t.Month())) }},
+		{"dddd", func(t time.Time) string { return t.Weekday().String() }},
+		{"ddd", func(t time.Time) string { return t.Weekday().String()[:3] }},
+		{"dd", func(t time.Time) string { return fmt.Sprintf("%02d", t.Day()) }},
+		{"d", func(t time.Time) string { return strconv.Itoa(t.Day()) }},
+		{"HH", func(t time.Time) string { return fmt.Sprintf("%02d", t.Hour()) }},
+		{"H", func(t time.Time) string { return strconv.Itoa(t.Hour()) }},
+		{"hh", func(t time.Time) string { return fmt.Sprintf("%02d", hour12(t)) }},
+		{"h", func(t time.Time) string { return strconv.Itoa(hour12(t)) }},
+		{"mm", func(t time.Time) string { return fmt.Sprintf("%02d", t.Minute()) }},
+		{"m", func(t time.Time) string { return strconv.Itoa(t.Minute()) }},
+		{"ss", func(t time.Time) string { return fmt.Sprintf("%02d", t.Second()) }},
+		{"s", func(t time.Time) string { return strconv.Itoa(t.Second()) }},
+		{"AM/PM", func(t time.Time) string { return t.Format("PM") }},
+		{"am/pm", func(t time.Time) string { return strings.ToLower(t.Format("PM")) }},
+	}
+
+	var result strings.Builder
+	for i := 0; i < len(format); {
+		if format[i] == '\'' {
+			end := strings.IndexByte(format[i+1:], '\'')
+			if end == -1 {
+				result.WriteString(format[i+1:])
+				break
+			}
+			result.WriteString(format[i+1 : i+1+end])
+			i += end + 2
+			continue
+		}
+		matched := false
+		for _, token := range tokens {
+			if strings.HasPrefix(format[i:], token.token) {
+				result.WriteString(token.format(t))
+				i += len(token.token)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			result.WriteByte(format[i])
+			i++
+		}
+	}
+	return result.String()
+}
+
+func hour12(t time.Time) int {
+	if h := t.Hour() % 12; h != 0 {
+		return h
+	}
+	return 12
+}
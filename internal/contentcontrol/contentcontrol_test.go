@@ -0,0 +1,146 @@
+package contentcontrol
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContentControlWriter_PlainText(t *testing.T) {
+	inputContent := `<w:p><w:sdt><w:sdtPr><w:rPr><w:b/></w:rPr><w:alias w:val="Customer Name"/><w:tag w:val="customer"/><w:showingPlcHdr/><w:text/></w:sdtPr><w:sdtContent><w:r><w:rPr><w:rStyle w:val="PlaceholderText"/></w:rPr><w:t>Click here</w:t></w:r></w:sdtContent></w:sdt></w:p>`
+	expectedOutput := `<w:p><w:sdt><w:sdtPr><w:rPr><w:b/></w:rPr><w:alias w:val="Customer Name"/><w:tag w:val="customer"/><w:text/></w:sdtPr><w:sdtContent><w:r><w:t xml:space="preserve">ACME &amp; Co</w:t></w:r></w:sdtContent></w:sdt></w:p>`
+
+	docxWriter := ContentControlWriter(map[string]any{"customer": "ACME & Co"})()
+	outputContent, err := docxWriter(inputContent)
+	if err != nil {
+		t.Fatalf("DocxWriter returned an error: %v", err)
+	}
+
+	if outputContent != expectedOutput {
+		t.Errorf("Expected '%s', got '%s'", expectedOutput, outputContent)
+	}
+}
+
+func TestContentControlWriter_MatchesAlias(t *testing.T) {
+	inputContent := `<w:sdt><w:sdtPr><w:alias w:val="City"/></w:sdtPr><w:sdtContent><w:r><w:rPr><w:i/></w:rPr><w:t>City</w:t></w:r></w:sdtContent></w:sdt>`
+	expectedOutput := `<w:sdt><w:sdtPr><w:alias w:val="City"/></w:sdtPr><w:sdtContent><w:r><w:rPr><w:i/></w:rPr><w:t xml:space="preserve">Berlin</w:t></w:r></w:sdtContent></w:sdt>`
+
+	outputContent := applyControls(t, map[string]any{"City": "Berlin"}, inputContent)
+
+	if outputContent != expectedOutput {
+		t.Errorf("Expected '%s', got '%s'", expectedOutput, outputContent)
+	}
+}
+
+func TestContentControlWriter_KeepsUnmatchedPlaceholder(t *testing.T) {
+	inputContent := `<w:sdt><w:sdtPr><w:tag w:val="other"/><w:showingPlcHdr/></w:sdtPr><w:sdtContent><w:r><w:t>Click here</w:t></w:r></w:sdtContent></w:sdt>`
+
+	outputContent := applyControls(t, map[string]any{"customer": "ACME"}, inputContent)
+
+	if outputContent != inputContent {
+		t.Errorf("Expected unmatched control to be unchanged, got '%s'", outputContent)
+	}
+}
+
+func TestContentControlWriter_RichTextParagraphs(t *testing.T) {
+	inputContent := `<w:sdt><w:sdtPr><w:tag w:val="notes"/></w:sdtPr><w:sdtContent><w:p><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:t>Old</w:t></w:r></w:p><w:p><w:r><w:t>Old 2</w:t></w:r></w:p></w:sdtContent></w:sdt>`
+	expectedContent := `<w:sdtContent><w:p><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:t xml:space="preserve">First</w:t></w:r></w:p><w:p><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:t xml:space="preserve">Second</w:t></w:r></w:p></w:sdtContent>`
+
+	outputContent := applyControls(t, map[string]any{"notes": "First\nSecond"}, inputContent)
+
+	if !strings.Contains(outputContent, expectedContent) {
+		t.Errorf("Expected content '%s', got '%s'", expectedContent, outputContent)
+	}
+}
+
+func TestContentControlWriter_DropDown(t *testing.T) {
+	inputContent := `<w:sdt><w:sdtPr><w:tag w:val="country"/><w:showingPlcHdr/><w:dropDownList><w:listItem w:displayText="Choose an item." w:value=""/><w:listItem w:displayText="Germany" w:value="DE"/><w:listItem w:displayText="Saudi Arabia" w:value="SA"/></w:dropDownList></w:sdtPr><w:sdtContent><w:r><w:t>Choose an item.</w:t></w:r></w:sdtContent></w:sdt>`
+
+	outputContent := applyControls(t, map[string]any{"country": "SA"}, inputContent)
+
+	if !strings.Contains(outputContent, `<w:dropDownList w:lastValue="SA">`) {
+		t.Errorf("Expected selected value to be recorded, got '%s'", outputContent)
+	}
+	if !strings.Contains(outputContent, `<w:t xml:space="preserve">Saudi Arabia</w:t>`) {
+		t.Errorf("Expected display text of the selected item, got '%s'", outputContent)
+	}
+	if strings.Contains(outputContent, "showingPlcHdr") {
+		t.Errorf("Expected placeholder state to be removed, got '%s'", outputContent)
+	}
+}
+
+func TestContentControlWriter_Date(t *testing.T) {
+	inputContent := `<w:sdt><w:sdtPr><w:tag w:val="signed"/><w:date><w:dateFormat w:val="dddd, MMMM d, yyyy"/><w:lid w:val="en-US"/></w:date></w:sdtPr><w:sdtContent><w:r><w:t>Pick a date</w:t></w:r></w:sdtContent></w:sdt>`
+
+	signed := time.Date(2024, time.April, 30, 0, 0, 0, 0, time.UTC)
+	outputContent := applyControls(t, map[string]any{"signed": signed}, inputContent)
+
+	if !strings.Contains(outputContent, `<w:date w:fullDate="2024-04-30T00:00:00Z">`) {
+		t.Errorf("Expected full date to be set, got '%s'", outputContent)
+	}
+	if !strings.Contains(outputContent, "Tuesday, April 30, 2024") {
+		t.Errorf("Expected formatted date, got '%s'", outputContent)
+	}
+}
+
+func TestContentControlWriter_Checkbox(t *testing.T) {
+	inputContent := `<w:sdt><w:sdtPr><w:tag w:val="agree"/><w14:checkbox><w14:checked w14:val="0"/><w14:checkedState w14:val="2612" w14:font="MS Gothic"/><w14:uncheckedState w14:val="2610" w14:font="MS Gothic"/></w14:checkbox></w:sdtPr><w:sdtContent><w:r><w:rPr><w:rFonts w:ascii="MS Gothic"/></w:rPr><w:t>☐</w:t></w:r></w:sdtContent></w:sdt>`
+
+	outputContent := applyControls(t, map[string]any{"agree": true}, inputContent)
+
+	if !strings.Contains(outputContent, `<w14:checked w14:val="1"/>`) {
+		t.Errorf("Expected checkbox to be checked, got '%s'", outputContent)
+	}
+	if !strings.Contains(outputContent, `<w:r><w:rPr><w:rFonts w:ascii="MS Gothic"/></w:rPr><w:t xml:space="preserve">☒</w:t></w:r>`) {
+		t.Errorf("Expected checked glyph, got '%s'", outputContent)
+	}
+}
+
+func TestContentControlWriter_RepeatingSection(t *testing.T) {
+	item := `<w:sdt><w:sdtPr><w:id w:val="2"/><w15:repeatingSectionItem/></w:sdtPr><w:sdtContent><w:p><w:sdt><w:sdtPr><w:id w:val="3"/><w:tag w:val="name"/></w:sdtPr><w:sdtContent><w:r><w:t>Name</w:t></w:r></w:sdtContent></w:sdt></w:p></w:sdtContent></w:sdt>`
+	inputContent := `<w:sdt><w:sdtPr><w:id w:val="1"/><w:tag w:val="people"/><w15:repeatingSection/></w:sdtPr><w:sdtContent>` + item + `</w:sdtContent></w:sdt>`
+
+	outputContent := applyControls(t, map[string]any{
+		"people": []map[string]any{{"name": "Ali"}, {"name": "Sara"}},
+	}, inputContent)
+
+	if strings.Count(outputContent, "<w15:repeatingSectionItem/>") != 2 {
+		t.Errorf("Expected one section item per entry, got '%s'", outputContent)
+	}
+	if !strings.Contains(outputContent, ">Ali</w:t>") || !strings.Contains(outputContent, ">Sara</w:t>") {
+		t.Errorf("Expected nested controls to be filled per entry, got '%s'", outputContent)
+	}
+	if strings.Count(outputContent, `<w:id w:val="3"/>`) != 1 {
+		t.Errorf("Expected copied controls to drop their ids, got '%s'", outputContent)
+	}
+}
+
+func TestFormatWordDate(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 14, 7, 0, 0, time.UTC)
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"M/d/yyyy", "3/5/2024"},
+		{"dd.MM.yy", "05.03.24"},
+		{"dddd, MMMM d, yyyy", "Tuesday, March 5, 2024"},
+		{"d MMM yyyy 'at' h:mm AM/PM", "5 Mar 2024 at 2:07 PM"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if got := FormatWordDate(date, tt.format); got != tt.expected {
+				t.Errorf("FormatWordDate(%q) = %q, want %q", tt.format, got, tt.expected)
+			}
+		})
+	}
+}
+
+// applyControls runs ContentControlWriter over content
+func applyControls(t *testing.T, values map[string]any, content string) string {
+	outputContent, err := ContentControlWriter(values)()(content)
+	if err != nil {
+		t.Fatalf("DocxWriter returned an error: %v", err)
+	}
+	return outputContent
+}
@@ -0,0 +1,352 @@
+package contentcontrol
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aliamerj/docxer/internal/opc"
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+const (
+	customXMLRelType      = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/customXml"
+	customXMLPropsRelType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/customXmlProps"
+	customXMLPropsType    = "application/vnd.openxmlformats-officedocument.customXmlProperties+xml"
+	documentPath          = "word/document.xml"
+)
+
+var (
+	xpathStepPattern = regexp.MustCompile(`^(@)?(?:([\w.-]+):)?([\w.-]+)(?:\[(\d+)\])?$`)
+	prefixPattern    = regexp.MustCompile(`xmlns:([\w.-]+)=['"]([^'"]*)['"]`)
+)
+
+// UpdateCustomXML stores payload as the Custom XML item the content controls
+// of the DOCX file are bound to, the one whose item id is the w:storeItemID
+// of their w:dataBinding, and refreshes the cached values of those controls.
+// Without such an item a new one is created with that id, so templates bound
+// to a store item that is missing find it. A document without bound controls
+// has its first item replaced, or gets one with a new id.
+func UpdateCustomXML(filePath string, payload []byte) error {
+	root, err := parseNode(payload)
+	if err != nil {
+		return fmt.Errorf("error parsing custom XML payload: %w", err)
+	}
+
+	pkg, err := opc.Open(filePath)
+	if err != nil {
+		return err
+	}
+
+	storeItemID := boundStoreItemID(pkg)
+	itemPath, itemID := customXMLItem(pkg, storeItemID)
+	if itemPath == "" {
+		if storeItemID == "" {
+			storeItemID = newGUID()
+		}
+		itemPath, itemID = pkg.NewPartName("customXml/item%d.xml"), storeItemID
+		if err := addCustomXMLPart(pkg, itemPath, itemID); err != nil {
+			return err
+		}
+	}
+	pkg.SetPart(itemPath, "", payload)
+
+	err = pkg.Transform(utils.TransformerFunc(func(name, _ string, content []byte) ([]byte, error) {
+		if utils.IsStoryPart(name) {
+			return []byte(bindControls(string(content), root, itemID)), nil
+		}
+		return content, nil
+	}))
+	if err != nil {
+		return err
+	}
+	return pkg.Save(filePath)
+}
+
+// customXMLItem returns the name and id of the Custom XML item of the
+// document whose properties give it the id storeItemID, or of its first item
+// for an empty storeItemID. The name is "" when there is no such item.
+func customXMLItem(pkg *opc.Package, storeItemID string) (string, string) {
+	for _, relationship := range pkg.Relationships(documentPath) {
+		if relationship.Type != customXMLRelType || relationship.External {
+			continue
+		}
+		item := utils.ResolveTarget(documentPath, relationship.Target)
+		id := ""
+		props, _ := pkg.Part(pkg.Related(item, customXMLPropsRelType))
+		if datastoreItem, ok := utils.FindElement(string(props), "ds:datastoreItem", 0); ok {
+			id, _ = utils.Attr(datastoreItem.StartTag(string(props)), "ds:itemID")
+		}
+		if storeItemID == "" || strings.EqualFold(id, storeItemID) {
+			return item, id
+		}
+	}
+	return "", ""
+}
+
+// addCustomXMLPart registers a new Custom XML item together with its
+// properties part, which gives the item the id itemID that bindings refer to.
+func addCustomXMLPart(pkg *opc.Package, itemPath, itemID string) error {
+	if _, ok := pkg.Part(utils.ContentTypesPath); !ok {
+		return fmt.Errorf("the document has no %s", utils.ContentTypesPath)
+	}
+
+	dir, file := path.Split(itemPath)
+	propsPath := dir + strings.Replace(file, "item", "itemProps", 1)
+	propsXML := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n" +
+		`<ds:datastoreItem ds:itemID="` + utils.EscapeXML(itemID) + `" xmlns:ds="http://schemas.openxmlformats.org/officeDocument/2006/customXml"><ds:schemaRefs/></ds:datastoreItem>`
+	pkg.SetPart(propsPath, customXMLPropsType, []byte(propsXML))
+	pkg.Relate(itemPath, customXMLPropsRelType, propsPath)
+	pkg.Relate(documentPath, customXMLRelType, itemPath)
+	return nil
+}
+
+// boundStoreItemID returns the w:storeItemID of the first control of the
+// document bound with w:dataBinding, or "" when no control has one.
+func boundStoreItemID(pkg *opc.Package) string {
+	names := []string{documentPath}
+	for _, part := range pkg.Parts() {
+		if part.Name != documentPath && utils.IsStoryPart(part.Name) {
+			names = append(names, part.Name)
+		}
+	}
+	for _, name := range names {
+		content, _ := pkg.Part(name)
+		for _, binding := range utils.FindElements(string(content), "w:dataBinding") {
+			if id, ok := utils.Attr(binding.StartTag(string(content)), "w:storeItemID"); ok && id != "" {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+func newGUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("{%X-%X-%X-%X-%X}", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// bindControls sets every control with a w:dataBinding to the value its XPath
+// selects in root, the Custom XML item with the id itemID. Controls bound to
+// other items, and those whose XPath selects nothing, keep their content.
+func bindControls(content string, root *node, itemID string) string {
+	controls := utils.FindElements(content, "w:sdt")
+	if len(controls) == 0 {
+		return content
+	}
+	var result strings.Builder
+	last := 0
+	for _, control := range controls {
+		result.WriteString(content[last:control.Start])
+		result.WriteString(bindControl(control.Outer(content), root, itemID))
+		last = control.End
+	}
+	result.WriteString(content[last:])
+	return result.String()
+}
+
+func bindControl(sdt string, root *node, itemID string) string {
+	props, ok := utils.FindElement(sdt, "w:sdtPr", 0)
+	if !ok {
+		return sdt
+	}
+	body, ok := utils.FindElement(sdt, "w:sdtContent", props.End)
+	if !ok {
+		return sdt
+	}
+	properties := props.Outer(sdt)
+	binding, ok := utils.FindElement(properties, "w:dataBinding", 0)
+	if !ok {
+		return sdt[:body.InnerStart] + bindControls(body.Inner(sdt), root, itemID) + sdt[body.InnerEnd:]
+	}
+
+	tag := binding.StartTag(properties)
+	if storeItemID, _ := utils.Attr(tag, "w:storeItemID"); storeItemID != "" && !strings.EqualFold(storeItemID, itemID) {
+		return sdt
+	}
+	xpath, _ := utils.Attr(tag, "w:xpath")
+	mappings, _ := utils.Attr(tag, "w:prefixMappings")
+	value, ok := selectXPath(root, xpath, prefixes(mappings))
+	if !ok {
+		return sdt
+	}
+	return setValue(sdt, props, body, value)
+}
+
+func prefixes(mappings string) map[string]string {
+	result := map[string]string{}
+	for _, match := range prefixPattern.FindAllStringSubmatch(mappings, -1) {
+		result[match[1]] = match[2]
+	}
+	return result
+}
+
+// node is a parsed element of a Custom XML part.
+type node struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*node
+	text     strings.Builder
+}
+
+func parseNode(payload []byte) (*node, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(payload))
+	document := &node{}
+	stack := []*node{document}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			child := &node{name: t.Name, attrs: t.Attr}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, child)
+			stack = append(stack, child)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			for _, n := range stack[1:] {
+				n.text.Write(t)
+			}
+		}
+	}
+	if len(document.children) != 1 {
+		return nil, fmt.Errorf("expected a single root element, found %d", len(document.children))
+	}
+	return document, nil
+}
+
+// selectXPath evaluates the absolute location paths Word writes into
+// w:dataBinding, such as "/ns0:invoice[1]/ns0:total[1]" or "/root[1]/@id".
+func selectXPath(document *node, xpath string, namespaces map[string]string) (string, bool) {
+	if !strings.HasPrefix(xpath, "/") {
+		return "", false
+	}
+	current := document
+	steps := strings.Split(strings.TrimPrefix(xpath, "/"), "/")
+	for i, step := range steps {
+		match := xpathStepPattern.FindStringSubmatch(step)
+		if match == nil {
+			return "", false
+		}
+		attribute, prefix, local := match[1] == "@", match[2], match[3]
+		if attribute {
+			if i != len(steps)-1 {
+				return "", false
+			}
+			for _, attr := range current.attrs {
+				if attr.Name.Local == local && matchesNamespace(attr.Name.Space, prefix, namespaces) {
+					return attr.Value, true
+				}
+			}
+			return "", false
+		}
+
+		position := 1
+		if match[4] != "" {
+			position, _ = strconv.Atoi(match[4])
+		}
+		var next *node
+		for _, child := range current.children {
+			if child.name.Local != local || !matchesNamespace(child.name.Space, prefix, namespaces) {
+				continue
+			}
+			position--
+			if position == 0 {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return "", false
+		}
+		current = next
+	}
+	return current.text.String(), true
+}
+
+func matchesNamespace(space, prefix string, namespaces map[string]string) bool {
+	if prefix == "" {
+		return space == ""
+	}
+	uri, ok := namespaces[prefix]
+	if !ok {
+		// Without a mapping the prefix cannot be resolved, so only the local name counts.
+		return true
+	}
+	return space == uri
+}
+
+// CustomXMLPayload converts the value given for a Custom XML part into XML.
+// Strings and byte slices are used as they are. A map is written as elements
+// in key order; when it has a single key holding a map, that key becomes the
+// root element, otherwise the elements are wrapped in <root>.
+func CustomXMLPayload(payload any) ([]byte, error) {
+	switch v := payload.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	case map[string]any:
+		root, children := "root", v
+		if len(v) == 1 {
+			for key, value := range v {
+				if m, ok := value.(map[string]any); ok {
+					root, children = key, m
+				}
+			}
+		}
+		var buf strings.Builder
+		buf.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+		writeElement(&buf, root, children)
+		return []byte(buf.String()), nil
+	}
+	return nil, fmt.Errorf("unsupported custom XML payload of type %T", payload)
+}
+
+func writeElement(buf *strings.Builder, name string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buf.WriteString("<" + name + ">")
+		for _, key := range keys {
+			writeElement(buf, key, v[key])
+		}
+		buf.WriteString("</" + name + ">")
+	case []any:
+		for _, item := range v {
+			writeElement(buf, name, item)
+		}
+	case []map[string]any:
+		for _, item := range v {
+			writeElement(buf, name, item)
+		}
+	case []string:
+		for _, item := range v {
+			writeElement(buf, name, item)
+		}
+	case nil:
+		buf.WriteString("<" + name + "/>")
+	default:
+		buf.WriteString("<" + name + ">" + utils.EscapeXML(utils.FormatValue(v)) + "</" + name + ">")
+	}
+}
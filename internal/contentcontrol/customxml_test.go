@@ -0,0 +1,179 @@
+package contentcontrol
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+func TestUpdateCustomXML(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "testUpdateCustomXML")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	filePath := filepath.Join(tempDir, "bound.docx")
+	file, err := os.Create(filePath)
+	if err != nil {
+		t.Fatalf("Failed to create test docx: %v", err)
+	}
+	zipWriter := zip.NewWriter(file)
+	testParts := map[string]string{
+		"[Content_Types].xml": `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="xml" ContentType="application/xml"/></Types>`,
+		"word/document.xml": `<w:body><w:p><w:sdt><w:sdtPr><w:dataBinding w:prefixMappings="xmlns:ns0='urn:invoice'" w:xpath="/ns0:invoice[1]/ns0:customer[1]" w:storeItemID="{X}"/><w:showingPlcHdr/><w:text/></w:sdtPr><w:sdtContent><w:r><w:t>Customer</w:t></w:r></w:sdtContent></w:sdt></w:p>` +
+			`<w:p><w:sdt><w:sdtPr><w:dataBinding w:xpath="/ns0:invoice[1]/ns0:missing[1]"/></w:sdtPr><w:sdtContent><w:r><w:t>Unchanged</w:t></w:r></w:sdtContent></w:sdt></w:p></w:body>`,
+	}
+	for _, name := range []string{"[Content_Types].xml", "word/document.xml"} {
+		writer, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		writer.Write([]byte(testParts[name]))
+	}
+	zipWriter.Close()
+	file.Close()
+
+	payload := `<invoice xmlns="urn:invoice"><customer>ACME</customer></invoice>`
+	for i := 0; i < 2; i++ {
+		if err := UpdateCustomXML(filePath, []byte(payload)); err != nil {
+			t.Fatalf("UpdateCustomXML returned an error: %v", err)
+		}
+	}
+
+	parts, err := utils.ReadDocx(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read updated docx: %v", err)
+	}
+	content := map[string]string{}
+	for _, part := range parts {
+		content[part.Name] = string(part.Content)
+	}
+
+	if content["customXml/item1.xml"] != payload {
+		t.Errorf("Expected item1.xml to hold the payload, got %q", content["customXml/item1.xml"])
+	}
+	if !strings.Contains(content["customXml/itemProps1.xml"], `ds:itemID="{X}"`) {
+		t.Errorf("Expected item properties with the item id of the bound control, got %q", content["customXml/itemProps1.xml"])
+	}
+	if !strings.Contains(content["customXml/_rels/item1.xml.rels"], `Target="itemProps1.xml"`) {
+		t.Errorf("Expected item relationships, got %q", content["customXml/_rels/item1.xml.rels"])
+	}
+	if strings.Count(content["word/_rels/document.xml.rels"], `Target="../customXml/item1.xml"`) != 1 {
+		t.Errorf("Expected a single custom XML relationship, got %q", content["word/_rels/document.xml.rels"])
+	}
+	if strings.Count(content["[Content_Types].xml"], `PartName="/customXml/itemProps1.xml"`) != 1 {
+		t.Errorf("Expected item properties to be registered once, got %q", content["[Content_Types].xml"])
+	}
+	document := content["word/document.xml"]
+	if !strings.Contains(document, `<w:t xml:space="preserve">ACME</w:t>`) || strings.Contains(document, "showingPlcHdr") {
+		t.Errorf("Expected bound control to show the payload value, got %q", document)
+	}
+	if !strings.Contains(document, "<w:t>Unchanged</w:t>") {
+		t.Errorf("Expected control bound to a missing node to keep its content, got %q", document)
+	}
+}
+
+func TestSelectXPath(t *testing.T) {
+	root, err := parseNode([]byte(`<ns:root xmlns:ns="urn:a" id="7"><ns:item>One</ns:item><ns:item>Two</ns:item><plain>P</plain></ns:root>`))
+	if err != nil {
+		t.Fatalf("parseNode returned an error: %v", err)
+	}
+	namespaces := map[string]string{"a": "urn:a"}
+
+	tests := []struct {
+		xpath    string
+		expected string
+		found    bool
+	}{
+		{"/a:root[1]/a:item[2]", "Two", true},
+		{"/a:root/a:item", "One", true},
+		{"/a:root[1]/@id", "7", true},
+		{"/a:root[1]/plain[1]", "P", true},
+		{"/a:root[1]/a:item[3]", "", false},
+		{"root/item", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.xpath, func(t *testing.T) {
+			got, found := selectXPath(root, tt.xpath, namespaces)
+			if got != tt.expected || found != tt.found {
+				t.Errorf("selectXPath(%q) = %q, %v; want %q, %v", tt.xpath, got, found, tt.expected, tt.found)
+			}
+		})
+	}
+}
+
+func TestCustomXMLPayload(t *testing.T) {
+	payload, err := CustomXMLPayload(map[string]any{
+		"invoice": map[string]any{
+			"number": 42,
+			"lines":  []any{"A & B", "C"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CustomXMLPayload returned an error: %v", err)
+	}
+	expected := `<invoice><lines>A &amp; B</lines><lines>C</lines><number>42</number></invoice>`
+	if !strings.HasSuffix(string(payload), expected) {
+		t.Errorf("Expected payload ending with %q, got %q", expected, payload)
+	}
+
+	if _, err := CustomXMLPayload(42); err == nil {
+		t.Errorf("Expected error for unsupported payload")
+	}
+}
+
+func TestUpdateCustomXML_SeveralItems(t *testing.T) {
+	const rels = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`
+	properties := `<p:properties xmlns:p="http://schemas.microsoft.com/office/2006/metadata/properties"><documentManagement/></p:properties>`
+	filePath := filepath.Join(t.TempDir(), "sharepoint.docx")
+	err := utils.WriteDocx(filePath, []utils.DocxPart{
+		{Name: "[Content_Types].xml", Content: []byte(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="xml" ContentType="application/xml"/></Types>`)},
+		{Name: "word/document.xml", Content: []byte(`<w:body><w:p><w:sdt><w:sdtPr><w:dataBinding w:xpath="/invoice[1]/customer[1]" w:storeItemID="{b0000000-0000-0000-0000-000000000002}"/><w:text/></w:sdtPr>` +
+			`<w:sdtContent><w:r><w:t>Customer</w:t></w:r></w:sdtContent></w:sdt></w:p>` +
+			`<w:p><w:sdt><w:sdtPr><w:dataBinding w:xpath="/invoice[1]/customer[1]" w:storeItemID="{A0000000-0000-0000-0000-000000000001}"/><w:text/></w:sdtPr>` +
+			`<w:sdtContent><w:r><w:t>Owner</w:t></w:r></w:sdtContent></w:sdt></w:p></w:body>`)},
+		{Name: "word/_rels/document.xml.rels", Content: []byte(rels +
+			`<Relationship Id="rId1" Type="` + customXMLRelType + `" Target="../customXml/item1.xml"/>` +
+			`<Relationship Id="rId2" Type="` + customXMLRelType + `" Target="../customXml/item2.xml"/></Relationships>`)},
+		{Name: "customXml/item1.xml", Content: []byte(properties)},
+		{Name: "customXml/_rels/item1.xml.rels", Content: []byte(rels + `<Relationship Id="rId1" Type="` + customXMLPropsRelType + `" Target="itemProps1.xml"/></Relationships>`)},
+		{Name: "customXml/itemProps1.xml", Content: []byte(`<ds:datastoreItem ds:itemID="{A0000000-0000-0000-0000-000000000001}" xmlns:ds="http://schemas.openxmlformats.org/officeDocument/2006/customXml"/>`)},
+		{Name: "customXml/item2.xml", Content: []byte(`<invoice/>`)},
+		{Name: "customXml/_rels/item2.xml.rels", Content: []byte(rels + `<Relationship Id="rId1" Type="` + customXMLPropsRelType + `" Target="itemProps2.xml"/></Relationships>`)},
+		{Name: "customXml/itemProps2.xml", Content: []byte(`<ds:datastoreItem ds:itemID="{B0000000-0000-0000-0000-000000000002}" xmlns:ds="http://schemas.openxmlformats.org/officeDocument/2006/customXml"/>`)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test docx: %v", err)
+	}
+
+	payload := `<invoice><customer>ACME</customer></invoice>`
+	if err := UpdateCustomXML(filePath, []byte(payload)); err != nil {
+		t.Fatalf("UpdateCustomXML returned an error: %v", err)
+	}
+
+	parts, err := utils.ReadDocx(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read updated docx: %v", err)
+	}
+	content := map[string]string{}
+	for _, part := range parts {
+		content[part.Name] = string(part.Content)
+	}
+	if content["customXml/item1.xml"] != properties {
+		t.Errorf("Expected the properties item to be kept, got %q", content["customXml/item1.xml"])
+	}
+	if content["customXml/item2.xml"] != payload {
+		t.Errorf("Expected the bound item to hold the payload, got %q", content["customXml/item2.xml"])
+	}
+	if _, ok := content["customXml/item3.xml"]; ok {
+		t.Errorf("Expected no new item, got %q", content["customXml/item3.xml"])
+	}
+	if document := content["word/document.xml"]; strings.Count(document, "ACME") != 1 || !strings.Contains(document, "<w:t>Owner</w:t>") {
+		t.Errorf("Expected only the control bound to the item to show the payload value, got %q", document)
+	}
+}
@@ -0,0 +1,235 @@
+package contentcontrol
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+var fldCharPattern = regexp.MustCompile(`<w:fldChar\s[^>]*?w:fldCharType="(begin|separate|end)"[^>]*?(/>|>)`)
+
+// ExtractData reads the values entered in a filled-in DOCX. Content controls
+// are keyed by their tag (or alias when they have no tag) and legacy form
+// fields by their bookmark name. Checkboxes become bools, dates time.Time,
+// dropdowns the value of the selected entry and repeating sections a
+// []map[string]any with one map per item.
+func ExtractData(filePath string) (map[string]any, error) {
+	parts, err := utils.ReadDocx(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]any{}
+	for _, part := range parts {
+		if !utils.IsStoryPart(path.Clean(part.Name)) {
+			continue
+		}
+		content := string(part.Content)
+		extractControls(content, data)
+		extractFormFields(content, data)
+	}
+	return data, nil
+}
+
+func extractControls(content string, data map[string]any) {
+	for _, control := range utils.FindElements(content, "w:sdt") {
+		sdt := control.Outer(content)
+		props, ok := utils.FindElement(sdt, "w:sdtPr", 0)
+		if !ok {
+			continue
+		}
+		body, ok := utils.FindElement(sdt, "w:sdtContent", props.End)
+		if !ok {
+			continue
+		}
+		properties, inner := props.Outer(sdt), body.Inner(sdt)
+
+		key := controlKey(properties)
+		if key == "" {
+			extractControls(inner, data)
+			continue
+		}
+		switch {
+		case strings.Contains(properties, "<w15:repeatingSection"):
+			data[key] = extractSection(inner)
+			continue
+		case strings.Contains(properties, "<w:showingPlcHdr"):
+			data[key] = ""
+		case strings.Contains(properties, "<w14:checkbox"):
+			data[key] = checkboxState(properties)
+		case strings.Contains(properties, "<w:dropDownList") || strings.Contains(properties, "<w:comboBox"):
+			data[key] = selectedItem(properties, utils.ExtractText(inner))
+		case strings.Contains(properties, "<w:date"):
+			data[key] = dateValue(properties, utils.ExtractText(inner))
+		default:
+			data[key] = utils.ExtractText(inner)
+		}
+		// Controls nested inside a filled control still report their own values.
+		extractControls(inner, data)
+	}
+}
+
+func extractSection(content string) []map[string]any {
+	items := []map[string]any{}
+	for _, element := range utils.FindElements(content, "w:sdt") {
+		if !strings.Contains(element.Outer(content), "<w15:repeatingSectionItem") {
+			continue
+		}
+		item := map[string]any{}
+		extractControls(element.Outer(content), item)
+		items = append(items, item)
+	}
+	return items
+}
+
+func controlKey(properties string) string {
+	for _, name := range []string{"w:tag", "w:alias"} {
+		if element, ok := utils.FindElement(properties, name, 0); ok {
+			if key, ok := utils.Attr(element.StartTag(properties), "w:val"); ok && key != "" {
+				return key
+			}
+		}
+	}
+	return ""
+}
+
+func checkboxState(properties string) bool {
+	element, ok := utils.FindElement(properties, "w14:checked", 0)
+	if !ok {
+		return false
+	}
+	val, _ := utils.Attr(element.StartTag(properties), "w14:val")
+	return val == "1" || val == "true"
+}
+
+// selectedItem maps the displayed text of a dropdown back to the value of its
+// entry. Free text typed into a combo box is returned as is.
+func selectedItem(properties, display string) string {
+	for _, item := range utils.FindElements(properties, "w:listItem") {
+		tag := item.StartTag(properties)
+		value, _ := utils.Attr(tag, "w:value")
+		text, ok := utils.Attr(tag, "w:displayText")
+		if !ok {
+			text = value
+		}
+		if text == display {
+			return value
+		}
+	}
+	return display
+}
+
+func dateValue(properties, display string) any {
+	if element, ok := utils.FindElement(properties, "w:date", 0); ok {
+		if fullDate, ok := utils.Attr(element.StartTag(properties), "w:fullDate"); ok {
+			if date, ok := utils.ToTime(fullDate); ok {
+				return date
+			}
+		}
+	}
+	if date, ok := utils.ToTime(display); ok {
+		return date
+	}
+	return display
+}
+
+// extractFormFields reads legacy form fields (FORMTEXT, FORMCHECKBOX and
+// FORMDROPDOWN), which store their settings in w:ffData inside the field's
+// begin character.
+func extractFormFields(content string, data map[string]any) {
+	matches := fldCharPattern.FindAllStringSubmatchIndex(content, -1)
+	for i, match := range matches {
+		if content[match[2]:match[3]] != "begin" {
+			continue
+		}
+		begin := content[match[0]:match[1]]
+		beginEnd := match[1]
+		if strings.HasSuffix(begin, ">") && !strings.HasSuffix(begin, "/>") {
+			element, ok := utils.FindElement(content, "w:fldChar", match[0])
+			if !ok {
+				continue
+			}
+			begin, beginEnd = element.Outer(content), element.End
+		}
+		if !strings.Contains(begin, "<w:ffData") {
+			continue
+		}
+		name := ""
+		if element, ok := utils.FindElement(begin, "w:name", 0); ok {
+			name, _ = utils.Attr(element.StartTag(begin), "w:val")
+		}
+		if name == "" {
+			continue
+		}
+
+		switch {
+		case strings.Contains(begin, "<w:checkBox"):
+			data[name] = formCheckbox(begin)
+		case strings.Contains(begin, "<w:ddList"):
+			data[name] = formDropdown(begin)
+		default:
+			data[name] = fieldResult(content, beginEnd, matches[i+1:])
+		}
+	}
+}
+
+func formCheckbox(ffData string) bool {
+	if element, ok := utils.FindElement(ffData, "w:checked", 0); ok {
+		val, found := utils.Attr(element.StartTag(ffData), "w:val")
+		return !found || val == "1" || val == "true"
+	}
+	if element, ok := utils.FindElement(ffData, "w:default", 0); ok {
+		val, _ := utils.Attr(element.StartTag(ffData), "w:val")
+		return val == "1" || val == "true"
+	}
+	return false
+}
+
+func formDropdown(ffData string) string {
+	selected := 0
+	if element, ok := utils.FindElement(ffData, "w:result", 0); ok {
+		val, _ := utils.Attr(element.StartTag(ffData), "w:val")
+		selected, _ = strconv.Atoi(val)
+	} else if element, ok := utils.FindElement(ffData, "w:default", 0); ok {
+		val, _ := utils.Attr(element.StartTag(ffData), "w:val")
+		selected, _ = strconv.Atoi(val)
+	}
+	entries := utils.FindElements(ffData, "w:listEntry")
+	if selected < 0 || selected >= len(entries) {
+		return ""
+	}
+	value, _ := utils.Attr(entries[selected].StartTag(ffData), "w:val")
+	return value
+}
+
+// fieldResult returns the text between the separate and end characters of the
+// field that begins at offset, skipping over nested fields.
+func fieldResult(content string, offset int, following [][]int) string {
+	depth := 1
+	resultStart := -1
+	for _, match := range following {
+		if match[0] < offset {
+			continue
+		}
+		switch content[match[2]:match[3]] {
+		case "begin":
+			depth++
+		case "separate":
+			if depth == 1 {
+				resultStart = match[1]
+			}
+		case "end":
+			depth--
+			if depth == 0 {
+				if resultStart == -1 {
+					return ""
+				}
+				return utils.ExtractText(content[resultStart:match[0]])
+			}
+		}
+	}
+	return ""
+}
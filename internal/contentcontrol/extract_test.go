@@ -0,0 +1,103 @@
+package contentcontrol
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExtractData(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "testExtractData")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	document := `<w:document><w:body>` +
+		`<w:p><w:sdt><w:sdtPr><w:tag w:val="name"/><w:text/></w:sdtPr><w:sdtContent><w:r><w:t>Ali &amp; Sara</w:t></w:r></w:sdtContent></w:sdt></w:p>` +
+		`<w:p><w:sdt><w:sdtPr><w:tag w:val="empty"/><w:showingPlcHdr/></w:sdtPr><w:sdtContent><w:r><w:t>Click here</w:t></w:r></w:sdtContent></w:sdt></w:p>` +
+		`<w:p><w:sdt><w:sdtPr><w:tag w:val="agree"/><w14:checkbox><w14:checked w14:val="1"/></w14:checkbox></w:sdtPr><w:sdtContent><w:r><w:t>☒</w:t></w:r></w:sdtContent></w:sdt></w:p>` +
+		`<w:p><w:sdt><w:sdtPr><w:alias w:val="Country"/><w:dropDownList><w:listItem w:displayText="Germany" w:value="DE"/></w:dropDownList></w:sdtPr><w:sdtContent><w:r><w:t>Germany</w:t></w:r></w:sdtContent></w:sdt></w:p>` +
+		`<w:p><w:sdt><w:sdtPr><w:tag w:val="signed"/><w:date w:fullDate="2024-04-30T00:00:00Z"><w:dateFormat w:val="d.M.yyyy"/></w:date></w:sdtPr><w:sdtContent><w:r><w:t>30.4.2024</w:t></w:r></w:sdtContent></w:sdt></w:p>` +
+		`<w:sdt><w:sdtPr><w:tag w:val="people"/><w15:repeatingSection/></w:sdtPr><w:sdtContent>` +
+		`<w:sdt><w:sdtPr><w15:repeatingSectionItem/></w:sdtPr><w:sdtContent><w:p><w:sdt><w:sdtPr><w:tag w:val="person"/></w:sdtPr><w:sdtContent><w:r><w:t>Ali</w:t></w:r></w:sdtContent></w:sdt></w:p></w:sdtContent></w:sdt>` +
+		`<w:sdt><w:sdtPr><w15:repeatingSectionItem/></w:sdtPr><w:sdtContent><w:p><w:sdt><w:sdtPr><w:tag w:val="person"/></w:sdtPr><w:sdtContent><w:r><w:t>Sara</w:t></w:r></w:sdtContent></w:sdt></w:p></w:sdtContent></w:sdt>` +
+		`</w:sdtContent></w:sdt>` +
+		`<w:p><w:r><w:fldChar w:fldCharType="begin"><w:ffData><w:name w:val="Phone"/><w:textInput/></w:ffData></w:fldChar></w:r><w:r><w:instrText xml:space="preserve"> FORMTEXT </w:instrText></w:r><w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:t>555</w:t></w:r><w:r><w:t>-0100</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r></w:p>` +
+		`<w:p><w:r><w:fldChar w:fldCharType="begin"><w:ffData><w:name w:val="Subscribe"/><w:checkBox><w:sizeAuto/><w:default w:val="0"/><w:checked/></w:checkBox></w:ffData></w:fldChar></w:r><w:r><w:instrText> FORMCHECKBOX </w:instrText></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r></w:p>` +
+		`<w:p><w:r><w:fldChar w:fldCharType="begin"><w:ffData><w:name w:val="Size"/><w:ddList><w:result w:val="1"/><w:listEntry w:val="S"/><w:listEntry w:val="M"/></w:ddList></w:ffData></w:fldChar></w:r><w:r><w:instrText> FORMDROPDOWN </w:instrText></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r></w:p>` +
+		`</w:body></w:document>`
+	filePath := filepath.Join(tempDir, "filled.docx")
+	createTestDocx(t, filePath, document)
+
+	data, err := ExtractData(filePath)
+	if err != nil {
+		t.Fatalf("ExtractData returned an error: %v", err)
+	}
+
+	expected := map[string]any{
+		"name":      "Ali & Sara",
+		"empty":     "",
+		"agree":     true,
+		"Country":   "DE",
+		"signed":    time.Date(2024, time.April, 30, 0, 0, 0, 0, time.UTC),
+		"Phone":     "555-0100",
+		"Subscribe": true,
+		"Size":      "M",
+	}
+	for key, want := range expected {
+		got, ok := data[key]
+		if !ok {
+			t.Errorf("Expected key %q to be extracted", key)
+			continue
+		}
+		if date, ok := want.(time.Time); ok {
+			if gotDate, ok := got.(time.Time); !ok || !gotDate.Equal(date) {
+				t.Errorf("data[%q] = %v, want %v", key, got, want)
+			}
+			continue
+		}
+		if got != want {
+			t.Errorf("data[%q] = %v, want %v", key, got, want)
+		}
+	}
+
+	people, ok := data["people"].([]map[string]any)
+	if !ok || len(people) != 2 {
+		t.Fatalf("Expected two repeating section items, got %v", data["people"])
+	}
+	if people[0]["person"] != "Ali" || people[1]["person"] != "Sara" {
+		t.Errorf("Unexpected repeating section items: %v", people)
+	}
+	if _, ok := data["person"]; ok {
+		t.Errorf("Expected repeating section items not to leak into the top level")
+	}
+}
+
+func TestExtractData_FileDoesNotExist(t *testing.T) {
+	if _, err := ExtractData("/path/to/nonexistent.docx"); err == nil {
+		t.Errorf("Expected error for non-existent file")
+	}
+}
+
+// createTestDocx creates a DOCX file holding only the given document.xml
+func createTestDocx(t *testing.T, filePath string, content string) {
+	file, err := os.Create(filePath)
+	if err != nil {
+		t.Fatalf("Failed to create test docx: %v", err)
+	}
+	defer file.Close()
+
+	zipWriter := zip.NewWriter(file)
+	defer zipWriter.Close()
+
+	writer, err := zipWriter.Create("word/document.xml")
+	if err != nil {
+		t.Fatalf("Failed to create document.xml: %v", err)
+	}
+	if _, err := writer.Write([]byte(content)); err != nil {
+		t.Fatalf("Failed to write document.xml: %v", err)
+	}
+}
@@ -1,9 +1,7 @@
 package document
 
 import (
-	"archive/zip"
 	"embed"
-	"os"
 	"path/filepath"
 	"strings"
 
@@ -14,30 +12,28 @@ import (
 //go:embed template/*
 var documentXml embed.FS
 
-func CreateNewDocx(dirPath string, title string, body string) (string, error) {
+func CreateNewDocx(dirPath string, title string, body string, transformers ...utils.Transformer) (string, error) {
 	outputFilePath := filepath.Join(dirPath, "new_file.docx")
-	file, err := os.Create(outputFilePath)
+	pkg, err := template.NewPackage()
 	if err != nil {
 		return "", err
 	}
-	defer file.Close()
-	zipFile := zip.NewWriter(file)
-	defer zipFile.Close()
-
-	if err := template.CreateDocxTemplate(zipFile); err != nil {
+	if err := template.AddWordParts(pkg, documentXml); err != nil {
+		return "", err
+	}
+	if err := pkg.Transform(utils.Chain(docxWriter(title, body), utils.Chain(transformers...))); err != nil {
 		return "", err
 	}
-	docxer := docxWriter(title, body)
-	if err := utils.CreateDocx(documentXml, zipFile, docxer); err != nil {
+	if err := pkg.Save(outputFilePath); err != nil {
 		return "", err
 	}
-	return outputFilePath, err
+	return outputFilePath, nil
 }
 func docxWriter(title string, body string) utils.DocxWriter {
 
-	return func(fileContent string) string {
+	return func(fileContent string) (string, error) {
 		updatedTemplate := strings.Replace(fileContent, "{{TITLE}}", title, -1)
 		updatedTemplate = strings.Replace(updatedTemplate, "{{BODY}}", body, -1)
-		return updatedTemplate
+		return updatedTemplate, nil
 	}
 }
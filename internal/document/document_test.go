@@ -2,10 +2,13 @@ package document
 
 import (
 	"archive/zip"
+	"errors"
 	"io"
 	"os"
 	"strings"
 	"testing"
+
+	"github.com/aliamerj/docxer/internal/utils"
 )
 
 func TestCreateNewDocx(t *testing.T) {
@@ -68,3 +71,38 @@ func TestCreateNewDocx(t *testing.T) {
 		t.Errorf("document.xml was not found in the zip")
 	}
 }
+
+func TestCreateNewDocx_Transformers(t *testing.T) {
+	dir := t.TempDir()
+	seen := map[string]string{}
+	stamp := utils.TransformerFunc(func(name, contentType string, content []byte) ([]byte, error) {
+		seen[name] = contentType
+		return []byte(strings.ReplaceAll(string(content), "Test Body", "Stamped Body")), nil
+	})
+
+	if _, err := CreateNewDocx(dir, "Test Title", "Test Body", stamp); err != nil {
+		t.Fatalf("CreateNewDocx failed: %v", err)
+	}
+	if seen["word/document.xml"] != "application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml" {
+		t.Errorf("Expected the document part with its content type, got %v", seen)
+	}
+	if _, ok := seen["docProps/core.xml"]; !ok {
+		t.Errorf("Expected package parts to be transformed too, got %v", seen)
+	}
+
+	parts, err := utils.ReadDocx(dir + "/new_file.docx")
+	if err != nil {
+		t.Fatalf("Failed to read created DOCX: %v", err)
+	}
+	document := string(parts[utils.FindPart(parts, "word/document.xml")].Content)
+	if !strings.Contains(document, "Stamped Body") {
+		t.Errorf("Expected the transformer to run after the body was filled, got '%s'", document)
+	}
+
+	failing := utils.TransformerFunc(func(name, contentType string, content []byte) ([]byte, error) {
+		return nil, errors.New("rejected")
+	})
+	if _, err := CreateNewDocx(dir, "Test Title", "Test Body", failing); err == nil {
+		t.Errorf("Expected the transformer error to be returned")
+	}
+}
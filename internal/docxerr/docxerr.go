@@ -0,0 +1,135 @@
+// Package docxerr defines the errors reported by docxer. Failures caused by
+// a path, a package, the template markup or a missing key are one of the
+// sentinels below or wrap one, so callers can tell them apart with errors.Is
+// and errors.As. Invalid arguments, such as an unsupported custom XML
+// payload, locale, barcode or image, are reported with plain errors.
+package docxerr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidPath is returned when an output directory or file path cannot be used.
+	ErrInvalidPath = errors.New("invalid path")
+	// ErrTemplateNotFound is returned when the DOCX file to open does not exist.
+	ErrTemplateNotFound = errors.New("template not found")
+	// ErrInvalidPackage is matched by every *InvalidPackageError.
+	ErrInvalidPackage = errors.New("invalid DOCX package")
+	// ErrTemplateSyntax is matched by every *TemplateSyntaxError.
+	ErrTemplateSyntax = errors.New("template syntax error")
+	// ErrMissingKey is matched by every *MissingKeyError. A Resolver may also
+	// return it to report that it has no value for a key.
+	ErrMissingKey = errors.New("missing key")
+)
+
+// TemplateSyntaxError reports malformed template markup, such as an
+// {{#each}} without its {{/each}}. Line and Column are 1-based positions in
+// the XML of the part.
+type TemplateSyntaxError struct {
+	Part   string
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *TemplateSyntaxError) Error() string {
+	location := e.Part
+	if location == "" {
+		location = "template"
+	}
+	if e.Line > 0 {
+		location += fmt.Sprintf(":%d:%d", e.Line, e.Column)
+	}
+	return location + ": " + e.Msg
+}
+
+func (e *TemplateSyntaxError) Is(target error) bool {
+	return target == ErrTemplateSyntax
+}
+
+// NewSyntaxError returns a TemplateSyntaxError for the markup at offset in content.
+func NewSyntaxError(content string, offset int, msg string) *TemplateSyntaxError {
+	line, column := Position(content, offset)
+	return &TemplateSyntaxError{Line: line, Column: column, Msg: msg}
+}
+
+// Position returns the 1-based line and column of offset in content.
+func Position(content string, offset int) (int, int) {
+	line := strings.Count(content[:offset], "\n") + 1
+	column := offset - strings.LastIndexByte(content[:offset], '\n')
+	return line, column
+}
+
+// MissingKeyError reports a placeholder for which no value was found.
+type MissingKeyError struct {
+	Key  string
+	Part string
+	Err  error
+}
+
+func (e *MissingKeyError) Error() string {
+	msg := fmt.Sprintf("missing value for placeholder %q", e.Key)
+	if e.Part != "" {
+		msg += " in " + e.Part
+	}
+	if e.Err != nil && e.Err != ErrMissingKey {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *MissingKeyError) Is(target error) bool {
+	return target == ErrMissingKey
+}
+
+func (e *MissingKeyError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidPackageError reports a file that is not a usable DOCX package: not a
+// ZIP archive, or missing or unreadable parts. Part is empty when the archive
+// itself is broken and Path when it was not read from a file.
+type InvalidPackageError struct {
+	Path string
+	Part string
+	Err  error
+}
+
+func (e *InvalidPackageError) Error() string {
+	msg := "invalid DOCX package"
+	if e.Path != "" {
+		msg += " " + e.Path
+	}
+	if e.Part != "" {
+		msg += ": part " + e.Part
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *InvalidPackageError) Is(target error) bool {
+	return target == ErrInvalidPackage
+}
+
+func (e *InvalidPackageError) Unwrap() error {
+	return e.Err
+}
+
+// InPart records the part an error happened in on the typed errors that
+// carry one, when it is not known yet.
+func InPart(err error, part string) error {
+	var syntaxErr *TemplateSyntaxError
+	if errors.As(err, &syntaxErr) && syntaxErr.Part == "" {
+		syntaxErr.Part = part
+	}
+	var missingErr *MissingKeyError
+	if errors.As(err, &missingErr) && missingErr.Part == "" {
+		missingErr.Part = part
+	}
+	return err
+}
@@ -0,0 +1,55 @@
+package docxerr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestTypedErrorsMatchSentinels(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		sentinel error
+		message  string
+	}{
+		{"syntax", &TemplateSyntaxError{Part: "word/document.xml", Line: 3, Column: 7, Msg: "bad loop"}, ErrTemplateSyntax, "word/document.xml:3:7: bad loop"},
+		{"missing key", &MissingKeyError{Key: "NAME", Part: "word/header1.xml", Err: ErrMissingKey}, ErrMissingKey, `missing value for placeholder "NAME" in word/header1.xml`},
+		{"invalid package", &InvalidPackageError{Path: "a.docx", Err: io.ErrUnexpectedEOF}, ErrInvalidPackage, "invalid DOCX package a.docx: unexpected EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := fmt.Errorf("rendering: %w", tt.err)
+			if !errors.Is(wrapped, tt.sentinel) {
+				t.Errorf("Expected errors.Is to match the sentinel for %v", wrapped)
+			}
+			if tt.err.Error() != tt.message {
+				t.Errorf("Expected '%s', got '%s'", tt.message, tt.err.Error())
+			}
+		})
+	}
+
+	wrapped := fmt.Errorf("rendering: %w", &InvalidPackageError{Path: "a.docx", Err: io.ErrUnexpectedEOF})
+	var packageErr *InvalidPackageError
+	if !errors.As(wrapped, &packageErr) || packageErr.Path != "a.docx" || !errors.Is(wrapped, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected errors.As and the cause to be reachable, got %v", wrapped)
+	}
+}
+
+func TestNewSyntaxErrorAndInPart(t *testing.T) {
+	err := NewSyntaxError("<a>\n<b>{{#each}}", 7, "unbalanced loop")
+	if err.Line != 2 || err.Column != 4 {
+		t.Errorf("Expected line 2 column 4, got line %d column %d", err.Line, err.Column)
+	}
+
+	wrapped := InPart(fmt.Errorf("outer: %w", err), "word/footer1.xml")
+	var syntaxErr *TemplateSyntaxError
+	if !errors.As(wrapped, &syntaxErr) || syntaxErr.Part != "word/footer1.xml" {
+		t.Errorf("Expected the part to be recorded, got %v", wrapped)
+	}
+	if InPart(err, "word/document.xml"); err.Part != "word/footer1.xml" {
+		t.Errorf("Expected a known part to be kept, got '%s'", err.Part)
+	}
+}
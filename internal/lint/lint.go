@@ -0,0 +1,392 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aliamerj/docxer/internal/docxerr"
+	"github.com/aliamerj/docxer/internal/placeholder"
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+var tagPattern = regexp.MustCompile(`\{\{([^{}<>]*)\}\}`)
+
+// kinds are the prefixes of typed placeholders such as {{link:portal}}.
+var kinds = map[string]bool{"link": true, "table": true, "qr": true, "barcode": true}
+
+// symbologies are the kinds of {{barcode:kind:key}} placeholders.
+var symbologies = map[string]bool{"code128": true, "ean13": true}
+
+// Issue is a problem found in a template. Line and Column are 1-based
+// positions in the XML of the part.
+type Issue struct {
+	Part        string
+	Line        int
+	Column      int
+	Placeholder string
+	Message     string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s", i.Part, i.Line, i.Column, i.Message)
+}
+
+// Options turns on the optional checks of Lint. With Data, every placeholder
+// must have a value in the sample; with Schema, a JSON Schema describing the
+// data, every placeholder must be defined by it and loops must be arrays.
+type Options struct {
+	Data   map[string]any
+	Schema []byte
+}
+
+// reference is a placeholder or loop found in a template.
+type reference struct {
+	location Issue
+	kind     string
+	key      string
+	loop     string
+}
+
+type linter struct {
+	issues     []Issue
+	references []reference
+	loops      map[string]Issue
+}
+
+// Lint checks the template at filePath for mistakes that make placeholders
+// render wrongly or not at all. It returns an error only when the template
+// cannot be read or the schema is not valid JSON.
+func Lint(filePath string, options Options) ([]Issue, error) {
+	parts, err := utils.ReadDocx(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &linter{loops: map[string]Issue{}}
+	for _, part := range parts {
+		if utils.IsStoryPart(part.Name) {
+			l.lintPart(part.Name, string(part.Content))
+		}
+	}
+
+	if options.Data != nil {
+		l.checkData(options.Data)
+	}
+	if options.Schema != nil {
+		var root schema
+		if err := json.Unmarshal(options.Schema, &root); err != nil {
+			return nil, fmt.Errorf("invalid JSON Schema: %w", err)
+		}
+		l.checkSchema(&root)
+	}
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		a, b := l.issues[i], l.issues[j]
+		if a.Part != b.Part {
+			return a.Part < b.Part
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return l.issues, nil
+}
+
+// Reference is a placeholder, loop or condition used by a template. Kind is
+// "each" or "if" for blocks, the type of typed placeholders such as "link",
+// or "" for plain placeholders. Loop names the loop the reference is in.
+type Reference struct {
+	Kind string
+	Key  string
+	Loop string
+}
+
+// References returns the placeholders, loops and conditions of the template
+// at filePath, in the order of its parts. Malformed tags are left out.
+func References(filePath string) ([]Reference, error) {
+	parts, err := utils.ReadDocx(filePath)
+	if err != nil {
+		return nil, err
+	}
+	l := &linter{loops: map[string]Issue{}}
+	for _, part := range parts {
+		if utils.IsStoryPart(part.Name) {
+			l.lintPart(part.Name, string(part.Content))
+		}
+	}
+	references := make([]Reference, 0, len(l.references))
+	for _, ref := range l.references {
+		references = append(references, Reference{Kind: ref.kind, Key: ref.key, Loop: ref.loop})
+	}
+	return references, nil
+}
+
+func (l *linter) report(location Issue, format string, args ...any) {
+	location.Message = fmt.Sprintf(format, args...)
+	l.issues = append(l.issues, location)
+}
+
+func (l *linter) lintPart(part, content string) {
+	fields := utils.FindFields(content)
+	// open holds the {{#each}} and {{#if}} blocks around the current tag.
+	var open []reference
+	loop := func() string {
+		for _, block := range open {
+			if block.kind == "each" {
+				return block.key
+			}
+		}
+		return ""
+	}
+
+	for _, match := range tagPattern.FindAllStringSubmatchIndex(content, -1) {
+		line, column := docxerr.Position(content, match[0])
+		location := Issue{Part: part, Line: line, Column: column, Placeholder: content[match[0]:match[1]]}
+		inner := strings.TrimSpace(content[match[2]:match[3]])
+		l.checkLocation(content, match[0], fields, location)
+
+		switch {
+		case strings.HasPrefix(inner, "#each") || strings.HasPrefix(inner, "#if"):
+			kind, name, _ := strings.Cut(inner[1:], " ")
+			name = strings.TrimSpace(name)
+			if kind != "each" && kind != "if" {
+				l.report(location, "unknown block %s", location.Placeholder)
+				continue
+			}
+			if name == "" {
+				l.report(location, "{{#%s}} needs a key", kind)
+				continue
+			}
+			block := reference{location: location, kind: kind, key: name, loop: loop()}
+			if kind == "each" {
+				if block.loop != "" {
+					l.report(location, "{{#each %s}} is inside the loop %q; loops cannot be nested", name, block.loop)
+					continue
+				}
+				if previous, ok := l.loops[name]; ok {
+					l.report(location, "the loop %q is already used at %s:%d:%d", name, previous.Part, previous.Line, previous.Column)
+				} else {
+					l.loops[name] = location
+				}
+			}
+			open = append(open, block)
+			l.references = append(l.references, block)
+		case inner == "/each" || inner == "/if":
+			kind := inner[1:]
+			if len(open) == 0 || open[len(open)-1].kind != kind {
+				l.report(location, "{{/%s}} has no matching {{#%s}}", kind, kind)
+				continue
+			}
+			open = open[:len(open)-1]
+		case strings.HasPrefix(inner, "#") || strings.HasPrefix(inner, "/"):
+			l.report(location, "unknown block %s", location.Placeholder)
+		default:
+			l.lintPlaceholder(location, inner, loop())
+		}
+	}
+	for _, block := range open {
+		l.report(block.location, "{{#%s %s}} has no matching {{/%s}}", block.kind, block.key, block.kind)
+	}
+
+	l.checkSplit(part, content)
+}
+
+func (l *linter) lintPlaceholder(location Issue, inner, loop string) {
+	kind := ""
+	// Only the key can have a type; filters take arguments after a colon.
+	head, _, _ := strings.Cut(inner, "|")
+	if i := strings.IndexByte(head, ':'); i != -1 {
+		kind, inner = strings.TrimSpace(inner[:i]), strings.TrimSpace(inner[i+1:])
+		if !kinds[kind] {
+			l.report(location, "unknown placeholder type %q", kind)
+			return
+		}
+		if kind == "barcode" {
+			symbology, key, _ := strings.Cut(inner, ":")
+			if !symbologies[strings.TrimSpace(symbology)] {
+				l.report(location, "unknown barcode type %q", strings.TrimSpace(symbology))
+				return
+			}
+			inner = strings.TrimSpace(key)
+		}
+	}
+	key, filters := placeholder.ParsePlaceholder(inner)
+	if key == "" {
+		l.report(location, "placeholder without a name")
+		return
+	}
+	for _, name := range filters {
+		if kind != "" || !placeholder.IsFilter(name) {
+			l.report(location, "unknown filter %q", name)
+		}
+	}
+	l.references = append(l.references, reference{location: location, kind: kind, key: key, loop: loop})
+}
+
+// checkLocation reports placeholders inside text boxes and fields, where the
+// renderers do not look for them.
+func (l *linter) checkLocation(content string, offset int, fields []utils.Field, location Issue) {
+	if _, ok := utils.EnclosingElement(content, "w:txbxContent", offset); ok {
+		l.report(location, "%s is inside a text box and will not be filled", location.Placeholder)
+		return
+	}
+	for _, field := range fields {
+		if offset >= field.Start && offset < field.End {
+			l.report(location, "%s is inside the %s field and will be lost when fields are updated", location.Placeholder, field.Name())
+			return
+		}
+	}
+}
+
+// checkSplit reports placeholders that Word has split over several runs, for
+// example because part of the text was retyped or spell-checked.
+func (l *linter) checkSplit(part, content string) {
+	for _, paragraph := range utils.FindElements(content, "w:p") {
+		markup := paragraph.Outer(content)
+		text := utils.ExtractText(markup)
+		line, column := docxerr.Position(content, paragraph.Start)
+		location := Issue{Part: part, Line: line, Column: column}
+
+		counts := map[string]int{}
+		for _, tag := range tagPattern.FindAllString(text, -1) {
+			counts[tag]++
+		}
+		for tag, count := range counts {
+			escaped := utils.EscapeXML(tag)
+			intact := strings.Count(markup, escaped)
+			if escaped != tag {
+				intact += strings.Count(markup, tag)
+			}
+			if intact < count {
+				location.Placeholder = tag
+				l.report(location, "%s is split across several runs; retype it without pausing or changing its formatting", tag)
+			}
+		}
+
+		rest := tagPattern.ReplaceAllString(text, "")
+		if strings.Contains(rest, "{{") || strings.Contains(rest, "}}") {
+			location.Placeholder = ""
+			l.report(location, "unbalanced braces in %q", strings.TrimSpace(text))
+		}
+	}
+}
+
+func (l *linter) checkData(data map[string]any) {
+	reported := map[string]bool{}
+	for _, ref := range l.references {
+		if reported[ref.loop+"."+ref.key] {
+			continue
+		}
+		switch {
+		case ref.kind == "each":
+			value, ok := data[ref.key]
+			if !ok {
+				l.report(ref.location, "the data sample has no list %q", ref.key)
+			} else if !isList(value) {
+				l.report(ref.location, "%q in the data sample is %T, not a list of objects", ref.key, value)
+			}
+		case ref.loop != "":
+			if _, ok := data[ref.key]; ok || listHasKey(data[ref.loop], ref.key) {
+				continue
+			}
+			l.report(ref.location, "no item of %q in the data sample has %q", ref.loop, ref.key)
+		default:
+			if _, ok := data[ref.key]; !ok {
+				l.report(ref.location, "the data sample has no value for %q", ref.key)
+			}
+		}
+		reported[ref.loop+"."+ref.key] = true
+	}
+}
+
+func isList(value any) bool {
+	list := reflect.ValueOf(value)
+	if list.Kind() != reflect.Slice {
+		return false
+	}
+	element := list.Type().Elem()
+	return element.Kind() == reflect.Map || element.Kind() == reflect.Interface
+}
+
+func listHasKey(value any, key string) bool {
+	list := reflect.ValueOf(value)
+	if list.Kind() != reflect.Slice {
+		return false
+	}
+	for i := 0; i < list.Len(); i++ {
+		item := reflect.ValueOf(list.Index(i).Interface())
+		if item.Kind() == reflect.Map && item.Type().Key().Kind() == reflect.String {
+			if item.MapIndex(reflect.ValueOf(key).Convert(item.Type().Key())).IsValid() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// schema is the part of JSON Schema that Lint understands.
+type schema struct {
+	Type       any                `json:"type"`
+	Properties map[string]*schema `json:"properties"`
+	Items      *schema            `json:"items"`
+}
+
+func (s *schema) property(key string) *schema {
+	if s == nil {
+		return nil
+	}
+	return s.Properties[key]
+}
+
+func (s *schema) is(typeName string) bool {
+	switch t := s.Type.(type) {
+	case string:
+		return t == typeName
+	case []any:
+		for _, name := range t {
+			if name == typeName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (l *linter) checkSchema(root *schema) {
+	reported := map[string]bool{}
+	for _, ref := range l.references {
+		if reported[ref.loop+"."+ref.key] {
+			continue
+		}
+		reported[ref.loop+"."+ref.key] = true
+		switch {
+		case ref.kind == "each":
+			property := root.property(ref.key)
+			if property == nil {
+				l.report(ref.location, "the schema does not define %q", ref.key)
+			} else if !property.is("array") {
+				l.report(ref.location, "the schema does not define %q as an array", ref.key)
+			}
+		case ref.loop != "":
+			if root.property(ref.key) == nil && root.property(ref.loop).itemProperty(ref.key) == nil {
+				l.report(ref.location, "the schema does not define %q for items of %q", ref.key, ref.loop)
+			}
+		default:
+			if root.property(ref.key) == nil {
+				l.report(ref.location, "the schema does not define %q", ref.key)
+			}
+		}
+	}
+}
+
+func (s *schema) itemProperty(key string) *schema {
+	if s == nil {
+		return nil
+	}
+	return s.Items.property(key)
+}
@@ -0,0 +1,153 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+func createTemplate(t *testing.T, parts map[string]string) string {
+	filePath := t.TempDir() + "/template.docx"
+	var docxParts []utils.DocxPart
+	for name, content := range parts {
+		docxParts = append(docxParts, utils.DocxPart{Name: name, Content: []byte(content)})
+	}
+	if err := utils.WriteDocx(filePath, docxParts); err != nil {
+		t.Fatalf("Failed to create template: %v", err)
+	}
+	return filePath
+}
+
+func messages(issues []Issue) string {
+	var lines []string
+	for _, issue := range issues {
+		lines = append(lines, issue.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestLint_TemplateProblems(t *testing.T) {
+	document := `<w:document><w:body>` +
+		`<w:p><w:r><w:t>{{#each items}}{{NAME | shout}}{{/each}}</w:t></w:r></w:p>` +
+		"\n" + `<w:p><w:r><w:t>{{#each items}}</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>{{TO</w:t></w:r><w:r><w:t>TAL}}</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:pict><w:txbxContent><w:p><w:r><w:t>{{BOXED}}</w:t></w:r></w:p></w:txbxContent></w:pict></w:r></w:p>` +
+		`<w:p><w:fldSimple w:instr=" AUTHOR "><w:r><w:t>{{AUTHOR}}</w:t></w:r></w:fldSimple></w:p>` +
+		`<w:p><w:r><w:t>{{img:logo}} {{barcode:upc:SKU}} {{/each}}{{/each}}</w:t></w:r></w:p>` +
+		`</w:body></w:document>`
+	filePath := createTemplate(t, map[string]string{
+		"word/document.xml": document,
+		"word/styles.xml":   `<w:styles>{{IGNORED</w:styles>`,
+	})
+
+	issues, err := Lint(filePath, Options{})
+	if err != nil {
+		t.Fatalf("Lint returned an error: %v", err)
+	}
+
+	report := messages(issues)
+	expected := []string{
+		`unknown filter "shout"`,
+		`the loop "items" is already used at word/document.xml:1:`,
+		`{{TOTAL}} is split across several runs`,
+		`{{BOXED}} is inside a text box`,
+		`{{AUTHOR}} is inside the AUTHOR field`,
+		`unknown placeholder type "img"`,
+		`unknown barcode type "upc"`,
+		"{{/each}} has no matching {{#each}}",
+	}
+	for _, message := range expected {
+		if !strings.Contains(report, message) {
+			t.Errorf("Expected '%s' in the report, got:\n%s", message, report)
+		}
+	}
+	if strings.Contains(report, "styles.xml") {
+		t.Errorf("Expected only story parts to be linted, got:\n%s", report)
+	}
+	if len(issues) != 8 {
+		t.Errorf("Expected 8 issues, got %d:\n%s", len(issues), report)
+
+	}
+}
+
+func TestLint_SplitBesideIntact(t *testing.T) {
+	filePath := createTemplate(t, map[string]string{
+		"word/document.xml": `<w:p><w:r><w:t>{{NAME}} and {{NA</w:t></w:r><w:r><w:t>ME}}</w:t></w:r></w:p>`,
+	})
+
+	issues, err := Lint(filePath, Options{})
+	if err != nil {
+		t.Fatalf("Lint returned an error: %v", err)
+	}
+	if report := messages(issues); len(issues) != 1 || !strings.Contains(report, "{{NAME}} is split across several runs") {
+		t.Errorf("Expected the split {{NAME}} to be reported, got:\n%s", report)
+	}
+}
+
+func TestLint_CleanTemplate(t *testing.T) {
+	filePath := createTemplate(t, map[string]string{
+		"word/document.xml": `<w:p><w:r><w:t>{{NAME | upper}} {{link:portal}}</w:t></w:r></w:p><w:p><w:r><w:t>{{#each items}}{{PRICE | currency:EUR}}{{/each}}</w:t></w:r></w:p>`,
+		"word/header1.xml":  `<w:p><w:r><w:t>{{table:results}}</w:t></w:r></w:p><w:p><w:r><w:t>{{qr:URL}} {{barcode:ean13:EAN}}</w:t></w:r></w:p>`,
+	})
+
+	issues, err := Lint(filePath, Options{})
+	if err != nil {
+		t.Fatalf("Lint returned an error: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("Expected no issues, got:\n%s", messages(issues))
+	}
+}
+
+func TestLint_DataAndSchema(t *testing.T) {
+	filePath := createTemplate(t, map[string]string{
+		"word/document.xml": `<w:p><w:r><w:t>{{NAME}} {{MISSING}}</w:t></w:r></w:p><w:p><w:r><w:t>{{#each items}}{{PRICE}} {{SKU}} {{NAME}}{{/each}}</w:t></w:r></w:p><w:p><w:r><w:t>{{#each other}}{{/each}}</w:t></w:r></w:p>`,
+	})
+
+	issues, err := Lint(filePath, Options{Data: map[string]any{
+		"NAME":  "Ada",
+		"items": []map[string]any{{"PRICE": 1}},
+		"other": "not a list",
+	}})
+	if err != nil {
+		t.Fatalf("Lint returned an error: %v", err)
+	}
+	report := messages(issues)
+	for _, message := range []string{
+		`the data sample has no value for "MISSING"`,
+		`no item of "items" in the data sample has "SKU"`,
+		`"other" in the data sample is string, not a list of objects`,
+	} {
+		if !strings.Contains(report, message) {
+			t.Errorf("Expected '%s' in the report, got:\n%s", message, report)
+		}
+	}
+	if len(issues) != 3 {
+		t.Errorf("Expected 3 data issues, got:\n%s", report)
+	}
+
+	schema := `{"type": "object", "properties": {
+		"NAME": {"type": "string"},
+		"items": {"type": "array", "items": {"properties": {"PRICE": {"type": "number"}}}},
+		"other": {"type": ["string", "null"]}
+	}}`
+	issues, err = Lint(filePath, Options{Schema: []byte(schema)})
+	if err != nil {
+		t.Fatalf("Lint returned an error: %v", err)
+	}
+	report = messages(issues)
+	for _, message := range []string{
+		`the schema does not define "MISSING"`,
+		`the schema does not define "SKU" for items of "items"`,
+		`the schema does not define "other" as an array`,
+	} {
+		if !strings.Contains(report, message) {
+			t.Errorf("Expected '%s' in the report, got:\n%s", message, report)
+		}
+	}
+
+	if _, err := Lint(filePath, Options{Schema: []byte("{")}); err == nil {
+		t.Errorf("Expected an error for an invalid schema")
+	}
+}
@@ -0,0 +1,200 @@
+package locale
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// names are the words and date styles of a language.
+type names struct {
+	months      [12]string
+	shortMonths [12]string
+	hijriMonths [12]string
+	// days start on Sunday, like time.Weekday.
+	days      [7]string
+	shortDays [7]string
+	am, pm    string
+	hijriEra  string
+	styles    map[string]string
+}
+
+var hijriMonthsLatin = [12]string{
+	"Muharram", "Safar", "Rabiʻ I", "Rabiʻ II", "Jumada I", "Jumada II",
+	"Rajab", "Shaʻban", "Ramadan", "Shawwal", "Dhuʻl-Qiʻdah", "Dhuʻl-Hijjah",
+}
+
+var calendarNames = map[string]*names{
+	"en": {
+		months: [12]string{
+			"January", "February", "March", "April", "May", "June",
+			"July", "August", "September", "October", "November", "December",
+		},
+		shortMonths: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		hijriMonths: hijriMonthsLatin,
+		days:        [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		shortDays:   [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		am:          "AM",
+		pm:          "PM",
+		hijriEra:    "AH",
+		styles: map[string]string{
+			"short":  "M/d/yy",
+			"medium": "MMM d, yyyy",
+			"long":   "MMMM d, yyyy",
+			"full":   "dddd, MMMM d, yyyy",
+		},
+	},
+	"de": {
+		months: [12]string{
+			"Januar", "Februar", "März", "April", "Mai", "Juni",
+			"Juli", "August", "September", "Oktober", "November", "Dezember",
+		},
+		shortMonths: [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		hijriMonths: hijriMonthsLatin,
+		days:        [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		shortDays:   [7]string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+		am:          "AM",
+		pm:          "PM",
+		hijriEra:    "AH",
+		styles: map[string]string{
+			"short":  "dd.MM.yy",
+			"medium": "dd.MM.yyyy",
+			"long":   "d. MMMM yyyy",
+			"full":   "dddd, d. MMMM yyyy",
+		},
+	},
+	"ar": {
+		months: [12]string{
+			"يناير", "فبراير", "مارس", "أبريل", "مايو", "يونيو",
+			"يوليو", "أغسطس", "سبتمبر", "أكتوبر", "نوفمبر", "ديسمبر",
+		},
+		shortMonths: [12]string{
+			"يناير", "فبراير", "مارس", "أبريل", "مايو", "يونيو",
+			"يوليو", "أغسطس", "سبتمبر", "أكتوبر", "نوفمبر", "ديسمبر",
+		},
+		hijriMonths: [12]string{
+			"محرم", "صفر", "ربيع الأول", "ربيع الآخر", "جمادى الأولى", "جمادى الآخرة",
+			"رجب", "شعبان", "رمضان", "شوال", "ذو القعدة", "ذو الحجة",
+		},
+		days:      [7]string{"الأحد", "الاثنين", "الثلاثاء", "الأربعاء", "الخميس", "الجمعة", "السبت"},
+		shortDays: [7]string{"الأحد", "الاثنين", "الثلاثاء", "الأربعاء", "الخميس", "الجمعة", "السبت"},
+		am:        "ص",
+		pm:        "م",
+		hijriEra:  "هـ",
+		styles: map[string]string{
+			"short":  "d/M/yyyy",
+			"medium": "dd/MM/yyyy",
+			"long":   "d MMMM yyyy",
+			"full":   "dddd، d MMMM yyyy",
+		},
+	},
+}
+
+// date is a day of the calendar of a locale.
+type date struct {
+	year, month, day int
+}
+
+// Hijri returns the year, month and day of t in the tabular Islamic calendar.
+// It can differ by a day from the Umm al-Qura calendar used in Saudi Arabia,
+// which follows moon sightings.
+func Hijri(t time.Time) (int, int, int) {
+	// Julian day number of the civil date of t.
+	y, m, d := t.Date()
+	jd := int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix()/86400) + 2440588
+	l := jd - 1948440 + 10632
+	n := (l - 1) / 10631
+	l = l - 10631*n + 354
+	j := ((10985-l)/5316)*((50*l)/17719) + (l/5670)*((43*l)/15238)
+	l = l - ((30-j)/15)*((17719*j)/50) - (j/16)*((15238*j)/43) + 29
+	month := (24 * l) / 709
+	day := l - (709*month)/24
+	year := 30*n + j - 30
+	return year, month, day
+}
+
+// Date formats t in a style of the locale, "short", "medium", "long" or
+// "full", or with a Word date picture such as "d MMMM yyyy". Locales using
+// the Islamic calendar write Hijri dates, with the era in all but the short
+// style.
+func (l *Locale) Date(t time.Time, layout string) string {
+	if pattern, ok := l.names.styles[layout]; ok {
+		layout = pattern
+		if l.hijri && layout != l.names.styles["short"] {
+			layout += " '" + l.names.hijriEra + "'"
+		}
+	}
+
+	day := date{t.Year(), int(t.Month()), t.Day()}
+	months := l.names.months
+	shortMonths := l.names.shortMonths
+	if l.hijri {
+		day.year, day.month, day.day = Hijri(t)
+		months, shortMonths = l.names.hijriMonths, l.names.hijriMonths
+	}
+	number := func(value, width int) string {
+		text := strconv.Itoa(value)
+		if len(text) < width {
+			text = strings.Repeat("0", width-len(text)) + text
+		}
+		return l.Digits(text)
+	}
+	hour12 := t.Hour() % 12
+	if hour12 == 0 {
+		hour12 = 12
+	}
+	ampm := l.names.am
+	if t.Hour() >= 12 {
+		ampm = l.names.pm
+	}
+
+	parts := []struct {
+		picture string
+		value   func() string
+	}{
+		{"yyyy", func() string { return number(day.year, 4) }},
+		{"yy", func() string { return number(day.year%100, 2) }},
+		{"MMMM", func() string { return months[day.month-1] }},
+		{"MMM", func() string { return shortMonths[day.month-1] }},
+		{"MM", func() string { return number(day.month, 2) }},
+		{"M", func() string { return number(day.month, 1) }},
+		{"dddd", func() string { return l.names.days[t.Weekday()] }},
+		{"ddd", func() string { return l.names.shortDays[t.Weekday()] }},
+		{"dd", func() string { return number(day.day, 2) }},
+		{"d", func() string { return number(day.day, 1) }},
+		{"HH", func() string { return number(t.Hour(), 2) }},
+		{"H", func() string { return number(t.Hour(), 1) }},
+		{"hh", func() string { return number(hour12, 2) }},
+		{"h", func() string { return number(hour12, 1) }},
+		{"mm", func() string { return number(t.Minute(), 2) }},
+		{"m", func() string { return number(t.Minute(), 1) }},
+		{"ss", func() string { return number(t.Second(), 2) }},
+		{"s", func() string { return number(t.Second(), 1) }},
+		{"AM/PM", func() string { return ampm }},
+		{"am/pm", func() string { return strings.ToLower(ampm) }},
+	}
+
+	var result strings.Builder
+	for rest := layout; rest != ""; {
+		if rest[0] == '\'' {
+			literal, after, _ := strings.Cut(rest[1:], "'")
+			result.WriteString(literal)
+			rest = after
+			continue
+		}
+		matched := false
+		for _, part := range parts {
+			if strings.HasPrefix(rest, part.picture) {
+				result.WriteString(part.value())
+				rest = rest[len(part.picture):]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			result.WriteByte(rest[0])
+			rest = rest[1:]
+		}
+	}
+	return result.String()
+}
@@ -0,0 +1,54 @@
+package locale
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHijri(t *testing.T) {
+	tests := []struct {
+		date             time.Time
+		year, month, day int
+	}{
+		{time.Date(622, time.July, 19, 0, 0, 0, 0, time.UTC), 1, 1, 1},
+		{time.Date(2024, time.March, 11, 0, 0, 0, 0, time.UTC), 1445, 9, 1},
+		{time.Date(2023, time.July, 19, 23, 59, 0, 0, time.UTC), 1445, 1, 1},
+	}
+	for _, test := range tests {
+		year, month, day := Hijri(test.date)
+		if year != test.year || month != test.month || day != test.day {
+			t.Errorf("Hijri(%s) = %d-%d-%d, want %d-%d-%d", test.date.Format("2006-01-02"), year, month, day, test.year, test.month, test.day)
+		}
+	}
+}
+
+func TestDate(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 14, 7, 9, 0, time.UTC)
+	tests := []struct {
+		locale   string
+		layout   string
+		expected string
+	}{
+		{"en-US", "short", "3/5/24"},
+		{"en-US", "medium", "Mar 5, 2024"},
+		{"en-US", "full", "Tuesday, March 5, 2024"},
+		{"de-DE", "medium", "05.03.2024"},
+		{"de-DE", "long", "5. März 2024"},
+		{"de-DE", "dddd, d. MMM", "Dienstag, 5. März"},
+		{"ar-SA", "long", "٥ مارس ٢٠٢٤"},
+		{"ar-SA-u-nu-latn", "dd/MM/yyyy", "05/03/2024"},
+		{"ar-SA-u-ca-islamic", "long", "٢٤ شعبان ١٤٤٥ هـ"},
+		{"en-US-u-ca-islamic", "short", "8/24/45"},
+		{"en-US", "'Due' d MMMM yyyy h:mm:ss am/pm", "Due 5 March 2024 2:07:09 pm"},
+		{"ar-EG", "hh:mm AM/PM", "٠٢:٠٧ م"},
+	}
+	for _, test := range tests {
+		l, err := Parse(test.locale)
+		if err != nil {
+			t.Fatalf("Parse(%q) returned an error: %v", test.locale, err)
+		}
+		if got := l.Date(date, test.layout); got != test.expected {
+			t.Errorf("%s Date(%q) = %q, want %q", test.locale, test.layout, got, test.expected)
+		}
+	}
+}
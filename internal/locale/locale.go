@@ -0,0 +1,298 @@
+// Package locale formats numbers, amounts of money and dates the way a
+// language and region write them. Locales are BCP 47 tags such as "de-DE" or
+// "ar-SA"; the Unicode extensions "-u-nu-" and "-u-ca-" choose the digits
+// ("latn", "arab") and the calendar ("gregory", "islamic").
+package locale
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// Locale formats values for a language and region.
+type Locale struct {
+	tag      language.Tag
+	language string
+	// latin writes numbers with the CLDR symbols and grouping of the
+	// language, in ASCII digits.
+	latin    *message.Printer
+	decimal  string
+	group    string
+	arabic   bool
+	hijri    bool
+	currency currency.Unit
+	names    *names
+}
+
+// Default is the locale used when none is chosen: US English.
+var Default = mustParse("en-US")
+
+// maghreb are the regions writing Arabic with Latin digits.
+var maghreb = map[string]bool{"MA": true, "DZ": true, "TN": true, "LY": true, "EH": true}
+
+// currencyAfter are the languages writing the currency symbol after the amount.
+var currencyAfter = map[string]bool{
+	"ar": true, "cs": true, "da": true, "de": true, "es": true, "fi": true, "fr": true,
+	"it": true, "nb": true, "pl": true, "pt": true, "ru": true, "sv": true, "tr": true,
+}
+
+// Parse returns the locale named by a BCP 47 tag such as "ar-SA". Arabic uses
+// Arabic-Indic digits outside the Maghreb unless the tag asks for "-u-nu-latn",
+// and dates use the Gregorian calendar unless it asks for "-u-ca-islamic".
+// Languages whose month and day names are not known, those other than
+// English, German and Arabic, are an error.
+func Parse(name string) (*Locale, error) {
+	tag, err := language.Parse(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid locale %q: %w", name, err)
+	}
+	base, _ := tag.Base()
+	region, _ := tag.Region()
+
+	latin, _ := tag.SetTypeForKey("nu", "latn")
+	l := &Locale{tag: tag, language: base.String(), latin: message.NewPrinter(latin)}
+	l.group, l.decimal = separators(l.latin)
+	switch tag.TypeForKey("nu") {
+	case "arab":
+		l.arabic = true
+	case "":
+		l.arabic = l.language == "ar" && !maghreb[region.String()]
+	}
+	if l.arabic {
+		l.decimal, l.group = "٫", "٬"
+	}
+	l.hijri = strings.HasPrefix(tag.TypeForKey("ca"), "islamic")
+	l.currency, _ = currency.FromTag(tag)
+	if l.names = calendarNames[l.language]; l.names == nil {
+		return nil, fmt.Errorf("invalid locale %q: dates are only written in English, German and Arabic", name)
+	}
+	return l, nil
+}
+
+func mustParse(name string) *Locale {
+	l, err := Parse(name)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+// String returns the tag of the locale.
+func (l *Locale) String() string {
+	return l.tag.String()
+}
+
+// separators returns the grouping and decimal separators printer writes
+// numbers with.
+func separators(printer *message.Printer) (string, string) {
+	group := symbol(printer.Sprint(number.Decimal(1234567)))
+	decimal := symbol(printer.Sprint(number.Decimal(0.5)))
+	if decimal == "" {
+		decimal = "."
+	}
+	return group, decimal
+}
+
+// symbol returns the first run of text that is neither a digit nor an
+// invisible formatting character.
+func symbol(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, text)
+	start := strings.IndexFunc(text, func(r rune) bool { return !unicode.IsDigit(r) })
+	if start == -1 {
+		return ""
+	}
+	end := strings.IndexFunc(text[start:], unicode.IsDigit)
+	if end == -1 {
+		return text[start:]
+	}
+	return text[start : start+end]
+}
+
+// Digits writes the ASCII digits of text in the digits of the locale.
+func (l *Locale) Digits(text string) string {
+	if !l.arabic {
+		return text
+	}
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return '٠' + r - '0'
+		}
+		return r
+	}, text)
+}
+
+// Localize rewrites a number formatted by Go, such as "-1234.50", with the
+// decimal separator and digits of the locale.
+func (l *Locale) Localize(text string) string {
+	whole, fraction, found := strings.Cut(text, ".")
+	if found && l.decimal != "." {
+		text = whole + l.decimal + fraction
+	}
+	return l.minus(l.Digits(text))
+}
+
+func (l *Locale) minus(text string) string {
+	if l.arabic && strings.HasPrefix(text, "-") {
+		return "\u061c" + text
+	}
+	return text
+}
+
+// compose writes a number from its ASCII digits.
+func (l *Locale) compose(negative bool, whole, fraction string, grouped bool) string {
+	if grouped {
+		whole = l.groupDigits(whole)
+	}
+	text := whole
+	if fraction != "" {
+		text += l.decimal + fraction
+	}
+	text = l.Digits(text)
+	if negative {
+		text = l.minus("-" + text)
+	}
+	return text
+}
+
+// groupDigits groups the ASCII digits of a whole number the way the language
+// does, for example in thousands or, in India, in lakhs and crores. Numbers
+// too long for x/text, or padded with zeros, are grouped in thousands.
+func (l *Locale) groupDigits(digits string) string {
+	n, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil || (len(digits) > 1 && digits[0] == '0') {
+		return groupThousands(digits, l.group)
+	}
+	var result strings.Builder
+	separated := false
+	for _, r := range l.latin.Sprint(number.Decimal(n)) {
+		switch {
+		case r >= '0' && r <= '9':
+			result.WriteRune(r)
+			separated = false
+		case !separated && !unicode.Is(unicode.Cf, r):
+			result.WriteString(l.group)
+			separated = true
+		}
+	}
+	return result.String()
+}
+
+func groupThousands(digits, separator string) string {
+	var result strings.Builder
+	for i, r := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			result.WriteString(separator)
+		}
+		result.WriteRune(r)
+	}
+	return result.String()
+}
+
+// Number formats number with thousands grouped and the given number of
+// fraction digits, or as many as needed when decimals is negative.
+func (l *Locale) Number(number float64, decimals int) string {
+	text := strconv.FormatFloat(math.Abs(number), 'f', decimals, 64)
+	whole, fraction, _ := strings.Cut(text, ".")
+	return l.compose(number < 0 && strings.Trim(text, "0.") != "", whole, fraction, true)
+}
+
+// Picture formats number with a Word numeric picture such as "#,##0.00" or
+// "$0.0". Text around the digits is kept as is.
+func (l *Locale) Picture(number float64, picture string) string {
+	start := strings.IndexAny(picture, "0#")
+	if start == -1 {
+		return l.Number(number, -1)
+	}
+	end := start
+	for end < len(picture) && strings.IndexByte("0#,.", picture[end]) != -1 {
+		end++
+	}
+	integer, fractionPicture, _ := strings.Cut(picture[start:end], ".")
+
+	decimals := len(fractionPicture) - strings.Count(fractionPicture, ",")
+	text := strconv.FormatFloat(math.Abs(number), 'f', decimals, 64)
+	whole, fraction, _ := strings.Cut(text, ".")
+	if strings.Contains(fractionPicture, "#") {
+		fraction = strings.TrimRight(fraction, "0")
+		if minimum := strings.Count(fractionPicture, "0"); len(fraction) < minimum {
+			fraction += strings.Repeat("0", minimum-len(fraction))
+		}
+	}
+	if minimum := strings.Count(integer, "0"); len(whole) < minimum {
+		whole = strings.Repeat("0", minimum-len(whole)) + whole
+	} else if whole == "0" && minimum == 0 {
+		whole = ""
+	}
+
+	negative := number < 0 && strings.Trim(whole+fraction, "0") != ""
+	result := l.compose(negative, whole, fraction, strings.Contains(integer, ","))
+	return unquote(picture[:start]) + result + unquote(picture[end:])
+}
+
+func unquote(text string) string {
+	return strings.ReplaceAll(text, "'", "")
+}
+
+// Currency formats an amount of money in the currency with the ISO 4217 code,
+// or in the currency of the region of the locale when code is empty.
+func (l *Locale) Currency(amount float64, code string) (string, error) {
+	unit := l.currency
+	if code != "" {
+		var err error
+		if unit, err = currency.ParseISO(code); err != nil {
+			return "", fmt.Errorf("unknown currency %q", code)
+		}
+	}
+	symbol := message.NewPrinter(l.tag).Sprint(currency.Symbol(unit))
+	scale, _ := currency.Standard.Rounding(unit)
+	text := l.Number(math.Abs(amount), scale)
+
+	sign := ""
+	if amount < 0 && math.Round(-amount*math.Pow10(scale)) != 0 {
+		sign = l.minus("-")
+	}
+	if currencyAfter[l.language] {
+		return sign + text + "\u00a0" + symbol, nil
+	}
+	if last := []rune(symbol); len(last) > 0 && unicode.IsLetter(last[len(last)-1]) {
+		symbol += "\u00a0"
+	}
+	return sign + symbol + text, nil
+}
+
+// Format writes a value the way the locale writes it: numbers with its
+// decimal separator and digits, dates in its medium style and anything else
+// with fmt.
+func (l *Locale) Format(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return l.Date(v, "medium")
+	case string:
+		return v
+	}
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return l.Localize(fmt.Sprint(value))
+	case reflect.Float32, reflect.Float64:
+		return l.Localize(strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()))
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,135 @@
+package locale
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	if _, err := Parse("not a locale!"); err == nil {
+		t.Errorf("Expected an error for an invalid locale")
+	}
+	if _, err := Parse("fr-FR"); err == nil {
+		t.Errorf("Expected an error for a language without month and day names")
+	}
+	l, err := Parse("ar-SA")
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+	if l.String() != "ar-SA" {
+		t.Errorf("String() = %q, want ar-SA", l.String())
+	}
+}
+
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		locale   string
+		number   float64
+		decimals int
+		expected string
+	}{
+		{"en-US", 1234567.891, 2, "1,234,567.89"},
+		{"en-US", -0.001, 2, "0.00"},
+		{"de-DE", 1234567.891, 2, "1.234.567,89"},
+		{"de-DE", -1234.5, -1, "-1.234,5"},
+		{"ar-SA", 1234.5, 2, "١٬٢٣٤٫٥٠"},
+		{"ar-SA", -3, 0, "\u061c-٣"},
+		{"ar-SA-u-nu-latn", 1234.5, 2, "1,234.50"},
+		{"ar-MA", 1234.5, 2, "1.234,50"},
+		{"en-US-u-nu-arab", 12, 0, "١٢"},
+		{"en-IN", 1234567.891, 2, "12,34,567.89"},
+		{"en-IN", 999, 0, "999"},
+	}
+	for _, test := range tests {
+		l, err := Parse(test.locale)
+		if err != nil {
+			t.Fatalf("Parse(%q) returned an error: %v", test.locale, err)
+		}
+		if got := l.Number(test.number, test.decimals); got != test.expected {
+			t.Errorf("%s Number(%v, %d) = %q, want %q", test.locale, test.number, test.decimals, got, test.expected)
+		}
+	}
+}
+
+func TestPicture(t *testing.T) {
+	de, _ := Parse("de-DE")
+	tests := []struct {
+		locale   *Locale
+		number   float64
+		picture  string
+		expected string
+	}{
+		{Default, 1234567.891, "#,##0.00", "1,234,567.89"},
+		{Default, -3.14, "$0.0", "$-3.1"},
+		{Default, 2.5, "0.##", "2.5"},
+		{Default, 7, "000", "007"},
+		{Default, 0.5, "#.00", ".50"},
+		{Default, 12, "'Total: '0", "Total: 12"},
+		{de, 1234.5, "#,##0.00 €", "1.234,50 €"},
+		{Default, 12, "#,##0000", "0,012"},
+	}
+	for _, test := range tests {
+		if got := test.locale.Picture(test.number, test.picture); got != test.expected {
+			t.Errorf("%s Picture(%v, %q) = %q, want %q", test.locale, test.number, test.picture, got, test.expected)
+		}
+	}
+}
+
+func TestCurrency(t *testing.T) {
+	tests := []struct {
+		locale   string
+		amount   float64
+		code     string
+		expected string
+	}{
+		{"en-US", 1234.5, "", "$1,234.50"},
+		{"en-US", -1234.5, "EUR", "-€1,234.50"},
+		{"en-US", 12, "CHF", "CHF\u00a012.00"},
+		{"en-US", 1234.6, "JPY", "¥1,235"},
+		{"de-DE", 1234.5, "", "1.234,50\u00a0€"},
+		{"de-DE", 1234.5, "USD", "1.234,50\u00a0$"},
+		{"ar-SA", 1234.5, "", "١٬٢٣٤٫٥٠\u00a0ر.س.\u200f"},
+		{"en-IN", -1234567.89, "", "-₹12,34,567.89"},
+	}
+	for _, test := range tests {
+		l, err := Parse(test.locale)
+		if err != nil {
+			t.Fatalf("Parse(%q) returned an error: %v", test.locale, err)
+		}
+		got, err := l.Currency(test.amount, test.code)
+		if err != nil {
+			t.Fatalf("%s Currency returned an error: %v", test.locale, err)
+		}
+		if got != test.expected {
+			t.Errorf("%s Currency(%v, %q) = %q, want %q", test.locale, test.amount, test.code, got, test.expected)
+		}
+	}
+	if _, err := Default.Currency(1, "XYZW"); err == nil {
+		t.Errorf("Expected an error for an unknown currency")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	ar, _ := Parse("ar-SA")
+	de, _ := Parse("de-DE")
+	tests := []struct {
+		locale   *Locale
+		value    any
+		expected string
+	}{
+		{Default, 1234.5, "1234.5"},
+		{Default, float32(1.1), "1.1"},
+		{de, 1234.5, "1234,5"},
+		{de, "1234.5", "1234.5"},
+		{ar, 2024, "٢٠٢٤"},
+		{ar, -2.5, "\u061c-٢٫٥"},
+		{de, time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC), "05.03.2024"},
+		{de, nil, ""},
+		{de, true, "true"},
+	}
+	for _, test := range tests {
+		if got := test.locale.Format(test.value); got != test.expected {
+			t.Errorf("%s Format(%v) = %q, want %q", test.locale, test.value, got, test.expected)
+		}
+	}
+}
@@ -1,15 +1,15 @@
 package markdown
 
 import (
-	"archive/zip"
 	"bufio"
 	"embed"
 	"fmt"
-	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
+	"github.com/aliamerj/docxer/internal/numbering"
 	"github.com/aliamerj/docxer/internal/template"
 	"github.com/aliamerj/docxer/internal/utils"
 )
@@ -17,56 +17,176 @@ import (
 //go:embed template/*
 var documentXml embed.FS
 
-func CreateMarkdownDocx(path string, markdown string) (string, error) {
+func CreateMarkdownDocx(path string, markdown string, transformers ...utils.Transformer) (string, error) {
 	outputFilePath := filepath.Join(path, "docx_markdown.docx")
-	file, err := os.Create(outputFilePath)
+	pkg, err := template.NewPackage()
 	if err != nil {
 		return "", err
 	}
-	defer file.Close()
-	zipFile := zip.NewWriter(file)
-	defer zipFile.Close()
-
-	if err := template.CreateDocxTemplate(zipFile); err != nil {
+	if err := template.AddWordParts(pkg, documentXml); err != nil {
 		return "", err
 	}
-	docxer := docxWriter(markdown)
-
-	if err := utils.CreateDocx(documentXml, zipFile, docxer); err != nil {
+	converted := convert(markdown)
+	if converted.hasLists {
+		pkg.SetPart(numbering.PartName, numbering.ContentType, converted.numbering())
+		pkg.Relate(template.DocumentPath, numbering.RelType, numbering.PartName)
+	}
+	if err := pkg.Transform(utils.Chain(docxWriter(converted), utils.Chain(transformers...))); err != nil {
+		return "", err
+	}
+	if err := pkg.Save(outputFilePath); err != nil {
 		return "", err
 	}
-	return outputFilePath, err
+	return outputFilePath, nil
 }
 
-func docxWriter(markdownText string) utils.DocxWriter {
+func docxWriter(converted *converted) utils.DocxWriter {
 
-	return func(fileContent string) string {
-		return applyStyle(fileContent, markdownText)
+	return func(fileContent string) (string, error) {
+		return converted.fill(fileContent), nil
 	}
 }
 
 func applyStyle(fileContent, markdownText string) string {
-	content := fileContent
-	var titleSection, bodySections strings.Builder
+	return convert(markdownText).fill(fileContent)
+}
+
+// bulletListID is the list of every bulleted list item. Each numbered list
+// gets a list of its own, so that it counts from its first number.
+const bulletListID = 1
+
+// listItemPattern matches a list item: its indentation, its marker and its
+// text.
+var listItemPattern = regexp.MustCompile(`^(\s*)([-*+]|(\d+)[.)])\s+(.*)$`)
+
+// converted is Markdown converted to the content of the main document part.
+type converted struct {
+	title, body strings.Builder
+	hasLists    bool
+	// numbered are the first items of the numbered lists.
+	numbered []numbering.Level
+}
+
+func convert(markdownText string) *converted {
+	c := &converted{}
+	// indents are the indentations of the levels of the current list, and
+	// numberedID the list of its numbered items.
+	var indents []int
+	numberedID := 0
 	scanner := bufio.NewScanner(strings.NewReader(markdownText))
 	for scanner.Scan() {
+		if match := listItemPattern.FindStringSubmatch(scanner.Text()); match != nil {
+			indent := len(strings.ReplaceAll(match[1], "\t", "    "))
+			for len(indents) > 0 && indents[len(indents)-1] > indent {
+				indents = indents[:len(indents)-1]
+			}
+			if len(indents) == 0 || indents[len(indents)-1] < indent {
+				indents = append(indents, indent)
+			}
+			level := min(len(indents)-1, numbering.Levels-1)
+			listID := bulletListID
+			if match[3] != "" {
+				if numberedID == 0 {
+					start, _ := strconv.Atoi(match[3])
+					c.numbered = append(c.numbered, numbering.Level{Level: level, Start: start})
+					numberedID = bulletListID + len(c.numbered)
+				}
+				listID = numberedID
+			}
+			c.hasLists = true
+			c.body.WriteString(listItem(listID, level, escapeXMLChars(match[4])))
+			continue
+		}
+		indents, numberedID = nil, 0
+
 		line := escapeXMLChars(scanner.Text())
 		style, placeholder := determineStyle(line)
 		cleanText := strings.Replace(line, placeholder, "", -1)
 
 		if style == "Title" {
-			titleSection.Reset()
-			titleSection.WriteString(cleanText)
+			c.title.Reset()
+			c.title.WriteString(cleanText)
 		} else {
-			bodySections.WriteString(newSection(style, cleanText))
+			c.body.WriteString(newSection(style, cleanText))
 		}
 	}
+	return c
+}
+
+func (c *converted) fill(fileContent string) string {
+	content := strings.Replace(fileContent, "{{TITLE}}", c.title.String(), 1)
+	return strings.Replace(content, "{{SECTION}}", c.body.String(), 1)
+}
+
+// numbering returns the numbering part of the lists: a bulleted list and a
+// numbered list for each run of numbered items, sharing the definition of
+// their levels.
+func (c *converted) numbering() []byte {
+	abstractNums := numbering.AbstractNum(0, numbering.Bullets()) + numbering.AbstractNum(1, numbering.Decimal())
+	nums := numbering.Num(bulletListID, 0, nil)
+	for i, first := range c.numbered {
+		nums += numbering.Num(bulletListID+1+i, 1, []numbering.Level{first})
+	}
+	return numbering.Part(abstractNums, nums)
+}
+
+func listItem(listID, level int, text string) string {
+	return fmt.Sprintf(`<w:p><w:pPr><w:pStyle w:val="ListParagraph"/><w:numPr><w:ilvl w:val="%d"/><w:numId w:val="%d"/></w:numPr></w:pPr>%s</w:p>`,
+		level, listID, processTextFormatting(text))
+}
+
+// Paragraph is a line of Markdown converted to WordprocessingML: the style
+// named by its heading marker and its runs.
+type Paragraph struct {
+	Style   string
+	Content string
+}
+
+// LinkResolver returns the relationship id of a hyperlink to url.
+type LinkResolver func(url string) string
+
+var linkPattern = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
 
-	content = strings.Replace(content, "{{TITLE}}", titleSection.String(), 1)
-	content = strings.Replace(content, "{{SECTION}}", bodySections.String(), 1)
+// Convert turns Markdown text into paragraphs, one per non-blank line. Links
+// written as [text](url) become hyperlinks when links is not nil and plain
+// text otherwise.
+func Convert(markdownText string, links LinkResolver) []Paragraph {
+	var paragraphs []Paragraph
+	scanner := bufio.NewScanner(strings.NewReader(markdownText))
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		line := escapeXMLChars(strings.TrimSpace(scanner.Text()))
+		style, placeholder := determineStyle(line)
+		cleanText := strings.Replace(line, placeholder, "", 1)
+		paragraphs = append(paragraphs, Paragraph{Style: style, Content: processLinks(cleanText, links)})
+	}
+	return paragraphs
+}
 
-	return content
+func processLinks(text string, links LinkResolver) string {
+	var result strings.Builder
+	last := 0
+	for _, match := range linkPattern.FindAllStringSubmatchIndex(text, -1) {
+		if match[0] > last {
+			result.WriteString(processTextFormatting(text[last:match[0]]))
+		}
+		label, url := text[match[2]:match[3]], text[match[4]:match[5]]
+		if links == nil {
+			result.WriteString(processTextFormatting(label))
+		} else {
+			id := links(utils.UnescapeXML(url))
+			result.WriteString(`<w:hyperlink r:id="` + id + `" w:history="1"><w:r><w:rPr><w:rStyle w:val="Hyperlink"/></w:rPr><w:t xml:space="preserve">` + label + `</w:t></w:r></w:hyperlink>`)
+		}
+		last = match[1]
+	}
+	if last < len(text) {
+		result.WriteString(processTextFormatting(text[last:]))
+	}
+	return result.String()
 }
+
 func newSection(style, body string) string {
 	formattedBody := processTextFormatting(body)
 	if !strings.Contains(formattedBody, "<w:r>") && !strings.Contains(formattedBody, "<w:rPr>") {
@@ -130,10 +250,5 @@ func determineStyle(line string) (style string, placeholder string) {
 }
 func escapeXMLChars(text string) string {
 	// Replace special XML characters with their escape sequences
-	text = strings.ReplaceAll(text, "&", "&amp;")
-	text = strings.ReplaceAll(text, "<", "&lt;")
-	text = strings.ReplaceAll(text, ">", "&gt;")
-	text = strings.ReplaceAll(text, "\"", "&quot;")
-	text = strings.ReplaceAll(text, "'", "&apos;")
-	return text
+	return utils.EscapeXML(text)
 }
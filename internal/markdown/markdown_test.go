@@ -117,6 +117,33 @@ func TestApplyStyle(t *testing.T) {
 	}
 }
 
+func TestConvert_Lists(t *testing.T) {
+	converted := convert("Steps:\n1. Open\n2. **Edit**\n   - note\n3. Save\nDone\n4. Again\n* one\n  * two")
+	body := converted.body.String()
+	for _, expected := range []string{
+		`<w:p><w:pPr><w:pStyle w:val="ListParagraph"/><w:numPr><w:ilvl w:val="0"/><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:t xml:space="preserve">Open</w:t></w:r></w:p>`,
+		`<w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t xml:space="preserve">note</w:t></w:r>`,
+		`<w:numPr><w:ilvl w:val="0"/><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:t xml:space="preserve">Save</w:t></w:r>`,
+		`<w:numPr><w:ilvl w:val="0"/><w:numId w:val="3"/></w:numPr></w:pPr><w:r><w:t xml:space="preserve">Again</w:t></w:r>`,
+		`<w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t xml:space="preserve">two</w:t></w:r>`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected '%s' in '%s'", expected, body)
+		}
+	}
+	if !converted.hasLists || len(converted.numbered) != 2 || converted.numbered[1].Start != 4 {
+		t.Fatalf("Expected two numbered lists, got %+v", converted.numbered)
+	}
+	numbering := string(converted.numbering())
+	if !strings.Contains(numbering, `<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>`) ||
+		!strings.Contains(numbering, `<w:num w:numId="3"><w:abstractNumId w:val="1"/><w:lvlOverride w:ilvl="0"><w:startOverride w:val="4"/></w:lvlOverride></w:num>`) {
+		t.Errorf("Expected a bulleted list and numbered lists that restart, got '%s'", numbering)
+	}
+	if convert("Just text").hasLists {
+		t.Error("Expected no lists in text without list items")
+	}
+}
+
 // TestDetermineStyle tests the determineStyle function for various input lines.
 func TestDetermineStyle(t *testing.T) {
 	// Define test cases
@@ -248,3 +275,31 @@ func TestEscapeXMLChars(t *testing.T) {
 		})
 	}
 }
+
+func TestConvert(t *testing.T) {
+	links := func(url string) string {
+		if url != "https://example.com/?a=1&b=2" {
+			t.Errorf("Unexpected link target %q", url)
+		}
+		return "rId7"
+	}
+
+	paragraphs := Convert("## Heading\n\n  Read [the docs](https://example.com/?a=1&b=2) today  \n", links)
+	if len(paragraphs) != 2 {
+		t.Fatalf("Expected 2 paragraphs, got %d", len(paragraphs))
+	}
+	if paragraphs[0].Style != "Heading2" || paragraphs[0].Content != `<w:r><w:t xml:space="preserve">Heading</w:t></w:r>` {
+		t.Errorf("Unexpected heading paragraph %+v", paragraphs[0])
+	}
+	expected := `<w:r><w:t xml:space="preserve">Read </w:t></w:r>` +
+		`<w:hyperlink r:id="rId7" w:history="1"><w:r><w:rPr><w:rStyle w:val="Hyperlink"/></w:rPr><w:t xml:space="preserve">the docs</w:t></w:r></w:hyperlink>` +
+		`<w:r><w:t xml:space="preserve"> today</w:t></w:r>`
+	if paragraphs[1].Style != "Normal" || paragraphs[1].Content != expected {
+		t.Errorf("Expected %s, got %+v", expected, paragraphs[1])
+	}
+
+	withoutLinks := Convert("[the docs](https://example.com)", nil)
+	if withoutLinks[0].Content != `<w:r><w:t xml:space="preserve">the docs</w:t></w:r>` {
+		t.Errorf("Expected link text only, got %s", withoutLinks[0].Content)
+	}
+}
@@ -0,0 +1,212 @@
+// Package media reads what documents need to know about the images they
+// embed: the format, the size in pixels and the resolution the image was
+// made for.
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultDPI is the resolution of images that do not record one.
+const DefaultDPI = 96
+
+// Info describes an image file. Format is "png", "jpeg", "gif", "bmp" or
+// "svg", which is also the extension of its media part.
+type Info struct {
+	Format        string
+	Width, Height int
+	DPIX, DPIY    float64
+}
+
+// Decode reads the header of an image file.
+func Decode(data []byte) (Info, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("BM")):
+		return decodeBMP(data)
+	case isSVG(data):
+		return decodeSVG(data)
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Info{}, fmt.Errorf("unsupported image: %w", err)
+	}
+	info := Info{Format: format, Width: config.Width, Height: config.Height, DPIX: DefaultDPI, DPIY: DefaultDPI}
+	switch format {
+	case "png":
+		readPNGResolution(data, &info)
+	case "jpeg":
+		readJPEGResolution(data, &info)
+	}
+	return info, nil
+}
+
+// ContentType returns the content type of the media parts of format.
+func ContentType(format string) string {
+	if format == "svg" {
+		return "image/svg+xml"
+	}
+	return "image/" + format
+}
+
+// Twips returns the size of the image at its resolution in twentieths of a
+// point.
+func (i Info) Twips() (int, int) {
+	return int(math.Round(float64(i.Width) * 1440 / i.DPIX)), int(math.Round(float64(i.Height) * 1440 / i.DPIY))
+}
+
+// readPNGResolution reads the pHYs chunk, which comes before the image data.
+func readPNGResolution(data []byte, info *Info) {
+	for offset := 8; offset+12 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[offset:]))
+		chunk := string(data[offset+4 : offset+8])
+		body := data[offset+8:]
+		if chunk == "IDAT" || length > len(body) {
+			return
+		}
+		// The unit 1 is the meter; without it the chunk gives only the aspect ratio.
+		if chunk == "pHYs" && length >= 9 && body[8] == 1 {
+			setResolution(info,
+				float64(binary.BigEndian.Uint32(body))*0.0254,
+				float64(binary.BigEndian.Uint32(body[4:]))*0.0254)
+			return
+		}
+		offset += 12 + length
+	}
+}
+
+// readJPEGResolution reads the density of the JFIF APP0 segment.
+func readJPEGResolution(data []byte, info *Info) {
+	for offset := 2; offset+4 <= len(data) && data[offset] == 0xFF; {
+		marker := data[offset+1]
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+		if marker == 0xDA || offset+2+length > len(data) {
+			return
+		}
+		segment := data[offset+4 : offset+2+length]
+		if marker == 0xE0 && len(segment) >= 12 && string(segment[:5]) == "JFIF\x00" {
+			x := float64(binary.BigEndian.Uint16(segment[8:]))
+			y := float64(binary.BigEndian.Uint16(segment[10:]))
+			switch segment[7] {
+			case 1:
+				setResolution(info, x, y)
+			case 2:
+				setResolution(info, x*2.54, y*2.54)
+			}
+			return
+		}
+		offset += 2 + length
+	}
+}
+
+func setResolution(info *Info, x, y float64) {
+	if x >= 1 && y >= 1 {
+		info.DPIX, info.DPIY = x, y
+	}
+}
+
+// decodeBMP reads the BITMAPINFOHEADER, or the BITMAPCOREHEADER of old files.
+func decodeBMP(data []byte) (Info, error) {
+	if len(data) < 26 {
+		return Info{}, errors.New("unsupported image: truncated BMP header")
+	}
+	info := Info{Format: "bmp", DPIX: DefaultDPI, DPIY: DefaultDPI}
+	headerSize := binary.LittleEndian.Uint32(data[14:])
+	if headerSize == 12 {
+		info.Width = int(binary.LittleEndian.Uint16(data[18:]))
+		info.Height = int(binary.LittleEndian.Uint16(data[20:]))
+		return info, nil
+	}
+	info.Width = int(int32(binary.LittleEndian.Uint32(data[18:])))
+	// A negative height stores the rows from the top.
+	info.Height = int(math.Abs(float64(int32(binary.LittleEndian.Uint32(data[22:])))))
+	if headerSize >= 40 && len(data) >= 46 {
+		setResolution(&info,
+			float64(int32(binary.LittleEndian.Uint32(data[38:])))*0.0254,
+			float64(int32(binary.LittleEndian.Uint32(data[42:])))*0.0254)
+	}
+	if info.Width <= 0 || info.Height == 0 {
+		return Info{}, errors.New("unsupported image: invalid BMP size")
+	}
+	return info, nil
+}
+
+func isSVG(data []byte) bool {
+	head := data[:min(len(data), 1024)]
+	return bytes.Contains(head, []byte("<svg"))
+}
+
+// decodeSVG reads the size of the root svg element from its width and height
+// or, for relative ones, its viewBox. SVG measures lengths in CSS pixels.
+func decodeSVG(data []byte) (Info, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return Info{}, errors.New("unsupported image: no svg element")
+		}
+		if err != nil {
+			return Info{}, fmt.Errorf("unsupported image: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "svg" {
+			return Info{}, errors.New("unsupported image: the root element is not svg")
+		}
+		var width, height float64
+		var viewBox []string
+		for _, attr := range start.Attr {
+			switch attr.Name.Local {
+			case "width":
+				width = svgLength(attr.Value)
+			case "height":
+				height = svgLength(attr.Value)
+			case "viewBox":
+				viewBox = strings.Fields(strings.ReplaceAll(attr.Value, ",", " "))
+			}
+		}
+		if len(viewBox) == 4 {
+			boxWidth, _ := strconv.ParseFloat(viewBox[2], 64)
+			boxHeight, _ := strconv.ParseFloat(viewBox[3], 64)
+			switch {
+			case width == 0 && height == 0:
+				width, height = boxWidth, boxHeight
+			case width == 0 && boxHeight > 0:
+				width = height * boxWidth / boxHeight
+			case height == 0 && boxWidth > 0:
+				height = width * boxHeight / boxWidth
+			}
+		}
+		if width <= 0 || height <= 0 {
+			return Info{}, errors.New("unsupported image: the svg element has no size")
+		}
+		return Info{Format: "svg", Width: int(math.Round(width)), Height: int(math.Round(height)), DPIX: DefaultDPI, DPIY: DefaultDPI}, nil
+	}
+}
+
+// svgUnits are the CSS pixels of the absolute units of SVG lengths.
+var svgUnits = map[string]float64{"": 1, "px": 1, "pt": 96.0 / 72, "pc": 16, "in": 96, "cm": 96 / 2.54, "mm": 96 / 25.4}
+
+// svgLength returns a length in pixels, or 0 for relative lengths.
+func svgLength(value string) float64 {
+	value = strings.TrimSpace(value)
+	unit := strings.TrimLeft(value, "0123456789.+-eE")
+	number, err := strconv.ParseFloat(strings.TrimSuffix(value, unit), 64)
+	if err != nil {
+		return 0
+	}
+	return number * svgUnits[unit]
+}
@@ -0,0 +1,119 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func encode(t *testing.T, format string) []byte {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, 40, 20))
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&buf, img)
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, nil)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatalf("error encoding %s: %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+// withPNGResolution inserts a pHYs chunk after the IHDR chunk.
+func withPNGResolution(data []byte, pixelsPerMeter uint32) []byte {
+	chunk := make([]byte, 21)
+	binary.BigEndian.PutUint32(chunk, 9)
+	copy(chunk[4:], "pHYs")
+	binary.BigEndian.PutUint32(chunk[8:], pixelsPerMeter)
+	binary.BigEndian.PutUint32(chunk[12:], pixelsPerMeter)
+	chunk[16] = 1
+	ihdrEnd := 8 + 12 + 13
+	return append(append(append([]byte(nil), data[:ihdrEnd]...), chunk...), data[ihdrEnd:]...)
+}
+
+// withJFIFDensity replaces the APP0 segment the encoder leaves out.
+func withJFIFDensity(data []byte, dpi uint16) []byte {
+	app0 := []byte{0xFF, 0xE0, 0, 16, 'J', 'F', 'I', 'F', 0, 1, 1, 1, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint16(app0[12:], dpi)
+	binary.BigEndian.PutUint16(app0[14:], dpi)
+	return append(append(append([]byte(nil), data[:2]...), app0...), data[2:]...)
+}
+
+func bmp(width, height int32, pixelsPerMeter int32) []byte {
+	data := make([]byte, 54)
+	copy(data, "BM")
+	binary.LittleEndian.PutUint32(data[14:], 40)
+	binary.LittleEndian.PutUint32(data[18:], uint32(width))
+	binary.LittleEndian.PutUint32(data[22:], uint32(height))
+	binary.LittleEndian.PutUint32(data[38:], uint32(pixelsPerMeter))
+	binary.LittleEndian.PutUint32(data[42:], uint32(pixelsPerMeter))
+	return data
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		format string
+		width  int
+		height int
+		dpi    float64
+	}{
+		{"png", encode(t, "png"), "png", 40, 20, 96},
+		{"png with pHYs", withPNGResolution(encode(t, "png"), 11811), "png", 40, 20, 299.9994},
+		{"jpeg", encode(t, "jpeg"), "jpeg", 40, 20, 96},
+		{"jpeg with JFIF density", withJFIFDensity(encode(t, "jpeg"), 144), "jpeg", 40, 20, 144},
+		{"gif", encode(t, "gif"), "gif", 40, 20, 96},
+		{"bmp", bmp(30, -10, 0), "bmp", 30, 10, 96},
+		{"bmp with resolution", bmp(30, 10, 7874), "bmp", 30, 10, 199.9996},
+		{"svg", []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg" width="2in" height="72pt"/>`), "svg", 192, 96, 96},
+		{"svg view box", []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="100%" viewBox="0 0 300 150"/>`), "svg", 300, 150, 96},
+		{"svg height and view box", []byte(`<svg height="50" viewBox="0,0,300,150"/>`), "svg", 100, 50, 96},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info, err := Decode(test.data)
+			if err != nil {
+				t.Fatalf("Decode returned an error: %v", err)
+			}
+			if info.Format != test.format || info.Width != test.width || info.Height != test.height {
+				t.Errorf("Expected a %dx%d %s, got %+v", test.width, test.height, test.format, info)
+			}
+			if diff := info.DPIX - test.dpi; diff > 0.01 || diff < -0.01 || info.DPIY != info.DPIX {
+				t.Errorf("Expected %v DPI, got %+v", test.dpi, info)
+			}
+		})
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	for _, data := range [][]byte{[]byte("not an image"), []byte("BM"), []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`), []byte(`<html><svg/></html>`)} {
+		if _, err := Decode(data); err == nil || !strings.HasPrefix(err.Error(), "unsupported image") {
+			t.Errorf("Expected an unsupported image error for %q, got %v", data, err)
+		}
+	}
+}
+
+func TestInfo_Twips(t *testing.T) {
+	width, height := Info{Width: 300, Height: 150, DPIX: 300, DPIY: 150}.Twips()
+	if width != 1440 || height != 1440 {
+		t.Errorf("Expected an inch square, got %dx%d", width, height)
+	}
+}
+
+func TestContentType(t *testing.T) {
+	if ContentType("svg") != "image/svg+xml" || ContentType("bmp") != "image/bmp" {
+		t.Errorf("Unexpected content types %s and %s", ContentType("svg"), ContentType("bmp"))
+	}
+}
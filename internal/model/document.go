@@ -0,0 +1,326 @@
+// Package model is an in-memory Word document that can be built in code, or
+// read from a DOCX file with Open, and saved as a DOCX file. The builder
+// methods return the element they add or change so that calls can be chained:
+//
+//	doc := model.New()
+//	doc.AddParagraph("Report").Style("Heading1")
+//	doc.AddParagraph("Total: ").AddRun("42").Bold()
+//
+// Markup the model does not know is kept as RawBlock and RawInline elements
+// and as unexported properties, so that a document that is opened and saved
+// again keeps everything Word shows.
+package model
+
+import "github.com/aliamerj/docxer/internal/opc"
+
+// Document is a Word document: its body split into sections, and the
+// headers and footers of an opened document. Which pages show a header or
+// footer is up to the Headers and Footers of the sections.
+type Document struct {
+	Sections []*Section
+	Headers  []*HeaderFooter
+	Footers  []*HeaderFooter
+
+	// lists are the lists added to the numbering part.
+	lists  []*List
+	source *source
+}
+
+// source is the package a document was opened from.
+type source struct {
+	pkg          *opc.Package
+	documentPath string
+	// start and end are the markup of the main document part around the
+	// content of w:body.
+	start, end string
+}
+
+// Section is a part of the body that has its own page setup, headers and
+// footers. A section without a header or footer for some pages shows that of
+// the section before it.
+type Section struct {
+	Blocks  []Block
+	Page    PageSetup
+	Headers map[HeaderFooterType]*HeaderFooter
+	Footers map[HeaderFooterType]*HeaderFooter
+
+	// properties is the w:sectPr element the section was read with, and
+	// readPage, readHeaders and readFooters are the page setup, headers and
+	// footers it had.
+	properties               string
+	readPage                 PageSetup
+	readHeaders, readFooters map[HeaderFooterType]*HeaderFooter
+}
+
+// PageSetup is the page layout of a section, in twentieths of a point. Size
+// is the size of the page held upright, which Landscape turns. Columns is
+// the number of columns of the text and ColumnSpace the space between them.
+// Start is where the section begins after the one before it.
+//
+// The size, margins and columns of a section that was read are kept as they
+// were while they are zero.
+type PageSetup struct {
+	Size        PageSize
+	Landscape   bool
+	Margins     Margins
+	Columns     int
+	ColumnSpace int
+	Start       SectionStart
+}
+
+// PageSize is the width and height of a page.
+type PageSize struct {
+	Width, Height int
+}
+
+// Page sizes in portrait orientation.
+var (
+	A3     = PageSize{16838, 23811}
+	A4     = PageSize{11906, 16838}
+	A5     = PageSize{8391, 11906}
+	Letter = PageSize{12240, 15840}
+	Legal  = PageSize{12240, 20160}
+)
+
+// Margins are the space between the edges of the page and the text. Header
+// and Footer are the distances of the header and footer from the top and
+// bottom edges, and Gutter is added to the left margin for binding.
+type Margins struct {
+	Top, Right, Bottom, Left int
+	Header, Footer, Gutter   int
+}
+
+// SectionStart is the break that begins a section.
+type SectionStart int
+
+const (
+	// StartNextPage begins the section on a new page.
+	StartNextPage SectionStart = iota
+	// StartContinuous begins the section on the same page.
+	StartContinuous
+	StartNextColumn
+	StartEvenPage
+	StartOddPage
+)
+
+// defaultPage is the page setup of new sections: US Letter with the margins
+// of the other generated documents.
+var defaultPage = PageSetup{
+	Size:    Letter,
+	Margins: Margins{Top: 1134, Right: 1134, Bottom: 1134, Left: 1134, Header: 720, Footer: 720},
+}
+
+// HeaderFooterType is the pages of a section a header or footer is shown on.
+type HeaderFooterType int
+
+const (
+	// DefaultPages are all pages of the section, except the first one when it
+	// has a FirstPage header or footer and the even ones when it has an
+	// EvenPages one.
+	DefaultPages HeaderFooterType = iota
+	FirstPage
+	EvenPages
+)
+
+// HeaderFooter is a header or footer. Part is the name of the part of an
+// opened document it was read from, and empty for new ones.
+type HeaderFooter struct {
+	Part   string
+	Blocks []Block
+
+	footer     bool
+	start, end string
+}
+
+// Block is a *Paragraph, *Table or *RawBlock.
+type Block interface {
+	block()
+}
+
+// RawBlock is body markup the model does not know, such as a content control
+// or a bookmark, kept as it was read.
+type RawBlock struct {
+	XML string
+}
+
+func (*RawBlock) block() {}
+
+// New returns an empty document with one section.
+func New() *Document {
+	return &Document{Sections: []*Section{{Page: defaultPage}}}
+}
+
+// AddSection starts a new section on a new page; the following blocks added
+// to the document go into it. The section has the page setup of the one
+// before it.
+func (d *Document) AddSection() *Section {
+	page := defaultPage
+	if n := len(d.Sections); n > 0 {
+		page = d.Sections[n-1].Page
+		page.Start = StartNextPage
+	}
+	section := &Section{Page: page}
+	d.Sections = append(d.Sections, section)
+	return section
+}
+
+// lastSection returns the section blocks added to the document go into.
+func (d *Document) lastSection() *Section {
+	if len(d.Sections) == 0 {
+		d.Sections = append(d.Sections, &Section{Page: defaultPage})
+	}
+	return d.Sections[len(d.Sections)-1]
+}
+
+// firstSection returns the section headers and footers added to the document
+// go into.
+func (d *Document) firstSection() *Section {
+	if len(d.Sections) == 0 {
+		d.Sections = append(d.Sections, &Section{Page: defaultPage})
+	}
+	return d.Sections[0]
+}
+
+// AddParagraph appends a paragraph to the last section, holding text in a
+// run unless it is empty.
+func (d *Document) AddParagraph(text string) *Paragraph {
+	return d.lastSection().AddParagraph(text)
+}
+
+// AddTable appends an empty table to the last section.
+func (d *Document) AddTable() *Table {
+	return d.lastSection().AddTable()
+}
+
+// AddPageBreak appends a paragraph holding a page break to the last section.
+func (d *Document) AddPageBreak() *Paragraph {
+	paragraph := d.AddParagraph("")
+	paragraph.AddBreak(PageBreak)
+	return paragraph
+}
+
+// AddParagraph appends a paragraph to the section, holding text in a run
+// unless it is empty.
+func (s *Section) AddParagraph(text string) *Paragraph {
+	paragraph := newParagraph(text)
+	s.Blocks = append(s.Blocks, paragraph)
+	return paragraph
+}
+
+// AddTable appends an empty table to the section.
+func (s *Section) AddTable() *Table {
+	table := newTable()
+	s.Blocks = append(s.Blocks, table)
+	return table
+}
+
+// Start sets the break that begins the section.
+func (s *Section) Start(start SectionStart) *Section {
+	s.Page.Start = start
+	return s
+}
+
+// Size sets the size of the pages of the section.
+func (s *Section) Size(size PageSize) *Section {
+	s.Page.Size = size
+	return s
+}
+
+// Landscape turns the pages of the section sideways.
+func (s *Section) Landscape() *Section {
+	s.Page.Landscape = true
+	return s
+}
+
+// Portrait holds the pages of the section upright.
+func (s *Section) Portrait() *Section {
+	s.Page.Landscape = false
+	return s
+}
+
+// Margins sets the margins of the pages of the section.
+func (s *Section) Margins(margins Margins) *Section {
+	s.Page.Margins = margins
+	return s
+}
+
+// Columns lays out the text of the section in count columns, space apart.
+func (s *Section) Columns(count, space int) *Section {
+	s.Page.Columns, s.Page.ColumnSpace = count, space
+	return s
+}
+
+// AddHeader adds a header to the first section, which the following sections
+// show as well unless they have their own.
+func (d *Document) AddHeader(pages HeaderFooterType) *HeaderFooter {
+	return d.firstSection().AddHeader(pages)
+}
+
+// AddFooter adds a footer to the first section, which the following sections
+// show as well unless they have their own.
+func (d *Document) AddFooter(pages HeaderFooterType) *HeaderFooter {
+	return d.firstSection().AddFooter(pages)
+}
+
+// AddHeader sets an empty header of the section for pages, replacing the one
+// it had.
+func (s *Section) AddHeader(pages HeaderFooterType) *HeaderFooter {
+	header := &HeaderFooter{}
+	if s.Headers == nil {
+		s.Headers = map[HeaderFooterType]*HeaderFooter{}
+	}
+	s.Headers[pages] = header
+	return header
+}
+
+// AddFooter sets an empty footer of the section for pages, replacing the one
+// it had.
+func (s *Section) AddFooter(pages HeaderFooterType) *HeaderFooter {
+	footer := &HeaderFooter{footer: true}
+	if s.Footers == nil {
+		s.Footers = map[HeaderFooterType]*HeaderFooter{}
+	}
+	s.Footers[pages] = footer
+	return footer
+}
+
+// AddParagraph appends a paragraph to the header or footer, holding text in
+// a run unless it is empty.
+func (h *HeaderFooter) AddParagraph(text string) *Paragraph {
+	paragraph := newParagraph(text)
+	h.Blocks = append(h.Blocks, paragraph)
+	return paragraph
+}
+
+// AddTable appends an empty table to the header or footer.
+func (h *HeaderFooter) AddTable() *Table {
+	table := newTable()
+	h.Blocks = append(h.Blocks, table)
+	return table
+}
+
+// Paragraphs returns the paragraphs of the document outside tables, in order.
+func (d *Document) Paragraphs() []*Paragraph {
+	var paragraphs []*Paragraph
+	for _, section := range d.Sections {
+		for _, block := range section.Blocks {
+			if paragraph, ok := block.(*Paragraph); ok {
+				paragraphs = append(paragraphs, paragraph)
+			}
+		}
+	}
+	return paragraphs
+}
+
+// Tables returns the top-level tables of the document, in order.
+func (d *Document) Tables() []*Table {
+	var tables []*Table
+	for _, section := range d.Sections {
+		for _, block := range section.Blocks {
+			if table, ok := block.(*Table); ok {
+				tables = append(tables, table)
+			}
+		}
+	}
+	return tables
+}
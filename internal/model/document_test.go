@@ -0,0 +1,46 @@
+package model
+
+import "testing"
+
+func TestDocument_Builder(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Report").Style("Heading1")
+	doc.AddParagraph("Total: ").AddRun("42").Bold().Italic()
+	doc.AddTable().AddRow("a", "b")
+	section := doc.AddSection()
+	section.AddParagraph("Appendix")
+	doc.AddPageBreak()
+
+	if len(doc.Sections) != 2 {
+		t.Fatalf("Expected 2 sections, got %d", len(doc.Sections))
+	}
+	if len(doc.Sections[1].Blocks) != 2 {
+		t.Errorf("Expected blocks to go into the last section, got %d", len(doc.Sections[1].Blocks))
+	}
+
+	paragraphs := doc.Paragraphs()
+	if len(paragraphs) != 4 {
+		t.Fatalf("Expected 4 paragraphs, got %d", len(paragraphs))
+	}
+	if paragraphs[0].Properties.Style != "Heading1" || paragraphs[0].Text() != "Report" {
+		t.Errorf("Unexpected first paragraph %+v", paragraphs[0])
+	}
+	runs := paragraphs[1].Runs()
+	if len(runs) != 2 || runs[1].Text != "42" || !runs[1].Properties.Bold || !runs[1].Properties.Italic || runs[0].Properties.Bold {
+		t.Errorf("Expected only the second run to be bold and italic, got %+v, %+v", runs[0], runs[1])
+	}
+	if brk, ok := paragraphs[3].Content[0].(*Break); !ok || brk.Type != PageBreak {
+		t.Errorf("Expected a page break, got %+v", paragraphs[3].Content)
+	}
+	if tables := doc.Tables(); len(tables) != 1 || tables[0].Rows[0].Cells[1].Text() != "b" {
+		t.Errorf("Expected one table, got %+v", tables)
+	}
+}
+
+func TestDocument_ZeroValue(t *testing.T) {
+	var doc Document
+	doc.AddParagraph("text")
+	if len(doc.Sections) != 1 || len(doc.Paragraphs()) != 1 {
+		t.Errorf("Expected a section to be created, got %+v", doc.Sections)
+	}
+}
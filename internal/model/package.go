@@ -0,0 +1,235 @@
+package model
+
+import (
+	"io/fs"
+	"strconv"
+
+	"github.com/aliamerj/docxer/internal/numbering"
+	"github.com/aliamerj/docxer/internal/opc"
+	"github.com/aliamerj/docxer/internal/template"
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+// Style is a style of the styles part. Type is "paragraph", "character",
+// "table" or "numbering"; Default marks the style used when none is set.
+type Style struct {
+	ID      string
+	Type    string
+	Name    string
+	BasedOn string
+	Default bool
+}
+
+// Relationship links the main document part to another part, or to an
+// external resource such as a hyperlink target.
+type Relationship = opc.Relationship
+
+// List is a numbering of the numbering part, which paragraphs join through
+// ParagraphProperties.List.
+type List struct {
+	ID     string
+	Levels []ListLevel
+
+	// abstractID is the abstract numbering of a list added to the document,
+	// which a restarted list shares with the list it restarts.
+	abstractID string
+	restart    bool
+}
+
+// ListLevel is the numbering of a level of a list.
+type ListLevel = numbering.Level
+
+// BulletList returns the levels of a bulleted list, using bullets in turn.
+func BulletList(bullets ...string) []ListLevel {
+	return numbering.Bullets(bullets...)
+}
+
+// DecimalList returns the levels of a list numbered 1., 2., 3.
+func DecimalList() []ListLevel {
+	return numbering.Decimal()
+}
+
+// LetterList returns the levels of a list numbered a., b., c.
+func LetterList() []ListLevel {
+	return numbering.Letters()
+}
+
+// RomanList returns the levels of a list numbered i., ii., iii.
+func RomanList() []ListLevel {
+	return numbering.Roman()
+}
+
+// LegalList returns the levels of a list numbered 1., 1.1., 1.1.1.
+func LegalList() []ListLevel {
+	return numbering.Legal()
+}
+
+// AddList adds a list with levels to the numbering part, for paragraphs to
+// join with Paragraph.ListItem.
+func (d *Document) AddList(levels []ListLevel) *List {
+	list := &List{Levels: levels, abstractID: strconv.Itoa(d.nextListID("w:abstractNum", "w:abstractNumId"))}
+	list.ID = strconv.Itoa(d.nextListID("w:num", "w:numId"))
+	d.lists = append(d.lists, list)
+	return list
+}
+
+// RestartList adds a list that looks like list but whose numbering starts
+// over at the Start of its levels, instead of continuing that of list.
+func (d *Document) RestartList(list *List) *List {
+	abstractID := list.abstractID
+	if abstractID == "" {
+		abstractID = d.readAbstractID(list.ID)
+	}
+	restarted := &List{Levels: list.Levels, abstractID: abstractID, restart: true}
+	restarted.ID = strconv.Itoa(d.nextListID("w:num", "w:numId"))
+	d.lists = append(d.lists, restarted)
+	return restarted
+}
+
+// nextListID returns an id of the elements called name of the numbering
+// part that neither the part nor the lists added to the document use.
+func (d *Document) nextListID(name, attr string) int {
+	next := numbering.NextID(string(d.relatedPart(numbering.RelType)), name, attr)
+	for _, list := range d.lists {
+		id := list.ID
+		if name == "w:abstractNum" {
+			id = list.abstractID
+		}
+		if value, err := strconv.Atoi(id); err == nil {
+			next = max(next, value+1)
+		}
+	}
+	return next
+}
+
+// readAbstractID returns the abstract numbering of the list with the given
+// id of the numbering part.
+func (d *Document) readAbstractID(id string) string {
+	content := string(d.relatedPart(numbering.RelType))
+	for _, element := range utils.FindElements(content, "w:num") {
+		if numID, _ := utils.Attr(element.StartTag(content), "w:numId"); numID == id {
+			inner := element.Inner(content)
+			if abstractID, ok := utils.FindElement(inner, "w:abstractNumId", 0); ok {
+				return val(abstractID.Outer(inner))
+			}
+		}
+	}
+	return ""
+}
+
+// Styles returns the styles the document defines.
+func (d *Document) Styles() []Style {
+	content := d.relatedPart(template.StylesRelType)
+	if d.source == nil {
+		content, _ = fs.ReadFile(templateFS, "template/styles.xml")
+	}
+	styles := string(content)
+
+	var result []Style
+	for _, element := range utils.FindElements(styles, "w:style") {
+		tag := element.StartTag(styles)
+		style := Style{}
+		style.ID, _ = utils.Attr(tag, "w:styleId")
+		style.Type, _ = utils.Attr(tag, "w:type")
+		def, _ := utils.Attr(tag, "w:default")
+		style.Default = def == "1" || def == "true" || def == "on"
+		inner := element.Inner(styles)
+		for _, child := range utils.Children(inner) {
+			switch child.Name(inner) {
+			case "w:name":
+				style.Name = val(child.Outer(inner))
+			case "w:basedOn":
+				style.BasedOn = val(child.Outer(inner))
+			}
+		}
+		result = append(result, style)
+	}
+	return result
+}
+
+// Relationships returns the relationships of the main document part of an
+// opened document.
+func (d *Document) Relationships() []Relationship {
+	if d.source == nil {
+		return nil
+	}
+	return d.source.pkg.Relationships(d.source.documentPath)
+}
+
+// Lists returns the numberings the document defines, with the levels of their
+// abstract numbering and its start overrides applied, followed by the lists
+// added to it.
+func (d *Document) Lists() []List {
+	content := string(d.relatedPart(numbering.RelType))
+
+	abstract := map[string][]ListLevel{}
+	for _, element := range utils.FindElements(content, "w:abstractNum") {
+		id, _ := utils.Attr(element.StartTag(content), "w:abstractNumId")
+		abstract[id] = listLevels(element.Inner(content))
+	}
+
+	var lists []List
+	for _, element := range utils.FindElements(content, "w:num") {
+		list := List{}
+		list.ID, _ = utils.Attr(element.StartTag(content), "w:numId")
+		inner := element.Inner(content)
+		if abstractID, ok := utils.FindElement(inner, "w:abstractNumId", 0); ok {
+			list.Levels = append(list.Levels, abstract[val(abstractID.Outer(inner))]...)
+		}
+		for _, override := range utils.FindElements(inner, "w:lvlOverride") {
+			ilvl, _ := utils.Attr(override.StartTag(inner), "w:ilvl")
+			level, _ := strconv.Atoi(ilvl)
+			overrideMarkup := override.Inner(inner)
+			start, ok := utils.FindElement(overrideMarkup, "w:startOverride", 0)
+			if !ok {
+				continue
+			}
+			for i := range list.Levels {
+				if list.Levels[i].Level == level {
+					list.Levels[i].Start, _ = strconv.Atoi(val(start.Outer(overrideMarkup)))
+				}
+			}
+		}
+		lists = append(lists, list)
+	}
+	for _, list := range d.lists {
+		lists = append(lists, *list)
+	}
+	return lists
+}
+
+func listLevels(markup string) []ListLevel {
+	var levels []ListLevel
+	for _, element := range utils.FindElements(markup, "w:lvl") {
+		level := ListLevel{Start: 1}
+		ilvl, _ := utils.Attr(element.StartTag(markup), "w:ilvl")
+		level.Level, _ = strconv.Atoi(ilvl)
+		inner := element.Inner(markup)
+		for _, child := range utils.Children(inner) {
+			switch child.Name(inner) {
+			case "w:start":
+				level.Start, _ = strconv.Atoi(val(child.Outer(inner)))
+			case "w:numFmt":
+				level.Format = val(child.Outer(inner))
+			case "w:lvlText":
+				level.Text = val(child.Outer(inner))
+			case "w:rPr":
+				if fonts, ok := utils.FindElement(child.Outer(inner), "w:rFonts", 0); ok {
+					level.Font, _ = utils.Attr(fonts.StartTag(child.Outer(inner)), "w:ascii")
+				}
+			}
+		}
+		levels = append(levels, level)
+	}
+	return levels
+}
+
+// relatedPart returns the content of the part the main document part of an
+// opened document relates to with relType, or nil.
+func (d *Document) relatedPart(relType string) []byte {
+	if d.source == nil {
+		return nil
+	}
+	content, _ := d.source.pkg.Part(d.source.pkg.Related(d.source.documentPath, relType))
+	return content
+}
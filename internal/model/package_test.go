@@ -0,0 +1,114 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDocument_Styles(t *testing.T) {
+	styles := readTestPackage(t, testPackage(t)).Styles()
+	expected := []Style{
+		{ID: "Normal", Type: "paragraph", Name: "Normal", Default: true},
+		{ID: "Title", Type: "paragraph", Name: "Title", BasedOn: "Normal"},
+	}
+	if !reflect.DeepEqual(styles, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, styles)
+	}
+
+	found := false
+	for _, style := range New().Styles() {
+		found = found || style.ID == "Heading1" && style.Type == "paragraph"
+	}
+	if !found {
+		t.Error("Expected new documents to have the built-in heading styles")
+	}
+}
+
+func TestDocument_Lists(t *testing.T) {
+	lists := readTestPackage(t, testPackage(t)).Lists()
+	expected := []List{{ID: "3", Levels: []ListLevel{
+		{Level: 0, Format: "decimal", Text: "%1.", Start: 5},
+		{Level: 1, Format: "lowerLetter", Text: "%2)", Start: 1},
+	}}}
+	if !reflect.DeepEqual(lists, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, lists)
+	}
+	if lists := New().Lists(); lists != nil {
+		t.Errorf("Expected no lists in a new document, got %+v", lists)
+	}
+}
+
+func TestDocument_AddList(t *testing.T) {
+	doc := New()
+	steps := doc.AddList(DecimalList())
+	doc.AddParagraph("First").ListItem(steps, 0)
+	doc.AddParagraph("Detail").ListItem(steps, 1)
+	again := doc.RestartList(steps)
+	doc.AddParagraph("Again").ListItem(again, 0)
+	doc.AddList(BulletList("■"))
+
+	if steps.ID != "1" || again.ID != "2" || len(doc.Lists()) != 3 {
+		t.Fatalf("Expected lists 1, 2 and 3, got %+v", doc.Lists())
+	}
+	parts := partsByName(t, doc)
+	numbering := parts["word/numbering.xml"]
+	for _, expected := range []string{
+		`<w:abstractNum w:abstractNumId="1"><w:multiLevelType w:val="hybridMultilevel"/><w:lvl w:ilvl="0"><w:start w:val="1"/><w:numFmt w:val="decimal"/><w:lvlText w:val="%1."/>`,
+		`<w:lvl w:ilvl="0"><w:start w:val="1"/><w:numFmt w:val="bullet"/><w:lvlText w:val="■"/>`,
+		`<w:num w:numId="1"><w:abstractNumId w:val="1"/></w:num><w:num w:numId="2"><w:abstractNumId w:val="1"/><w:lvlOverride w:ilvl="0"><w:startOverride w:val="1"/></w:lvlOverride>`,
+		`<w:num w:numId="3"><w:abstractNumId w:val="2"/></w:num></w:numbering>`,
+	} {
+		if !strings.Contains(numbering, expected) {
+			t.Errorf("Expected '%s' in '%s'", expected, numbering)
+		}
+	}
+	if strings.Count(numbering, "<w:abstractNum ") != 2 {
+		t.Errorf("Expected the restarted list to share its abstract numbering, got '%s'", numbering)
+	}
+	if !strings.Contains(parts["word/document.xml"], `<w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t xml:space="preserve">Detail</w:t>`) {
+		t.Errorf("Expected the paragraph to join the list, got '%s'", parts["word/document.xml"])
+	}
+	if !strings.Contains(parts["word/_rels/document.xml.rels"], `Target="numbering.xml"`) || !strings.Contains(parts["[Content_Types].xml"], `PartName="/word/numbering.xml"`) {
+		t.Errorf("Expected the numbering part to be related and typed, got '%s'", parts["word/_rels/document.xml.rels"])
+	}
+}
+
+func TestDocument_AddListOpened(t *testing.T) {
+	doc := readTestPackage(t, testPackage(t))
+	lists := doc.Lists()
+	letters := doc.AddList(LetterList())
+	restarted := doc.RestartList(&lists[0])
+	if letters.ID != "4" || restarted.ID != "5" {
+		t.Errorf("Expected ids after those of the numbering part, got %s and %s", letters.ID, restarted.ID)
+	}
+
+	numbering := partsByName(t, doc)["word/numbering.xml"]
+	expected := []string{
+		`</w:abstractNum><w:abstractNum w:abstractNumId="1">`,
+		`<w:num w:numId="4"><w:abstractNumId w:val="1"/></w:num>`,
+		`<w:num w:numId="5"><w:abstractNumId w:val="0"/><w:lvlOverride w:ilvl="0"><w:startOverride w:val="5"/></w:lvlOverride><w:lvlOverride w:ilvl="1"><w:startOverride w:val="1"/></w:lvlOverride></w:num></w:numbering>`,
+	}
+	for _, markup := range expected {
+		if !strings.Contains(numbering, markup) {
+			t.Errorf("Expected '%s' in '%s'", markup, numbering)
+		}
+	}
+	if lists := readTestPackage(t, partsByName(t, doc)).Lists(); len(lists) != 3 || lists[1].Levels[0].Format != "lowerLetter" {
+		t.Errorf("Expected the added lists to read back, got %+v", lists)
+	}
+}
+
+func TestDocument_Relationships(t *testing.T) {
+	relationships := readTestPackage(t, testPackage(t)).Relationships()
+	if len(relationships) != 5 {
+		t.Fatalf("Expected 5 relationships, got %d", len(relationships))
+	}
+	link := relationships[4]
+	if link.ID != "rId9" || link.Target != "https://example.com" || !link.External {
+		t.Errorf("Unexpected hyperlink relationship %+v", link)
+	}
+	if relationships[3].Target != "header1.xml" || relationships[3].External {
+		t.Errorf("Unexpected header relationship %+v", relationships[3])
+	}
+}
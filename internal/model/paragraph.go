@@ -0,0 +1,549 @@
+package model
+
+import (
+	"bytes"
+	"math"
+	"strings"
+
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+// Paragraph is a paragraph of text, images and breaks.
+type Paragraph struct {
+	Properties ParagraphProperties
+	Content    []Inline
+
+	// start is the start tag the paragraph was read with.
+	start string
+}
+
+// ParagraphProperties is the formatting of a paragraph. Style is a paragraph
+// style id such as "Heading1". List is the id of the numbering the paragraph
+// belongs to, if any, and ListLevel its level there. Align is "left",
+// "center", "right" or "both" for justified text. KeepNext keeps the
+// paragraph on the page of the next one and KeepLines keeps its lines on one
+// page. WidowControl, when set, turns the prevention of single lines at the
+// top or bottom of a page on or off. Shading is a fill color such as
+// "F2F2F2".
+//
+// The borders and shading of a paragraph that was read are kept as they
+// were unless Borders or Shading are set.
+type ParagraphProperties struct {
+	Style           string
+	List            string
+	ListLevel       int
+	Align           string
+	Spacing         *Spacing
+	Indent          *Indent
+	Tabs            []TabStop
+	KeepNext        bool
+	KeepLines       bool
+	PageBreakBefore bool
+	WidowControl    *bool
+	Borders         *ParagraphBorders
+	Shading         string
+
+	extra string
+}
+
+// Spacing is the space around and between the lines of a paragraph. Before
+// and After are in twentieths of a point. Line is in 240ths of a line, or in
+// twentieths of a point when LineRule is "exact" or "atLeast"; when zero the
+// line spacing is left to the style.
+type Spacing struct {
+	Before, After int
+	Line          int
+	LineRule      string
+}
+
+// Indent is the indentation of a paragraph from the margins in twentieths
+// of a point. The first line is indented by FirstLine more, or by Hanging
+// less.
+type Indent struct {
+	Left, Right        int
+	FirstLine, Hanging int
+}
+
+// TabStop is a position of the text after a tab, in twentieths of a point
+// from the left margin. Align is "left", "center", "right" or "decimal" and
+// Leader fills the space before the tab stop with "dot", "hyphen",
+// "underscore" or "middleDot" characters.
+type TabStop struct {
+	Position int
+	Align    string
+	Leader   string
+}
+
+// ParagraphBorders are the borders of a paragraph. Between is drawn between
+// paragraphs with the same borders.
+type ParagraphBorders struct {
+	Top, Left, Bottom, Right, Between Border
+}
+
+// Inline is the content of a paragraph: a *Run, *Break, *Image, *Field or
+// *RawInline.
+type Inline interface {
+	inline()
+}
+
+// RawInline is paragraph content the model does not know, such as a
+// hyperlink, a field or a bookmark, kept as it was read.
+type RawInline struct {
+	XML string
+}
+
+// Run is text with the same formatting.
+type Run struct {
+	Text       string
+	Properties RunProperties
+}
+
+// RunProperties is the formatting of a run. Style is a character style id
+// such as "Hyperlink". Size is in half points and Spacing, the space added
+// between characters, in twentieths of a point. Color is a color such as
+// "FF0000", Highlight a highlight color such as "yellow" and Underline a line
+// style such as "single", "double", "dotted" or "wave". Language is a
+// language tag such as "en-US" for spelling and hyphenation. Hidden text is
+// not shown or printed.
+//
+// Properties of a run that was read which are written in a way the fields
+// cannot express, such as theme colors, are kept as they were.
+type RunProperties struct {
+	Style        string
+	Bold         bool
+	Italic       bool
+	Fonts        Fonts
+	Size         int
+	Color        string
+	Highlight    string
+	Underline    string
+	Strike       bool
+	DoubleStrike bool
+	Superscript  bool
+	Subscript    bool
+	SmallCaps    bool
+	AllCaps      bool
+	Spacing      int
+	Language     string
+	Hidden       bool
+
+	extra string
+}
+
+// Fonts are the fonts of a run: ASCII for Latin text, EastAsia for Chinese,
+// Japanese and Korean text and ComplexScript for scripts such as Arabic and
+// Hebrew.
+type Fonts struct {
+	ASCII, EastAsia, ComplexScript string
+}
+
+// Field is a field Word computes, such as "PAGE", "NUMPAGES" or
+// `DATE \@ "d MMMM yyyy"`. Result is the text shown until Word updates the
+// field. Fields of an opened document are kept as RawInline content.
+type Field struct {
+	Code       string
+	Result     string
+	Properties RunProperties
+}
+
+// BreakType is the kind of a break.
+type BreakType int
+
+const (
+	LineBreak BreakType = iota
+	PageBreak
+	ColumnBreak
+)
+
+// Break ends a line, page or column inside a paragraph.
+type Break struct {
+	Type BreakType
+}
+
+// Image is a picture shown with the text. Data is a PNG, JPEG, GIF or BMP
+// file; SVG, when set, is an SVG version of the picture which Data stands in
+// for where SVG is not supported. Width and Height are in twentieths of a
+// point; when zero they are taken from the size and resolution the image
+// records, keeping its aspect ratio when only one is set. An image wider
+// than a non-zero MaxWidth is scaled down to fit. Title and AltText are read
+// out in place of the image. A Floating image is placed on the page with the
+// text wrapping around it instead of inline.
+type Image struct {
+	Data     []byte
+	SVG      []byte
+	Width    int
+	Height   int
+	MaxWidth int
+	AltText  string
+	Title    string
+	Floating *Floating
+
+	source *imageSource
+}
+
+// WrapType is how text flows around a floating image.
+type WrapType int
+
+const (
+	WrapSquare WrapType = iota
+	WrapTight
+	WrapTopAndBottom
+	WrapBehindText
+	WrapInFrontOfText
+)
+
+// Floating places an image relative to its paragraph. Align is "left",
+// "center" or "right" of the column; without it the image is X twentieths of
+// a point from the left of the column. It is Y twentieths of a point below
+// the top of the paragraph and Distance away from the text around it.
+type Floating struct {
+	Wrap     WrapType
+	Align    string
+	X, Y     int
+	Distance int
+}
+
+// imageSource is the drawing an image was read from, written again as long as
+// the image is not changed.
+type imageSource struct {
+	xml           string
+	data, svg     []byte
+	width, height int
+	altText       string
+	title         string
+}
+
+func (*Paragraph) block()  {}
+func (*Run) inline()       {}
+func (*Break) inline()     {}
+func (*Image) inline()     {}
+func (*Field) inline()     {}
+func (*RawInline) inline() {}
+
+func newParagraph(text string) *Paragraph {
+	paragraph := &Paragraph{}
+	if text != "" {
+		paragraph.AddRun(text)
+	}
+	return paragraph
+}
+
+// Style sets the paragraph style.
+func (p *Paragraph) Style(styleID string) *Paragraph {
+	p.Properties.Style = styleID
+	return p
+}
+
+// ListItem makes the paragraph an item of list at level, from 0 for the
+// first level.
+func (p *Paragraph) ListItem(list *List, level int) *Paragraph {
+	p.Properties.List = list.ID
+	p.Properties.ListLevel = level
+	return p
+}
+
+// Align sets the alignment of the paragraph.
+func (p *Paragraph) Align(align string) *Paragraph {
+	p.Properties.Align = align
+	return p
+}
+
+// Spacing sets the spacing around and between the lines of the paragraph.
+func (p *Paragraph) Spacing(spacing Spacing) *Paragraph {
+	p.Properties.Spacing = &spacing
+	return p
+}
+
+// LineSpacing sets the spacing between the lines of the paragraph in lines,
+// such as 1.5, keeping the spacing around it.
+func (p *Paragraph) LineSpacing(lines float64) *Paragraph {
+	spacing := Spacing{}
+	if p.Properties.Spacing != nil {
+		spacing = *p.Properties.Spacing
+	}
+	spacing.Line, spacing.LineRule = int(math.Round(lines*240)), "auto"
+	return p.Spacing(spacing)
+}
+
+// Indent sets the indentation of the paragraph.
+func (p *Paragraph) Indent(indent Indent) *Paragraph {
+	p.Properties.Indent = &indent
+	return p
+}
+
+// AddTab adds a tab stop at position with an alignment and a leader, which
+// may be empty.
+func (p *Paragraph) AddTab(position int, align, leader string) *Paragraph {
+	p.Properties.Tabs = append(p.Properties.Tabs, TabStop{Position: position, Align: align, Leader: leader})
+	return p
+}
+
+// KeepNext keeps the paragraph on the same page as the next one.
+func (p *Paragraph) KeepNext() *Paragraph {
+	p.Properties.KeepNext = true
+	return p
+}
+
+// KeepLines keeps the lines of the paragraph on the same page.
+func (p *Paragraph) KeepLines() *Paragraph {
+	p.Properties.KeepLines = true
+	return p
+}
+
+// PageBreakBefore starts the paragraph on a new page.
+func (p *Paragraph) PageBreakBefore() *Paragraph {
+	p.Properties.PageBreakBefore = true
+	return p
+}
+
+// WidowControl turns the prevention of single lines of the paragraph at the
+// top or bottom of a page on or off.
+func (p *Paragraph) WidowControl(on bool) *Paragraph {
+	p.Properties.WidowControl = &on
+	return p
+}
+
+// Borders sets the borders of the paragraph.
+func (p *Paragraph) Borders(borders *ParagraphBorders) *Paragraph {
+	p.Properties.Borders = borders
+	return p
+}
+
+// Shading sets the fill color of the paragraph.
+func (p *Paragraph) Shading(fill string) *Paragraph {
+	p.Properties.Shading = fill
+	return p
+}
+
+// AddRun appends a run of text. Line feeds and tabs in text are written as
+// line breaks and tabs.
+func (p *Paragraph) AddRun(text string) *Run {
+	run := &Run{Text: text}
+	p.Content = append(p.Content, run)
+	return run
+}
+
+// AddBreak appends a line, page or column break.
+func (p *Paragraph) AddBreak(breakType BreakType) *Break {
+	br := &Break{Type: breakType}
+	p.Content = append(p.Content, br)
+	return br
+}
+
+// AddField appends a field computed from code.
+func (p *Paragraph) AddField(code string) *Field {
+	field := &Field{Code: code}
+	p.Content = append(p.Content, field)
+	return field
+}
+
+// AddImage appends an inline picture of the image file data.
+func (p *Paragraph) AddImage(data []byte) *Image {
+	image := &Image{Data: data}
+	p.Content = append(p.Content, image)
+	return image
+}
+
+// AddSVG appends an inline picture of an SVG file, shown as the image file
+// fallback where SVG is not supported.
+func (p *Paragraph) AddSVG(svg, fallback []byte) *Image {
+	image := &Image{Data: fallback, SVG: svg}
+	p.Content = append(p.Content, image)
+	return image
+}
+
+// Text returns the text of the paragraph, with line breaks as "\n".
+func (p *Paragraph) Text() string {
+	var text strings.Builder
+	for _, inline := range p.Content {
+		switch inline := inline.(type) {
+		case *Run:
+			text.WriteString(inline.Text)
+		case *Break:
+			if inline.Type == LineBreak {
+				text.WriteByte('\n')
+			}
+		case *Field:
+			text.WriteString(inline.Result)
+		case *RawInline:
+			text.WriteString(inline.Text())
+		}
+	}
+	return text.String()
+}
+
+// Text returns the text of the markup, leaving out drawings and embedded
+// objects whose text is not part of the paragraph.
+func (r *RawInline) Text() string {
+	markup := r.XML
+	for _, name := range []string{"w:drawing", "w:pict", "w:object", "mc:AlternateContent"} {
+		for {
+			element, ok := utils.FindElement(markup, name, 0)
+			if !ok {
+				break
+			}
+			markup = markup[:element.Start] + markup[element.End:]
+		}
+	}
+	return utils.ExtractText(markup)
+}
+
+// Runs returns the runs of the paragraph.
+func (p *Paragraph) Runs() []*Run {
+	var runs []*Run
+	for _, inline := range p.Content {
+		if run, ok := inline.(*Run); ok {
+			runs = append(runs, run)
+		}
+	}
+	return runs
+}
+
+// Style sets the character style of the run.
+func (r *Run) Style(styleID string) *Run {
+	r.Properties.Style = styleID
+	return r
+}
+
+// Bold makes the run bold.
+func (r *Run) Bold() *Run {
+	r.Properties.Bold = true
+	return r
+}
+
+// Italic makes the run italic.
+func (r *Run) Italic() *Run {
+	r.Properties.Italic = true
+	return r
+}
+
+// Font sets the font of the Latin text of the run.
+func (r *Run) Font(name string) *Run {
+	r.Properties.Fonts.ASCII = name
+	return r
+}
+
+// Fonts sets the fonts of the run for each kind of script.
+func (r *Run) Fonts(fonts Fonts) *Run {
+	r.Properties.Fonts = fonts
+	return r
+}
+
+// Size sets the font size of the run in points, rounded to half points.
+func (r *Run) Size(points float64) *Run {
+	r.Properties.Size = int(math.Round(points * 2))
+	return r
+}
+
+// Color sets the color of the text of the run.
+func (r *Run) Color(color string) *Run {
+	r.Properties.Color = color
+	return r
+}
+
+// Highlight marks the run with a highlight color.
+func (r *Run) Highlight(color string) *Run {
+	r.Properties.Highlight = color
+	return r
+}
+
+// Underline underlines the run with a line style.
+func (r *Run) Underline(style string) *Run {
+	r.Properties.Underline = style
+	return r
+}
+
+// Strike draws a line through the run.
+func (r *Run) Strike() *Run {
+	r.Properties.Strike = true
+	return r
+}
+
+// DoubleStrike draws two lines through the run.
+func (r *Run) DoubleStrike() *Run {
+	r.Properties.DoubleStrike = true
+	return r
+}
+
+// Superscript raises the run above the baseline in a smaller size.
+func (r *Run) Superscript() *Run {
+	r.Properties.Superscript, r.Properties.Subscript = true, false
+	return r
+}
+
+// Subscript lowers the run below the baseline in a smaller size.
+func (r *Run) Subscript() *Run {
+	r.Properties.Subscript, r.Properties.Superscript = true, false
+	return r
+}
+
+// SmallCaps shows the lowercase letters of the run as small capitals.
+func (r *Run) SmallCaps() *Run {
+	r.Properties.SmallCaps = true
+	return r
+}
+
+// AllCaps shows the letters of the run as capitals.
+func (r *Run) AllCaps() *Run {
+	r.Properties.AllCaps = true
+	return r
+}
+
+// Spacing adds space between the characters of the run, in twentieths of a
+// point; a negative spacing condenses them.
+func (r *Run) Spacing(twips int) *Run {
+	r.Properties.Spacing = twips
+	return r
+}
+
+// Language sets the language of the text of the run.
+func (r *Run) Language(tag string) *Run {
+	r.Properties.Language = tag
+	return r
+}
+
+// Hidden hides the run.
+func (r *Run) Hidden() *Run {
+	r.Properties.Hidden = true
+	return r
+}
+
+// Size sets the size of the image in twentieths of a point.
+func (i *Image) Size(width, height int) *Image {
+	i.Width, i.Height = width, height
+	return i
+}
+
+// Alt sets the alternative text read out in place of the image.
+func (i *Image) Alt(text string) *Image {
+	i.AltText = text
+	return i
+}
+
+// Titled sets the title of the image.
+func (i *Image) Titled(title string) *Image {
+	i.Title = title
+	return i
+}
+
+// FitWidth scales the image down to at most maxWidth twentieths of a point
+// wide.
+func (i *Image) FitWidth(maxWidth int) *Image {
+	i.MaxWidth = maxWidth
+	return i
+}
+
+// Float places the image relative to its paragraph with the text wrapping
+// around it.
+func (i *Image) Float(floating Floating) *Image {
+	i.Floating = &floating
+	return i
+}
+
+// unchanged reports whether the image is still as it was read.
+func (i *Image) unchanged() bool {
+	source := i.source
+	return source != nil && bytes.Equal(source.data, i.Data) && bytes.Equal(source.svg, i.SVG) &&
+		source.width == i.Width && source.height == i.Height && source.altText == i.AltText &&
+		source.title == i.Title && i.MaxWidth == 0 && i.Floating == nil
+}
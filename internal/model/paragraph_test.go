@@ -0,0 +1,41 @@
+package model
+
+import "testing"
+
+func TestParagraph_Text(t *testing.T) {
+	paragraph := newParagraph("")
+	if len(paragraph.Content) != 0 {
+		t.Errorf("Expected an empty paragraph, got %+v", paragraph.Content)
+	}
+	paragraph.AddRun("one")
+	paragraph.AddBreak(LineBreak)
+	paragraph.AddRun("two")
+	paragraph.AddBreak(PageBreak)
+	paragraph.AddImage([]byte("data")).Size(1440, 720).Alt("Logo")
+
+	if text := paragraph.Text(); text != "one\ntwo" {
+		t.Errorf("Text() = %q, want %q", text, "one\ntwo")
+	}
+	if runs := paragraph.Runs(); len(runs) != 2 {
+		t.Errorf("Expected 2 runs, got %d", len(runs))
+	}
+	image, ok := paragraph.Content[4].(*Image)
+	if !ok || image.Width != 1440 || image.Height != 720 || image.AltText != "Logo" {
+		t.Errorf("Unexpected image %+v", paragraph.Content[4])
+	}
+}
+
+func TestRun_Style(t *testing.T) {
+	run := newParagraph("").AddRun("link").Style("Hyperlink")
+	if run.Properties != (RunProperties{Style: "Hyperlink"}) {
+		t.Errorf("Unexpected run properties %+v", run.Properties)
+	}
+}
+
+func TestRun_Formatting(t *testing.T) {
+	run := newParagraph("").AddRun("H2O").Font("Arial").Size(10.5).Superscript().Subscript().Underline("wave")
+	expected := RunProperties{Fonts: Fonts{ASCII: "Arial"}, Size: 21, Subscript: true, Underline: "wave"}
+	if run.Properties != expected {
+		t.Errorf("Expected %+v, got %+v", expected, run.Properties)
+	}
+}
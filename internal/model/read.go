@@ -0,0 +1,643 @@
+package model
+
+import (
+	"errors"
+	"io"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/aliamerj/docxer/internal/docxerr"
+	"github.com/aliamerj/docxer/internal/opc"
+	"github.com/aliamerj/docxer/internal/template"
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+const (
+	headerRelType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/header"
+	footerRelType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer"
+
+	// emuPerTwip converts the English Metric Units of DrawingML to
+	// twentieths of a point.
+	emuPerTwip = 635
+)
+
+// Open reads the DOCX file at filePath into a document.
+func Open(filePath string) (*Document, error) {
+	pkg, err := opc.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return read(filePath, pkg)
+}
+
+// Read reads a DOCX package of size bytes from r into a document.
+func Read(r io.ReaderAt, size int64) (*Document, error) {
+	pkg, err := opc.Read(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return read("", pkg)
+}
+
+func read(filePath string, pkg *opc.Package) (*Document, error) {
+	documentPath := pkg.Related("", template.OfficeDocumentRelType)
+	if documentPath == "" {
+		return nil, &docxerr.InvalidPackageError{Path: filePath, Err: errors.New("no main document part")}
+	}
+	content, _ := pkg.Part(documentPath)
+	body, ok := utils.FindElement(string(content), "w:body", 0)
+	if !ok {
+		return nil, &docxerr.InvalidPackageError{Path: filePath, Part: documentPath, Err: errors.New("no w:body element")}
+	}
+
+	document := string(content)
+	doc := &Document{source: &source{
+		pkg:          pkg,
+		documentPath: documentPath,
+		start:        document[:body.Start] + openTag(body.StartTag(document)),
+		end:          "</w:body>" + document[body.End:],
+	}}
+	doc.Sections = (&reader{pkg: pkg, part: documentPath}).body(body.Inner(document))
+
+	stories := map[string]*HeaderFooter{}
+	for _, relationship := range pkg.Relationships(documentPath) {
+		if relationship.External || relationship.Type != headerRelType && relationship.Type != footerRelType {
+			continue
+		}
+		name := utils.ResolveTarget(documentPath, relationship.Target)
+		part, _ := pkg.Part(name)
+		content := string(part)
+		children := utils.Children(content)
+		if len(children) == 0 || stories[name] != nil {
+			continue
+		}
+		root := children[0]
+		story := &HeaderFooter{
+			Part:   name,
+			Blocks: (&reader{pkg: pkg, part: name}).blocks(root.Inner(content)),
+			footer: relationship.Type == footerRelType,
+			start:  content[:root.Start] + openTag(root.StartTag(content)),
+			end:    "</" + root.Name(content) + ">" + content[root.End:],
+		}
+		stories[name] = story
+		if story.footer {
+			doc.Footers = append(doc.Footers, story)
+		} else {
+			doc.Headers = append(doc.Headers, story)
+		}
+	}
+
+	for _, section := range doc.Sections {
+		section.readHeaders = readReferences(pkg, documentPath, section.properties, "w:headerReference", stories)
+		section.readFooters = readReferences(pkg, documentPath, section.properties, "w:footerReference", stories)
+		section.Headers = maps.Clone(section.readHeaders)
+		section.Footers = maps.Clone(section.readFooters)
+		section.readPage = readPageSetup(section.properties)
+		section.Page = section.readPage
+	}
+	return doc, nil
+}
+
+// headerFooterTypes are the values of the w:type attribute of header and
+// footer references.
+var headerFooterTypes = map[string]HeaderFooterType{"default": DefaultPages, "first": FirstPage, "even": EvenPages}
+
+// readReferences returns the headers or footers a w:sectPr element refers to
+// with elements called name.
+func readReferences(pkg *opc.Package, documentPath, sectPr, name string, stories map[string]*HeaderFooter) map[HeaderFooterType]*HeaderFooter {
+	var references map[HeaderFooterType]*HeaderFooter
+	for _, element := range utils.FindElements(sectPr, name) {
+		tag := element.StartTag(sectPr)
+		pages, _ := utils.Attr(tag, "w:type")
+		rID, _ := utils.Attr(tag, "r:id")
+		story := stories[pkg.Target(documentPath, rID)]
+		if story == nil {
+			continue
+		}
+		if references == nil {
+			references = map[HeaderFooterType]*HeaderFooter{}
+		}
+		references[headerFooterTypes[pages]] = story
+	}
+	return references
+}
+
+// readPageSetup reads the page setup of a w:sectPr element. Columns of
+// different widths are not modeled.
+func readPageSetup(sectPr string) PageSetup {
+	var page PageSetup
+	if sectPr == "" {
+		return page
+	}
+	inner := utils.Children(sectPr)[0].Inner(sectPr)
+	for _, child := range utils.Children(inner) {
+		outer := child.Outer(inner)
+		switch child.Name(inner) {
+		case "w:type":
+			for start, value := range sectionStarts {
+				if val(outer) == value {
+					page.Start = start
+				}
+			}
+		case "w:pgSz":
+			page.Size = PageSize{number(outer, "w:w"), number(outer, "w:h")}
+			if attr(outer, "w:orient") == "landscape" {
+				page.Landscape = true
+				page.Size.Width, page.Size.Height = page.Size.Height, page.Size.Width
+			}
+		case "w:pgMar":
+			page.Margins = Margins{
+				Top: number(outer, "w:top"), Right: number(outer, "w:right"),
+				Bottom: number(outer, "w:bottom"), Left: number(outer, "w:left"),
+				Header: number(outer, "w:header"), Footer: number(outer, "w:footer"),
+				Gutter: number(outer, "w:gutter"),
+			}
+		case "w:cols":
+			if child.Inner(inner) == "" && attr(outer, "w:equalWidth") != "0" {
+				page.Columns = max(number(outer, "w:num"), 1)
+				page.ColumnSpace = number(outer, "w:space")
+			}
+		}
+	}
+	return page
+}
+
+// number returns an attribute of an element that holds a number, or 0.
+func number(element, name string) int {
+	value, _ := strconv.Atoi(attr(element, name))
+	return value
+}
+
+// openTag returns a start tag that may be self-closing as an opening one.
+func openTag(tag string) string {
+	if strings.HasSuffix(tag, "/>") {
+		return strings.TrimSuffix(tag, "/>") + ">"
+	}
+	return tag
+}
+
+// reader turns the WordprocessingML of a part into the model.
+type reader struct {
+	pkg  *opc.Package
+	part string
+}
+
+// body splits the content of w:body into sections, which end with a
+// paragraph holding a w:sectPr or with the w:sectPr of the body.
+func (r *reader) body(markup string) []*Section {
+	section := &Section{}
+	sections := []*Section{section}
+	for _, child := range utils.Children(markup) {
+		switch child.Name(markup) {
+		case "w:sectPr":
+			section.properties = child.Outer(markup)
+		case "w:p":
+			paragraph, sectPr := r.paragraph(markup, child)
+			section.Blocks = append(section.Blocks, paragraph)
+			if sectPr != "" {
+				section.properties = sectPr
+				section = &Section{}
+				sections = append(sections, section)
+			}
+		default:
+			section.Blocks = append(section.Blocks, r.block(markup, child))
+		}
+	}
+	return sections
+}
+
+func (r *reader) blocks(markup string) []Block {
+	var blocks []Block
+	for _, child := range utils.Children(markup) {
+		blocks = append(blocks, r.block(markup, child))
+	}
+	return blocks
+}
+
+func (r *reader) block(markup string, element utils.XMLElement) Block {
+	switch element.Name(markup) {
+	case "w:p":
+		paragraph, _ := r.paragraph(markup, element)
+		return paragraph
+	case "w:tbl":
+		return r.table(element.Inner(markup))
+	}
+	return &RawBlock{XML: element.Outer(markup)}
+}
+
+// paragraph reads a w:p element, returning the w:sectPr it holds apart.
+func (r *reader) paragraph(markup string, element utils.XMLElement) (*Paragraph, string) {
+	paragraph := &Paragraph{start: openTag(element.StartTag(markup))}
+	var sectPr string
+	inner := element.Inner(markup)
+	for _, child := range utils.Children(inner) {
+		switch child.Name(inner) {
+		case "w:pPr":
+			paragraph.Properties, sectPr = readParagraphProperties(child.Inner(inner))
+		case "w:r":
+			paragraph.Content = append(paragraph.Content, r.run(child.Inner(inner))...)
+		default:
+			paragraph.Content = append(paragraph.Content, &RawInline{XML: child.Outer(inner)})
+		}
+	}
+	return paragraph, sectPr
+}
+
+func readParagraphProperties(markup string) (ParagraphProperties, string) {
+	var properties ParagraphProperties
+	var sectPr string
+	for _, child := range utils.Children(markup) {
+		outer := child.Outer(markup)
+		switch child.Name(markup) {
+		case "w:pStyle":
+			properties.Style = val(outer)
+		case "w:numPr":
+			inner := child.Inner(markup)
+			for _, numChild := range utils.Children(inner) {
+				switch numChild.Name(inner) {
+				case "w:numId":
+					properties.List = val(numChild.Outer(inner))
+				case "w:ilvl":
+					properties.ListLevel, _ = strconv.Atoi(val(numChild.Outer(inner)))
+				}
+			}
+		case "w:sectPr":
+			sectPr = outer
+		default:
+			if !readParagraphProperty(&properties, child.Name(markup), outer) {
+				properties.extra += outer
+			}
+		}
+	}
+	return properties, sectPr
+}
+
+// readParagraphProperty reads the formatting of a w:pPr child into
+// properties, unless the model would write it differently.
+func readParagraphProperty(properties *ParagraphProperties, name, element string) bool {
+	switch name {
+	case "w:jc":
+		if element == valMarkup(name, val(element)) {
+			properties.Align = val(element)
+			return true
+		}
+	case "w:keepNext", "w:keepLines", "w:pageBreakBefore":
+		if element != "<"+name+"/>" {
+			return false
+		}
+		switch name {
+		case "w:keepNext":
+			properties.KeepNext = true
+		case "w:keepLines":
+			properties.KeepLines = true
+		default:
+			properties.PageBreakBefore = true
+		}
+		return true
+	case "w:widowControl":
+		on := isOn(element)
+		if element == widowControlMarkup(on) {
+			properties.WidowControl = &on
+			return true
+		}
+	case "w:spacing":
+		spacing := Spacing{Before: number(element, "w:before"), After: number(element, "w:after"),
+			Line: number(element, "w:line"), LineRule: attr(element, "w:lineRule")}
+		if element == spacingMarkup(spacing) {
+			properties.Spacing = &spacing
+			return true
+		}
+	case "w:ind":
+		indent := Indent{Left: number(element, "w:left"), Right: number(element, "w:right"),
+			FirstLine: number(element, "w:firstLine"), Hanging: number(element, "w:hanging")}
+		if element == indentMarkup(indent) {
+			properties.Indent = &indent
+			return true
+		}
+	case "w:tabs":
+		var tabs []TabStop
+		for _, tab := range utils.FindElements(element, "w:tab") {
+			tag := tab.StartTag(element)
+			tabs = append(tabs, TabStop{Position: number(tag, "w:pos"), Align: attr(tag, "w:val"), Leader: attr(tag, "w:leader")})
+		}
+		if len(tabs) > 0 && element == tabsMarkup(tabs) {
+			properties.Tabs = tabs
+			return true
+		}
+	}
+	return false
+}
+
+// run reads the content of a w:r element. Text, tabs and line breaks make
+// up runs; other content splits the run and is kept in runs of its own.
+func (r *reader) run(markup string) []Inline {
+	var properties RunProperties
+	var rPr string
+	var content []Inline
+	var text strings.Builder
+	hasText := false
+	flush := func() {
+		if hasText {
+			content = append(content, &Run{Text: text.String(), Properties: properties})
+			text.Reset()
+			hasText = false
+		}
+	}
+
+	for _, child := range utils.Children(markup) {
+		outer := child.Outer(markup)
+		switch child.Name(markup) {
+		case "w:rPr":
+			rPr = outer
+			properties = readRunProperties(child.Inner(markup))
+			continue
+		case "w:t":
+			text.WriteString(utils.UnescapeXML(child.Inner(markup)))
+			hasText = true
+			continue
+		case "w:tab":
+			text.WriteByte('\t')
+			hasText = true
+			continue
+		case "w:cr":
+			text.WriteByte('\n')
+			hasText = true
+			continue
+		case "w:lastRenderedPageBreak":
+			// Word recomputes where pages broke when it last laid out the text.
+			continue
+		case "w:br":
+			tag := child.StartTag(markup)
+			breakType, _ := utils.Attr(tag, "w:type")
+			if _, clear := utils.Attr(tag, "w:clear"); !clear {
+				switch breakType {
+				case "", "textWrapping":
+					text.WriteByte('\n')
+					hasText = true
+					continue
+				case "page":
+					flush()
+					content = append(content, &Break{Type: PageBreak})
+					continue
+				case "column":
+					flush()
+					content = append(content, &Break{Type: ColumnBreak})
+					continue
+				}
+			}
+		case "w:drawing":
+			flush()
+			if image := r.image(outer, "<w:r>"+rPr+outer+"</w:r>"); image != nil {
+				content = append(content, image)
+				continue
+			}
+		}
+		flush()
+		content = append(content, &RawInline{XML: "<w:r>" + rPr + outer + "</w:r>"})
+	}
+	flush()
+	if len(content) == 0 {
+		content = append(content, &Run{Properties: properties})
+	}
+	return content
+}
+
+func readRunProperties(markup string) RunProperties {
+	var properties RunProperties
+	// The size is modeled when the size of complex scripts matches it.
+	var size, complexSize string
+	for _, child := range utils.Children(markup) {
+		switch child.Name(markup) {
+		case "w:sz":
+			size = child.Outer(markup)
+		case "w:szCs":
+			complexSize = child.Outer(markup)
+		}
+	}
+	if value, err := strconv.Atoi(val(size)); err == nil && value > 0 &&
+		size == valMarkup("w:sz", val(size)) && complexSize == valMarkup("w:szCs", val(size)) {
+		properties.Size = value
+	}
+
+	for _, child := range utils.Children(markup) {
+		outer := child.Outer(markup)
+		value := val(outer)
+		// plain reports whether the element is written as the model writes
+		// its value.
+		plain := outer == valMarkup(child.Name(markup), value)
+		switch name := child.Name(markup); {
+		case name == "w:rStyle":
+			properties.Style = value
+		case name == "w:b" && isOn(outer):
+			properties.Bold = true
+		case name == "w:i" && isOn(outer):
+			properties.Italic = true
+		case name == "w:caps" && isOn(outer):
+			properties.AllCaps = true
+		case name == "w:smallCaps" && isOn(outer):
+			properties.SmallCaps = true
+		case name == "w:strike" && isOn(outer):
+			properties.Strike = true
+		case name == "w:dstrike" && isOn(outer):
+			properties.DoubleStrike = true
+		case name == "w:vanish" && isOn(outer):
+			properties.Hidden = true
+		case name == "w:rFonts" && outer == fontsMarkup(readFonts(outer)):
+			properties.Fonts = readFonts(outer)
+		case (name == "w:sz" || name == "w:szCs") && properties.Size > 0:
+		case name == "w:color" && plain:
+			properties.Color = value
+		case name == "w:highlight" && plain:
+			properties.Highlight = value
+		case name == "w:u" && plain:
+			properties.Underline = value
+		case name == "w:spacing" && plain && number(outer, "w:val") != 0:
+			properties.Spacing = number(outer, "w:val")
+		case name == "w:vertAlign" && plain && value == "superscript":
+			properties.Superscript = true
+		case name == "w:vertAlign" && plain && value == "subscript":
+			properties.Subscript = true
+		case name == "w:lang" && plain:
+			properties.Language = value
+		default:
+			properties.extra += outer
+		}
+	}
+	return properties
+}
+
+func readFonts(element string) Fonts {
+	return Fonts{ASCII: attr(element, "w:ascii"), EastAsia: attr(element, "w:eastAsia"), ComplexScript: attr(element, "w:cs")}
+}
+
+// image reads an inline picture embedded in the package. It returns nil for
+// other drawings, which are kept as raw markup.
+func (r *reader) image(drawing, run string) *Image {
+	inline, ok := utils.FindElement(drawing, "wp:inline", 0)
+	if !ok {
+		return nil
+	}
+	blip, ok := utils.FindElement(drawing, "a:blip", inline.Start)
+	if !ok {
+		return nil
+	}
+	rID, _ := utils.Attr(blip.StartTag(drawing), "r:embed")
+	data, ok := r.pkg.Part(r.pkg.Target(r.part, rID))
+	if !ok {
+		return nil
+	}
+
+	image := &Image{Data: data}
+	if extent, ok := utils.FindElement(drawing, "wp:extent", inline.Start); ok {
+		tag := extent.StartTag(drawing)
+		cx, _ := utils.Attr(tag, "cx")
+		cy, _ := utils.Attr(tag, "cy")
+		width, _ := strconv.Atoi(cx)
+		height, _ := strconv.Atoi(cy)
+		image.Width, image.Height = width/emuPerTwip, height/emuPerTwip
+	}
+	if docPr, ok := utils.FindElement(drawing, "wp:docPr", inline.Start); ok {
+		image.AltText, _ = utils.Attr(docPr.StartTag(drawing), "descr")
+		image.Title, _ = utils.Attr(docPr.StartTag(drawing), "title")
+	}
+	if svgBlip, ok := utils.FindElement(drawing, "asvg:svgBlip", blip.Start); ok {
+		svgID, _ := utils.Attr(svgBlip.StartTag(drawing), "r:embed")
+		image.SVG, _ = r.pkg.Part(r.pkg.Target(r.part, svgID))
+	}
+	image.source = &imageSource{xml: run, data: data, svg: image.SVG, width: image.Width, height: image.Height,
+		altText: image.AltText, title: image.Title}
+	return image
+}
+
+func (r *reader) table(markup string) *Table {
+	table := &Table{}
+	for _, child := range utils.Children(markup) {
+		outer := child.Outer(markup)
+		switch child.Name(markup) {
+		case "w:tblPr":
+			table.Properties = readTableProperties(child.Inner(markup))
+		case "w:tblGrid":
+			table.grid = outer
+		case "w:tr":
+			table.Rows = append(table.Rows, r.row(markup, child))
+		default:
+			table.Rows = append(table.Rows, &Row{raw: outer})
+		}
+	}
+	return table
+}
+
+func readTableProperties(markup string) TableProperties {
+	var properties TableProperties
+	for _, child := range utils.Children(markup) {
+		outer := child.Outer(markup)
+		switch name := child.Name(markup); {
+		case name == "w:tblStyle":
+			properties.Style = val(outer)
+		case name == "w:tblW" && isWidth(outer):
+			properties.Width = readWidth(outer)
+		case name == "w:tblLayout" && attr(outer, "w:type") == "fixed":
+			properties.Fixed = true
+		default:
+			properties.extra += outer
+		}
+	}
+	return properties
+}
+
+func (r *reader) row(markup string, element utils.XMLElement) *Row {
+	row := &Row{start: openTag(element.StartTag(markup))}
+	inner := element.Inner(markup)
+	for _, child := range utils.Children(inner) {
+		outer := child.Outer(inner)
+		switch child.Name(inner) {
+		case "w:tblPrEx":
+			row.exceptions = outer
+		case "w:trPr":
+			properties := child.Inner(inner)
+			for _, property := range utils.Children(properties) {
+				if property.Name(properties) == "w:tblHeader" {
+					row.Properties.Header = isOn(property.Outer(properties))
+				} else {
+					row.Properties.extra += property.Outer(properties)
+				}
+			}
+		case "w:tc":
+			cell := &Cell{}
+			cellMarkup := child.Inner(inner)
+			for _, cellChild := range utils.Children(cellMarkup) {
+				if cellChild.Name(cellMarkup) == "w:tcPr" {
+					cell.Properties = readCellProperties(cellChild.Inner(cellMarkup))
+				} else {
+					cell.Blocks = append(cell.Blocks, r.block(cellMarkup, cellChild))
+				}
+			}
+			row.Cells = append(row.Cells, cell)
+		default:
+			row.Cells = append(row.Cells, &Cell{raw: outer})
+		}
+	}
+	return row
+}
+
+func readCellProperties(markup string) CellProperties {
+	var properties CellProperties
+	for _, child := range utils.Children(markup) {
+		outer := child.Outer(markup)
+		switch name := child.Name(markup); {
+		case name == "w:tcW" && isWidth(outer):
+			properties.Width = readWidth(outer)
+		case name == "w:gridSpan":
+			properties.Span, _ = strconv.Atoi(val(outer))
+		case name == "w:vMerge":
+			properties.Merge = MergeContinue
+			if val(outer) == "restart" {
+				properties.Merge = MergeRestart
+			}
+		case name == "w:vAlign":
+			properties.VerticalAlign = val(outer)
+		default:
+			properties.extra += outer
+		}
+	}
+	return properties
+}
+
+// widthTypes are the values of the w:type attribute of widths.
+var widthTypes = map[string]WidthType{"auto": WidthAuto, "dxa": WidthTwips, "pct": WidthPercent}
+
+// isWidth reports whether a width element holds no more than a Width does.
+func isWidth(element string) bool {
+	_, known := widthTypes[attr(element, "w:type")]
+	return known && !strings.HasSuffix(attr(element, "w:w"), "%") && strings.Count(startTag(element), "=") == 2
+}
+
+func readWidth(element string) Width {
+	value, _ := strconv.Atoi(attr(element, "w:w"))
+	return Width{Type: widthTypes[attr(element, "w:type")], Value: value}
+}
+
+// val returns the w:val attribute of an element.
+func val(element string) string {
+	return attr(element, "w:val")
+}
+
+// attr returns an attribute of an element.
+func attr(element, name string) string {
+	value, _ := utils.Attr(startTag(element), name)
+	return value
+}
+
+// isOn reports whether a toggle property such as w:b is switched on.
+func isOn(element string) bool {
+	value, ok := utils.Attr(startTag(element), "w:val")
+	return !ok || value == "1" || value == "true" || value == "on"
+}
+
+func startTag(element string) string {
+	if end := strings.IndexByte(element, '>'); end != -1 {
+		return element[:end+1]
+	}
+	return element
+}
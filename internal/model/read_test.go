@@ -0,0 +1,372 @@
+package model
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aliamerj/docxer/internal/docxerr"
+)
+
+const (
+	testDocumentStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"` +
+		` xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"` +
+		` xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"` +
+		` xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml"><w:body>`
+
+	// testBody is written the way the model writes markup, so that saving
+	// it unchanged gives the same bytes.
+	testBody = `<w:p w14:paraId="1A2B3C4D"><w:pPr><w:pStyle w:val="Title"/><w:jc w:val="center"/></w:pPr>` +
+		`<w:r><w:t xml:space="preserve">Quarterly report</w:t></w:r><w:bookmarkStart w:id="0" w:name="top"/><w:bookmarkEnd w:id="0"/></w:p>` +
+		`<w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="3"/></w:numPr></w:pPr>` +
+		`<w:r><w:rPr><w:b/><w:color w:val="FF0000"/></w:rPr><w:t xml:space="preserve">Bold</w:t><w:tab/><w:t xml:space="preserve">red </w:t></w:r>` +
+		`<w:hyperlink r:id="rId9"><w:r><w:rPr><w:rStyle w:val="Hyperlink"/></w:rPr><w:t>site</w:t></w:r></w:hyperlink></w:p>` +
+		`<w:p><w:r><w:t xml:space="preserve">Page </w:t></w:r><w:r><w:fldChar w:fldCharType="begin"/></w:r>` +
+		`<w:r><w:instrText xml:space="preserve"> PAGE </w:instrText></w:r><w:r><w:fldChar w:fldCharType="separate"/></w:r>` +
+		`<w:r><w:t xml:space="preserve">1</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r></w:p>` +
+		`<w:p><w:r><w:drawing><wp:inline><wp:extent cx="635000" cy="317500"/><wp:docPr id="5" name="Picture 5" descr="Logo"/>` +
+		`<a:graphic xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"><a:graphicData>` +
+		`<pic:pic xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture"><pic:blipFill><a:blip r:embed="rId5"/></pic:blipFill></pic:pic>` +
+		`</a:graphicData></a:graphic></wp:inline></w:drawing></w:r><w:r><w:br w:type="page"/></w:r></w:p>` +
+		`<w:p><w:pPr><w:sectPr><w:type w:val="nextPage"/><w:pgSz w:w="11906" w:h="16838"/></w:sectPr></w:pPr></w:p>` +
+		`<w:tbl><w:tblPr><w:tblStyle w:val="LightList"/><w:tblW w:w="5000" w:type="pct"/></w:tblPr>` +
+		`<w:tblGrid><w:gridCol w:w="3000"/><w:gridCol w:w="3000"/></w:tblGrid>` +
+		`<w:tr w:rsidR="00AB12CD"><w:trPr><w:tblHeader/></w:trPr><w:tc><w:tcPr><w:tcW w:w="6000" w:type="dxa"/><w:gridSpan w:val="2"/></w:tcPr>` +
+		`<w:p><w:r><w:t xml:space="preserve">Merged</w:t></w:r></w:p></w:tc></w:tr>` +
+		`<w:tr><w:tc><w:tcPr><w:tcW w:w="3000" w:type="dxa"/></w:tcPr><w:p><w:r><w:t xml:space="preserve">A</w:t></w:r></w:p></w:tc>` +
+		`<w:tc><w:tcPr><w:tcW w:w="3000" w:type="dxa"/></w:tcPr><w:p><w:r><w:t xml:space="preserve">B</w:t></w:r></w:p></w:tc></w:tr></w:tbl>` +
+		`<w:sdt><w:sdtPr><w:alias w:val="Client"/></w:sdtPr><w:sdtContent><w:p><w:r><w:t>ACME</w:t></w:r></w:p></w:sdtContent></w:sdt>` +
+		`<w:p><w:r><w:t xml:space="preserve">The end</w:t></w:r></w:p>` +
+		`<w:sectPr><w:headerReference w:type="default" r:id="rId7"/><w:pgSz w:w="12240" w:h="15840"/></w:sectPr>`
+	testDocumentEnd = `</w:body></w:document>`
+)
+
+// testPackage returns the parts of a DOCX package using most of what the
+// model reads.
+func testPackage(t *testing.T) map[string]string {
+	return map[string]string{
+		"[Content_Types].xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+			`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/><Default Extension="png" ContentType="image/png"/>` +
+			`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+			`<Override PartName="/word/header1.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"/></Types>`,
+		"_rels/.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/></Relationships>`,
+		"word/document.xml": testDocumentStart + testBody + testDocumentEnd,
+		"word/_rels/document.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+			`<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/numbering" Target="numbering.xml"/>` +
+			`<Relationship Id="rId5" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/image1.png"/>` +
+			`<Relationship Id="rId7" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header1.xml"/>` +
+			`<Relationship Id="rId9" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="https://example.com" TargetMode="External"/></Relationships>`,
+		"word/styles.xml": `<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+			`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>` +
+			`<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/></w:style></w:styles>`,
+		"word/numbering.xml": `<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+			`<w:abstractNum w:abstractNumId="0"><w:lvl w:ilvl="0"><w:start w:val="1"/><w:numFmt w:val="decimal"/><w:lvlText w:val="%1."/></w:lvl>` +
+			`<w:lvl w:ilvl="1"><w:numFmt w:val="lowerLetter"/><w:lvlText w:val="%2)"/></w:lvl></w:abstractNum>` +
+			`<w:num w:numId="3"><w:abstractNumId w:val="0"/><w:lvlOverride w:ilvl="0"><w:startOverride w:val="5"/></w:lvlOverride></w:num></w:numbering>`,
+		"word/header1.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+			`<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t xml:space="preserve">Confidential</w:t></w:r></w:p></w:hdr>`,
+		"word/media/image1.png": string(testPNG(t, 20, 10)),
+	}
+}
+
+func zipPackage(t *testing.T, parts map[string]string) []byte {
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	for name, content := range parts {
+		writer, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		writer.Write([]byte(content))
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("Failed to write the package: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func readTestPackage(t *testing.T, parts map[string]string) *Document {
+	data := zipPackage(t, parts)
+	doc, err := Read(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Read returned an error: %v", err)
+	}
+	return doc
+}
+
+func TestRead(t *testing.T) {
+	doc := readTestPackage(t, testPackage(t))
+
+	if len(doc.Sections) != 2 || len(doc.Sections[0].Blocks) != 5 || len(doc.Sections[1].Blocks) != 3 {
+		t.Fatalf("Expected sections of 5 and 3 blocks, got %d sections", len(doc.Sections))
+	}
+	paragraphs := doc.Paragraphs()
+	expected := []string{"Quarterly report", "Bold\tred site", "Page 1", "", "", "The end"}
+	if len(paragraphs) != len(expected) {
+		t.Fatalf("Expected %d paragraphs, got %d", len(expected), len(paragraphs))
+	}
+	for i, text := range expected {
+		if paragraphs[i].Text() != text {
+			t.Errorf("Expected paragraph %d to read %q, got %q", i, text, paragraphs[i].Text())
+		}
+	}
+	if paragraphs[0].Properties.Style != "Title" {
+		t.Errorf("Expected the Title style, got %q", paragraphs[0].Properties.Style)
+	}
+	if properties := paragraphs[1].Properties; properties.List != "3" || properties.ListLevel != 1 {
+		t.Errorf("Expected level 1 of list 3, got %+v", properties)
+	}
+	if run := paragraphs[1].Runs()[0]; !run.Properties.Bold || run.Properties.Italic {
+		t.Errorf("Expected a bold run, got %+v", run.Properties)
+	}
+
+	content := paragraphs[3].Content
+	if len(content) != 2 {
+		t.Fatalf("Expected an image and a break, got %d inlines", len(content))
+	}
+	image, ok := content[0].(*Image)
+	if !ok || image.Width != 1000 || image.Height != 500 || image.AltText != "Logo" || !bytes.Equal(image.Data, testPNG(t, 20, 10)) {
+		t.Errorf("Unexpected image %+v", content[0])
+	}
+	if br, ok := content[1].(*Break); !ok || br.Type != PageBreak {
+		t.Errorf("Expected a page break, got %+v", content[1])
+	}
+
+	table := doc.Tables()[0]
+	if table.Properties.Style != "LightList" || len(table.Rows) != 2 || table.Rows[0].Cells[0].Text() != "Merged" || table.Rows[1].Cells[1].Text() != "B" {
+		t.Errorf("Unexpected table %+v", table)
+	}
+	if table.Properties.Width != Percent(100) || !table.Rows[0].Properties.Header || table.Rows[1].Properties.Header {
+		t.Errorf("Expected a full width table with a header row, got %+v", table.Properties)
+	}
+	if cell := table.Rows[0].Cells[0].Properties; cell.Span != 2 || cell.Width != Twips(6000) {
+		t.Errorf("Expected a cell spanning 2 columns, got %+v", cell)
+	}
+	if raw, ok := doc.Sections[1].Blocks[1].(*RawBlock); !ok || !strings.HasPrefix(raw.XML, "<w:sdt>") {
+		t.Errorf("Expected the content control to be kept, got %+v", doc.Sections[1].Blocks[1])
+	}
+
+	if len(doc.Headers) != 1 || doc.Headers[0].Part != "word/header1.xml" || len(doc.Footers) != 0 {
+		t.Fatalf("Expected one header, got %+v", doc.Headers)
+	}
+	if header := doc.Headers[0].Blocks[0].(*Paragraph); header.Text() != "Confidential" {
+		t.Errorf("Unexpected header text %q", header.Text())
+	}
+	if doc.Sections[1].Headers[DefaultPages] != doc.Headers[0] || doc.Sections[0].Headers != nil {
+		t.Errorf("Expected the last section to show the header, got %+v", doc.Sections)
+	}
+}
+
+func TestReadRunProperties(t *testing.T) {
+	markup := `<w:rFonts w:ascii="Arial" w:hAnsi="Arial"/><w:b w:val="1"/><w:color w:val="FF0000" w:themeColor="accent1"/>` +
+		`<w:sz w:val="28"/><w:szCs w:val="28"/><w:u w:val="dotted"/><w:vertAlign w:val="subscript"/><w:lang w:val="de-DE" w:eastAsia="ja-JP"/>`
+	properties := readRunProperties(markup)
+	expected := RunProperties{Bold: true, Fonts: Fonts{ASCII: "Arial"}, Size: 28, Underline: "dotted", Subscript: true,
+		extra: `<w:color w:val="FF0000" w:themeColor="accent1"/><w:lang w:val="de-DE" w:eastAsia="ja-JP"/>`}
+	if properties != expected {
+		t.Errorf("Expected %+v, got %+v", expected, properties)
+	}
+	if written := runProperties(properties); written != "<w:rPr>"+strings.Replace(markup, `<w:b w:val="1"/>`, "<w:b/>", 1)+"</w:rPr>" {
+		t.Errorf("Expected the properties to be written as read, got %s", written)
+	}
+
+	// A size without the matching size of complex scripts stays as it is.
+	if properties := readRunProperties(`<w:rFonts w:ascii="Arial" w:hAnsi="Calibri"/><w:sz w:val="24"/>`); properties.Size != 0 || properties.Fonts != (Fonts{}) {
+		t.Errorf("Expected the fonts and size to be kept as markup, got %+v", properties)
+	}
+}
+
+func TestReadParagraphProperties(t *testing.T) {
+	markup := `<w:keepNext/><w:widowControl w:val="0"/><w:pBdr><w:top w:val="single" w:sz="4" w:space="1" w:color="auto"/></w:pBdr>` +
+		`<w:tabs><w:tab w:val="right" w:leader="dot" w:pos="9000"/></w:tabs><w:spacing w:after="200" w:line="276" w:lineRule="auto"/>` +
+		`<w:ind w:left="720" w:firstLine="360"/><w:jc w:val="both"/>`
+	properties, _ := readParagraphProperties(markup)
+	if properties.Align != "both" || !properties.KeepNext || properties.WidowControl == nil || *properties.WidowControl ||
+		*properties.Spacing != (Spacing{After: 200, Line: 276, LineRule: "auto"}) || *properties.Indent != (Indent{Left: 720, FirstLine: 360}) ||
+		len(properties.Tabs) != 1 || properties.Tabs[0] != (TabStop{Position: 9000, Align: "right", Leader: "dot"}) {
+		t.Errorf("Expected the formatting to be read, got %+v", properties)
+	}
+	if properties.extra != `<w:pBdr><w:top w:val="single" w:sz="4" w:space="1" w:color="auto"/></w:pBdr>` {
+		t.Errorf("Expected the borders to be kept as markup, got %s", properties.extra)
+	}
+	if written := paragraphProperties(properties, ""); written != "<w:pPr>"+markup+"</w:pPr>" {
+		t.Errorf("Expected the properties to be written as read, got %s", written)
+	}
+
+	// Spacing the model would write differently stays as it is.
+	if properties, _ := readParagraphProperties(`<w:spacing w:before="0" w:after="0"/>`); properties.Spacing != nil {
+		t.Errorf("Expected the spacing to be kept as markup, got %+v", properties.Spacing)
+	}
+}
+
+func TestRead_SaveUnchanged(t *testing.T) {
+	parts := testPackage(t)
+	saved := partsByName(t, readTestPackage(t, parts))
+
+	if len(saved) != len(parts) {
+		t.Errorf("Expected %d parts, got %d", len(parts), len(saved))
+	}
+	for name, content := range parts {
+		if saved[name] != content {
+			t.Errorf("Expected %s to be kept, got:\n%s\nwant:\n%s", name, saved[name], content)
+		}
+	}
+}
+
+func TestRead_SaveEdited(t *testing.T) {
+	doc := readTestPackage(t, testPackage(t))
+	title := doc.Paragraphs()[0]
+	title.Runs()[0].Text = "Annual report"
+	title.AddImage(testPNG(t, 10, 10))
+	doc.AddParagraph("Appendix").AddRun(" A").Italic()
+	header := doc.Headers[0].Blocks[0].(*Paragraph)
+	header.AddImage(testPNG(t, 10, 10))
+
+	saved := partsByName(t, doc)
+	document := saved["word/document.xml"]
+	if !strings.Contains(document, `<w:p w14:paraId="1A2B3C4D"><w:pPr><w:pStyle w:val="Title"/><w:jc w:val="center"/></w:pPr><w:r><w:t xml:space="preserve">Annual report</w:t></w:r>`) {
+		t.Errorf("Expected the edited title to keep its properties, got %s", document)
+	}
+	if !strings.Contains(document, `<wp:docPr id="6" name="Picture 6"`) || !strings.Contains(document, `r:embed="rId10"`) {
+		t.Errorf("Expected a new picture after the existing ones, got %s", document)
+	}
+	if !strings.HasSuffix(document, `<w:p><w:r><w:t xml:space="preserve">Appendix</w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t xml:space="preserve"> A</w:t></w:r></w:p>`+
+		`<w:sectPr><w:headerReference w:type="default" r:id="rId7"/><w:pgSz w:w="12240" w:h="15840"/></w:sectPr>`+testDocumentEnd) {
+		t.Errorf("Expected the paragraph to be added to the last section, got %s", document)
+	}
+	if !strings.Contains(saved["word/_rels/document.xml.rels"], `Id="rId10" Type="`+imageRelType+`" Target="media/image2.png"`) {
+		t.Errorf("Expected a relationship to a new media part, got %s", saved["word/_rels/document.xml.rels"])
+	}
+	if _, ok := saved["word/media/image2.png"]; !ok {
+		t.Error("Expected the new image to be stored next to the existing one")
+	}
+
+	headerPart := saved["word/header1.xml"]
+	if !strings.Contains(headerPart, `xmlns:r="`+relationshipsNamespace+`"`) || !strings.Contains(headerPart, `xmlns:wp="`) ||
+		!strings.Contains(headerPart, `<wp:docPr id="7"`) || !strings.Contains(headerPart, `r:embed="rId1"`) {
+		t.Errorf("Expected the header to declare the picture namespaces, got %s", headerPart)
+	}
+	if !strings.Contains(saved["word/_rels/header1.xml.rels"], `Target="media/image3.png"`) {
+		t.Errorf("Expected header relationships for its image, got %s", saved["word/_rels/header1.xml.rels"])
+	}
+
+	reopened := readTestPackage(t, saved)
+	if text := reopened.Paragraphs()[0].Text(); text != "Annual report" {
+		t.Errorf("Expected the saved title to read back, got %q", text)
+	}
+	if _, ok := reopened.Paragraphs()[0].Content[3].(*Image); !ok {
+		t.Errorf("Expected the added image to read back, got %+v", reopened.Paragraphs()[0].Content)
+	}
+}
+
+func TestRead_SaveHeadersAndFooters(t *testing.T) {
+	parts := testPackage(t)
+	parts["word/settings.xml"] = `<w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+		`<w:zoom w:percent="100"/><w:defaultTabStop w:val="720"/><w:compat/></w:settings>`
+	parts["word/_rels/document.xml.rels"] = strings.Replace(parts["word/_rels/document.xml.rels"], "</Relationships>",
+		`<Relationship Id="rId2" Type="`+settingsRelType+`" Target="settings.xml"/></Relationships>`, 1)
+	doc := readTestPackage(t, parts)
+	doc.Sections[1].AddFooter(EvenPages).AddParagraph("Even")
+	doc.Sections[0].Headers = map[HeaderFooterType]*HeaderFooter{FirstPage: doc.Headers[0]}
+
+	saved := partsByName(t, doc)
+	document := saved["word/document.xml"]
+	for _, expected := range []string{
+		`<w:sectPr><w:headerReference w:type="first" r:id="rId7"/><w:type w:val="nextPage"/><w:pgSz w:w="11906" w:h="16838"/><w:titlePg/></w:sectPr>`,
+		`<w:sectPr><w:headerReference w:type="default" r:id="rId7"/><w:footerReference w:type="even" r:id="rId10"/><w:pgSz w:w="12240" w:h="15840"/></w:sectPr>`,
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("Expected '%s' in '%s'", expected, document)
+		}
+	}
+	if saved["word/header1.xml"] != parts["word/header1.xml"] || !strings.Contains(saved["word/footer1.xml"], "Even") {
+		t.Errorf("Expected the header to be kept and a new footer, got '%s'", saved["word/footer1.xml"])
+	}
+	if settings := saved["word/settings.xml"]; !strings.Contains(settings, `<w:defaultTabStop w:val="720"/><w:evenAndOddHeaders/><w:compat/>`) {
+		t.Errorf("Expected the settings to enable even page headers, got '%s'", settings)
+	}
+}
+
+func TestRead_SavePageSetup(t *testing.T) {
+	doc := readTestPackage(t, testPackage(t))
+	if page := doc.Sections[0].Page; page != (PageSetup{Size: A4}) {
+		t.Errorf("Expected an A4 section, got %+v", page)
+	}
+	if page := doc.Sections[1].Page; page != (PageSetup{Size: Letter}) {
+		t.Errorf("Expected a Letter section, got %+v", page)
+	}
+	doc.Sections[0].Start(StartOddPage)
+	doc.Sections[1].Landscape().Margins(Margins{Top: 720, Right: 720, Bottom: 720, Left: 720})
+
+	document := partsByName(t, doc)["word/document.xml"]
+	for _, expected := range []string{
+		`<w:sectPr><w:type w:val="oddPage"/><w:pgSz w:w="11906" w:h="16838"/></w:sectPr>`,
+		`<w:sectPr><w:headerReference w:type="default" r:id="rId7"/><w:pgSz w:w="15840" w:h="12240" w:orient="landscape"/>` +
+			`<w:pgMar w:top="720" w:right="720" w:bottom="720" w:left="720" w:header="0" w:footer="0" w:gutter="0"/></w:sectPr>`,
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("Expected '%s' in '%s'", expected, document)
+		}
+	}
+
+	reopened := readTestPackage(t, partsByName(t, doc))
+	if page := reopened.Sections[1].Page; !page.Landscape || page.Size != Letter || page.Margins.Left != 720 {
+		t.Errorf("Expected the landscape section to read back, got %+v", page)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "report.docx")
+	if err := os.WriteFile(filePath, zipPackage(t, testPackage(t)), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	doc, err := Open(filePath)
+	if err != nil {
+		t.Fatalf("Open returned an error: %v", err)
+	}
+	if err := doc.Save(filePath); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+	if doc, err = Open(filePath); err != nil || doc.Paragraphs()[0].Text() != "Quarterly report" {
+		t.Errorf("Expected the saved document to open again, got %v", err)
+	}
+}
+
+func TestOpen_Errors(t *testing.T) {
+	dir := t.TempDir()
+	notZip := filepath.Join(dir, "notes.docx")
+	if err := os.WriteFile(notZip, []byte("plain text"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	if _, err := Open(notZip); !errors.Is(err, docxerr.ErrInvalidPackage) {
+		t.Errorf("Expected ErrInvalidPackage, got %v", err)
+	}
+
+	parts := testPackage(t)
+	delete(parts, "_rels/.rels")
+	data := zipPackage(t, parts)
+	if _, err := Read(bytes.NewReader(data), int64(len(data))); !errors.Is(err, docxerr.ErrInvalidPackage) {
+		t.Errorf("Expected ErrInvalidPackage without a main document, got %v", err)
+	}
+
+	parts = testPackage(t)
+	parts["word/document.xml"] = testDocumentStart[:strings.Index(testDocumentStart, "<w:body>")] + "</w:document>"
+	data = zipPackage(t, parts)
+	_, err := Read(bytes.NewReader(data), int64(len(data)))
+	var packageErr *docxerr.InvalidPackageError
+	if !errors.As(err, &packageErr) || packageErr.Part != "word/document.xml" {
+		t.Errorf("Expected an InvalidPackageError for the document part, got %v", err)
+	}
+}
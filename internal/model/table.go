@@ -0,0 +1,332 @@
+package model
+
+// Table is a grid of cells. Style is a table style id; new tables use
+// "TableGrid", which draws all borders, and "GridTable4-Accent1" has a
+// heading row and banded rows.
+type Table struct {
+	Properties TableProperties
+	Rows       []*Row
+
+	// grid is the w:tblGrid element the table was read with.
+	grid string
+}
+
+// TableProperties is the formatting of a table. Columns are the widths of
+// the columns of a new table; columns without one share the width the others
+// leave. Fixed keeps the widths instead of fitting them to the content. Look
+// selects the parts of the table style that apply.
+//
+// The borders and padding of a table that was read are kept as they were
+// unless Borders or Padding are set.
+type TableProperties struct {
+	Style   string
+	Width   Width
+	Columns []Width
+	Fixed   bool
+	Borders *Borders
+	Padding *Padding
+	Look    *TableLook
+
+	extra string
+}
+
+// Row is a row of a table.
+type Row struct {
+	Cells      []*Cell
+	Properties RowProperties
+
+	// start and exceptions are the start tag and the w:tblPrEx element the
+	// row was read with. A row read from markup that is not a w:tr, such as
+	// a content control holding rows, keeps it in raw.
+	start, exceptions, raw string
+}
+
+// RowProperties is the formatting of a row. Header rows are repeated at the
+// top of every page the table spans.
+type RowProperties struct {
+	Header bool
+
+	extra string
+}
+
+// Cell is a cell of a table, holding paragraphs and nested tables.
+type Cell struct {
+	Blocks     []Block
+	Properties CellProperties
+
+	// raw is markup read in place of a w:tc.
+	raw string
+}
+
+// CellProperties is the formatting of a cell. Span is the number of grid
+// columns the cell covers, and a cell merged with MergeContinue joins the
+// cell above it. Shading is a fill color such as "D9E2F3" and VerticalAlign
+// is "top", "center" or "bottom".
+//
+// The borders, padding and shading of a cell that was read are kept as they
+// were unless Borders, Padding or Shading are set.
+type CellProperties struct {
+	Width         Width
+	Span          int
+	Merge         VerticalMerge
+	Shading       string
+	Borders       *Borders
+	Padding       *Padding
+	VerticalAlign string
+
+	extra string
+}
+
+// WidthType is the unit of a Width.
+type WidthType int
+
+const (
+	// WidthUnset leaves the width to the table.
+	WidthUnset WidthType = iota
+	// WidthAuto fits the width to the content.
+	WidthAuto
+	// WidthTwips is a width in twentieths of a point.
+	WidthTwips
+	// WidthPercent is a share of the width of the page or of the table, in
+	// fiftieths of a percent.
+	WidthPercent
+)
+
+// Width is the width of a table, column or cell.
+type Width struct {
+	Type  WidthType
+	Value int
+}
+
+// Twips returns a width of twentieths of a point.
+func Twips(twips int) Width {
+	return Width{Type: WidthTwips, Value: twips}
+}
+
+// Percent returns a width of percent of the available width.
+func Percent(percent float64) Width {
+	return Width{Type: WidthPercent, Value: int(percent*50 + 0.5)}
+}
+
+// AutoWidth fits a table, column or cell to its content.
+var AutoWidth = Width{Type: WidthAuto}
+
+// VerticalMerge is how a cell is merged with the cells above and below it.
+type VerticalMerge int
+
+const (
+	NoMerge VerticalMerge = iota
+	// MergeRestart starts a merged cell, which the cells below it continue.
+	MergeRestart
+	// MergeContinue joins the cell to the merged cell above it.
+	MergeContinue
+)
+
+// Border is a line along a side of a table or cell. Style is a line style
+// such as "single", "double", "dashed" or "none", Size is in eighths of a
+// point and Color is a color such as "4472C4" or "auto".
+type Border struct {
+	Style string
+	Size  int
+	Color string
+}
+
+// Borders are the borders of a table or cell. InsideH and InsideV are drawn
+// between rows and between columns. Sides with no Style are left to the
+// table style.
+type Borders struct {
+	Top, Left, Bottom, Right, InsideH, InsideV Border
+}
+
+// AllBorders returns borders drawing border on every side and between all
+// cells.
+func AllBorders(border Border) *Borders {
+	return &Borders{border, border, border, border, border, border}
+}
+
+// Padding is the space between the borders of cells and their content, in
+// twentieths of a point.
+type Padding struct {
+	Top, Left, Bottom, Right int
+}
+
+// TableLook selects the conditional formatting of the table style that
+// applies: that of the first and last row and column, and the banding of
+// rows and columns.
+type TableLook struct {
+	FirstRow, LastRow, FirstColumn, LastColumn bool
+	BandedRows, BandedColumns                  bool
+}
+
+func (*Table) block() {}
+
+// defaultTableProperties sizes new tables to their content with the usual
+// conditional formatting of the table style.
+const defaultTableProperties = `<w:tblW w:w="0" w:type="auto"/><w:tblLook w:val="04A0" w:firstRow="1" w:lastRow="0" w:firstColumn="1" w:lastColumn="0" w:noHBand="0" w:noVBand="1"/>`
+
+func newTable() *Table {
+	return &Table{Properties: TableProperties{Style: "TableGrid", extra: defaultTableProperties}}
+}
+
+// Style sets the table style.
+func (t *Table) Style(styleID string) *Table {
+	t.Properties.Style = styleID
+	return t
+}
+
+// Width sets the width of the table.
+func (t *Table) Width(width Width) *Table {
+	t.Properties.Width = width
+	return t
+}
+
+// ColumnWidths sets the widths of the columns of the table.
+func (t *Table) ColumnWidths(widths ...Width) *Table {
+	t.Properties.Columns = widths
+	return t
+}
+
+// Fixed keeps the column widths of the table instead of fitting them to the
+// content.
+func (t *Table) Fixed() *Table {
+	t.Properties.Fixed = true
+	return t
+}
+
+// Borders sets the borders of the table.
+func (t *Table) Borders(borders *Borders) *Table {
+	t.Properties.Borders = borders
+	return t
+}
+
+// Padding sets the padding of the cells of the table.
+func (t *Table) Padding(padding Padding) *Table {
+	t.Properties.Padding = &padding
+	return t
+}
+
+// Look sets the conditional formatting of the table style that applies.
+func (t *Table) Look(look TableLook) *Table {
+	t.Properties.Look = &look
+	return t
+}
+
+// AddRow appends a row with a cell for each text.
+func (t *Table) AddRow(texts ...string) *Row {
+	row := &Row{}
+	for _, text := range texts {
+		row.AddCell(text)
+	}
+	t.Rows = append(t.Rows, row)
+	return row
+}
+
+// AddHeaderRow appends a row with a cell for each text that is repeated at
+// the top of every page.
+func (t *Table) AddHeaderRow(texts ...string) *Row {
+	return t.AddRow(texts...).Header()
+}
+
+// Columns returns the number of columns of the table: the grid columns the
+// cells of its widest row span.
+func (t *Table) Columns() int {
+	columns := 0
+	for _, row := range t.Rows {
+		columns = max(columns, row.columns())
+	}
+	return columns
+}
+
+// Header repeats the row at the top of every page the table spans.
+func (r *Row) Header() *Row {
+	r.Properties.Header = true
+	return r
+}
+
+// AddCell appends a cell holding a paragraph of text.
+func (r *Row) AddCell(text string) *Cell {
+	cell := &Cell{}
+	cell.AddParagraph(text)
+	r.Cells = append(r.Cells, cell)
+	return cell
+}
+
+func (r *Row) columns() int {
+	columns := 0
+	for _, cell := range r.Cells {
+		columns += max(cell.Properties.Span, 1)
+	}
+	return columns
+}
+
+// AddParagraph appends a paragraph to the cell, holding text in a run unless
+// it is empty.
+func (c *Cell) AddParagraph(text string) *Paragraph {
+	paragraph := newParagraph(text)
+	c.Blocks = append(c.Blocks, paragraph)
+	return paragraph
+}
+
+// AddTable appends an empty nested table to the cell.
+func (c *Cell) AddTable() *Table {
+	table := newTable()
+	c.Blocks = append(c.Blocks, table)
+	return table
+}
+
+// Width sets the width of the cell.
+func (c *Cell) Width(width Width) *Cell {
+	c.Properties.Width = width
+	return c
+}
+
+// Span merges the cell with the cells to its right, covering columns grid
+// columns.
+func (c *Cell) Span(columns int) *Cell {
+	c.Properties.Span = columns
+	return c
+}
+
+// Merge merges the cell with the cells above or below it.
+func (c *Cell) Merge(merge VerticalMerge) *Cell {
+	c.Properties.Merge = merge
+	return c
+}
+
+// Shading sets the fill color of the cell.
+func (c *Cell) Shading(fill string) *Cell {
+	c.Properties.Shading = fill
+	return c
+}
+
+// Borders sets the borders of the cell.
+func (c *Cell) Borders(borders *Borders) *Cell {
+	c.Properties.Borders = borders
+	return c
+}
+
+// Padding sets the padding of the cell.
+func (c *Cell) Padding(padding Padding) *Cell {
+	c.Properties.Padding = &padding
+	return c
+}
+
+// VerticalAlign sets the vertical alignment of the content of the cell.
+func (c *Cell) VerticalAlign(align string) *Cell {
+	c.Properties.VerticalAlign = align
+	return c
+}
+
+// Text returns the text of the paragraphs of the cell, one per line.
+func (c *Cell) Text() string {
+	text := ""
+	for i, block := range c.Blocks {
+		if paragraph, ok := block.(*Paragraph); ok {
+			if i > 0 {
+				text += "\n"
+			}
+			text += paragraph.Text()
+		}
+	}
+	return text
+}
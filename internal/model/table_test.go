@@ -0,0 +1,28 @@
+package model
+
+import "testing"
+
+func TestTable_Builder(t *testing.T) {
+	table := New().AddTable()
+	if table.Properties.Style != "TableGrid" {
+		t.Errorf("Expected new tables to use TableGrid, got %q", table.Properties.Style)
+	}
+	table.Style("LightList").AddRow("Item", "Qty", "Price")
+	row := table.AddRow("Pen")
+	cell := row.AddCell("")
+	cell.AddParagraph("two")
+	cell.AddTable().AddRow("nested")
+
+	if table.Properties.Style != "LightList" {
+		t.Errorf("Expected the style to be set, got %q", table.Properties.Style)
+	}
+	if columns := table.Columns(); columns != 3 {
+		t.Errorf("Columns() = %d, want 3", columns)
+	}
+	if text := cell.Text(); text != "\ntwo" {
+		t.Errorf("Text() = %q, want %q", text, "\ntwo")
+	}
+	if len(cell.Blocks) != 3 {
+		t.Errorf("Expected 3 blocks in the cell, got %d", len(cell.Blocks))
+	}
+}
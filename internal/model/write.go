@@ -0,0 +1,1103 @@
+package model
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aliamerj/docxer/internal/media"
+	"github.com/aliamerj/docxer/internal/numbering"
+	"github.com/aliamerj/docxer/internal/opc"
+	"github.com/aliamerj/docxer/internal/template"
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+//go:embed template/*
+var templateFS embed.FS
+
+const (
+	stylesPath      = "word/styles.xml"
+	imageRelType    = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/image"
+	settingsRelType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/settings"
+
+	headerContentType   = "application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"
+	footerContentType   = "application/vnd.openxmlformats-officedocument.wordprocessingml.footer+xml"
+	settingsContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.settings+xml"
+
+	relationshipsNamespace = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+
+	// namespaces are the namespaces of the parts the model writes.
+	namespaces = ` xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"` +
+		` xmlns:r="` + relationshipsNamespace + `"` +
+		` xmlns:wp="` + utils.DrawingNamespace + `"`
+
+	documentStart = xmlDeclaration + `<w:document` + namespaces + `><w:body>`
+	documentEnd   = `</w:body></w:document>`
+	headerStart   = xmlDeclaration + `<w:hdr` + namespaces + `>`
+	footerStart   = xmlDeclaration + `<w:ftr` + namespaces + `>`
+	emptySettings = xmlDeclaration + `<w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"></w:settings>`
+
+	xmlDeclaration = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+	// defaultCellPadding is the left and right padding of table cells in
+	// Word's default table style.
+	defaultCellPadding = 108
+)
+
+// Save writes the document to a DOCX file at filePath, replacing it.
+func (d *Document) Save(filePath string) error {
+	pkg, err := d.pkg()
+	if err != nil {
+		return err
+	}
+	return pkg.Save(filePath)
+}
+
+// Parts returns the parts of the DOCX package of the document. An opened
+// document keeps the parts it was read with, updating the main document,
+// its headers and footers and their relationships.
+func (d *Document) Parts() ([]utils.DocxPart, error) {
+	pkg, err := d.pkg()
+	if err != nil {
+		return nil, err
+	}
+	return pkg.Parts(), nil
+}
+
+func (d *Document) pkg() (*opc.Package, error) {
+	if d.source != nil {
+		return d.sourcePackage()
+	}
+	pkg, err := template.NewPackage()
+	if err != nil {
+		return nil, err
+	}
+	styles, err := fs.ReadFile(templateFS, "template/styles.xml")
+	if err != nil {
+		return nil, err
+	}
+	pkg.SetPart(stylesPath, template.StylesContentType, styles)
+	pkg.Relate(template.DocumentPath, template.StylesRelType, stylesPath)
+
+	pkg.SetPart(template.DocumentPath, template.MainContentType, nil)
+	pkg.Relate("", template.OfficeDocumentRelType, template.DocumentPath)
+	w := &writer{pkg: pkg, nextID: 1}
+	if err := w.document(d, template.DocumentPath, documentStart, documentEnd); err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+func (d *Document) sourcePackage() (*opc.Package, error) {
+	pkg := d.source.pkg.Clone()
+	w := &writer{pkg: pkg, nextID: 1}
+	for _, part := range pkg.Parts() {
+		if utils.IsStoryPart(part.Name) {
+			w.nextID = max(w.nextID, utils.NextDrawingID(string(part.Content)))
+		}
+	}
+	if err := w.document(d, d.source.documentPath, d.source.start, d.source.end); err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+// document writes the main document part called name, with the content of
+// the body between start and end, and its headers and footers.
+func (w *writer) document(d *Document, name, start, end string) error {
+	// Headers and footers get their parts and relationships first, for the
+	// sections to refer to.
+	stories := w.addStories(d, name)
+
+	w.start(name)
+	body, err := w.body(d)
+	if err != nil {
+		return err
+	}
+	w.finish(start, body, end)
+
+	// Tables in headers and footers fill the text width of the first section.
+	if len(d.Sections) > 0 {
+		w.textWidth = d.Sections[0].page().textWidth()
+	}
+	for _, story := range stories {
+		w.start(w.storyParts[story])
+		var out strings.Builder
+		if err := w.blocks(&out, story.Blocks); err != nil {
+			return err
+		}
+		// Like a cell, a header or footer must end with a paragraph.
+		if n := len(story.Blocks); n == 0 || !isParagraph(story.Blocks[n-1]) {
+			out.WriteString("<w:p/>")
+		}
+		storyStart, storyEnd := story.start, story.end
+		if storyStart == "" {
+			storyStart, storyEnd = headerStart, "</w:hdr>"
+			if story.footer {
+				storyStart, storyEnd = footerStart, "</w:ftr>"
+			}
+		}
+		w.finish(storyStart, out.String(), storyEnd)
+	}
+
+	for _, section := range d.Sections {
+		if section.Headers[EvenPages] != nil || section.Footers[EvenPages] != nil {
+			w.enableEvenPages(name)
+			break
+		}
+	}
+	w.addLists(d, name)
+	return nil
+}
+
+// addLists adds the lists added to the document to the numbering part of
+// the main document part called name, creating the part when it is missing.
+func (w *writer) addLists(d *Document, name string) {
+	if len(d.lists) == 0 {
+		return
+	}
+	var abstractNums, nums strings.Builder
+	for _, list := range d.lists {
+		id, _ := strconv.Atoi(list.ID)
+		abstractID, _ := strconv.Atoi(list.abstractID)
+		var restart []ListLevel
+		if list.restart {
+			restart = list.Levels
+		} else {
+			abstractNums.WriteString(numbering.AbstractNum(abstractID, list.Levels))
+		}
+		nums.WriteString(numbering.Num(id, abstractID, restart))
+	}
+
+	part := w.pkg.Related(name, numbering.RelType)
+	if part == "" {
+		part = path.Join(path.Dir(name), "numbering.xml")
+		w.pkg.SetPart(part, numbering.ContentType, numbering.Part(abstractNums.String(), nums.String()))
+		w.pkg.Relate(name, numbering.RelType, part)
+		return
+	}
+	content, _ := w.pkg.Part(part)
+	w.pkg.SetPart(part, "", []byte(numbering.Add(string(content), abstractNums.String(), nums.String())))
+}
+
+// addStories returns the headers and footers of the document, relating the
+// main document part called name to them. New ones get parts of their own.
+func (w *writer) addStories(d *Document, name string) []*HeaderFooter {
+	w.storyParts = map[*HeaderFooter]string{}
+	w.storyIDs = map[*HeaderFooter]string{}
+	stories := append(append([]*HeaderFooter(nil), d.Headers...), d.Footers...)
+	for _, section := range d.Sections {
+		for _, references := range []map[HeaderFooterType]*HeaderFooter{section.Headers, section.Footers} {
+			for _, pages := range []HeaderFooterType{DefaultPages, FirstPage, EvenPages} {
+				if story := references[pages]; story != nil {
+					stories = append(stories, story)
+				}
+			}
+		}
+	}
+
+	var unique []*HeaderFooter
+	for _, story := range stories {
+		if _, ok := w.storyParts[story]; ok {
+			continue
+		}
+		relType, contentType, pattern := headerRelType, headerContentType, "header%d.xml"
+		if story.footer {
+			relType, contentType, pattern = footerRelType, footerContentType, "footer%d.xml"
+		}
+		part := story.Part
+		if part == "" {
+			part = w.pkg.NewPartName(path.Join(path.Dir(name), pattern))
+			// The part is stored right away so that the next one gets another name.
+			w.pkg.SetPart(part, contentType, nil)
+		}
+		w.storyParts[story] = part
+		w.storyIDs[story] = w.pkg.Relate(name, relType, part)
+		unique = append(unique, story)
+	}
+	return unique
+}
+
+// enableEvenPages sets the document settings of the main document part
+// called name to show different headers and footers on even pages.
+func (w *writer) enableEvenPages(name string) {
+	settingsPath := w.pkg.Related(name, settingsRelType)
+	content, _ := w.pkg.Part(settingsPath)
+	if settingsPath == "" {
+		settingsPath = path.Join(path.Dir(name), "settings.xml")
+		content = []byte(emptySettings)
+		w.pkg.SetPart(settingsPath, settingsContentType, content)
+		w.pkg.Relate(name, settingsRelType, settingsPath)
+	}
+	settings := string(content)
+	root, ok := utils.FindElement(settings, "w:settings", 0)
+	if !ok {
+		return
+	}
+	if element, ok := utils.FindElement(settings, "w:evenAndOddHeaders", root.InnerStart); ok && isOn(element.Outer(settings)) {
+		return
+	}
+	// w:evenAndOddHeaders goes after the settings the schema puts before it.
+	inner := root.Inner(settings)
+	position := 0
+	for _, child := range utils.Children(inner) {
+		if slices.Contains(settingsBeforeEvenAndOddHeaders, child.Name(inner)) {
+			position = child.End
+		}
+	}
+	inner = inner[:position] + "<w:evenAndOddHeaders/>" + inner[position:]
+	w.pkg.SetPart(settingsPath, "", []byte(settings[:root.InnerStart]+inner+settings[root.InnerEnd:]))
+}
+
+// settingsBeforeEvenAndOddHeaders are the document settings that come before
+// w:evenAndOddHeaders.
+var settingsBeforeEvenAndOddHeaders = []string{
+	"w:writeProtection", "w:view", "w:zoom", "w:removePersonalInformation", "w:removeDateAndTime",
+	"w:doNotDisplayPageBoundaries", "w:displayBackgroundShape", "w:printPostScriptOverText",
+	"w:printFractionalCharacterWidth", "w:printFormsData", "w:embedTrueTypeFonts", "w:embedSystemFonts",
+	"w:saveSubsetFonts", "w:saveFormsData", "w:mirrorMargins", "w:alignBordersAndEdges",
+	"w:bordersDoNotSurroundHeader", "w:bordersDoNotSurroundFooter", "w:gutterAtTop",
+	"w:hideSpellingErrors", "w:hideGrammaticalErrors", "w:activeWritingStyle", "w:proofState",
+	"w:formsDesign", "w:attachedTemplate", "w:linkStyles", "w:stylePaneFormatFilter",
+	"w:stylePaneSortMethod", "w:documentType", "w:mailMerge", "w:revisionView", "w:trackRevisions",
+	"w:doNotTrackMoves", "w:doNotTrackFormatting", "w:documentProtection", "w:autoFormatOverride",
+	"w:styleLockTheme", "w:styleLockQFSet", "w:defaultTabStop", "w:autoHyphenation",
+	"w:consecutiveHyphenLimit", "w:hyphenationZone", "w:doNotHyphenateCaps", "w:showEnvelope",
+	"w:summaryLength", "w:clickAndTypeStyle", "w:defaultTableStyle",
+}
+
+// writer turns the model into WordprocessingML, adding the images it shows
+// to the package with relationships from the part being written.
+type writer struct {
+	pkg    *opc.Package
+	part   string
+	nextID int
+	// pictures reports whether pictures were added to the current part.
+	pictures bool
+	// storyParts and storyIDs are the parts of the headers and footers and
+	// the ids of the relationships to them.
+	storyParts, storyIDs map[*HeaderFooter]string
+	// textWidth is the width between the margins of the current section, or
+	// inside the current cell.
+	textWidth int
+}
+
+// start begins writing the part called name.
+func (w *writer) start(name string) {
+	w.part = name
+	w.pictures = false
+}
+
+// finish stores the content written for the current part between start and
+// end, declaring the namespaces of pictures when some were added.
+func (w *writer) finish(start, content, end string) {
+	if w.pictures {
+		start = utils.EnsureNamespace(start, "r", relationshipsNamespace)
+		start = utils.EnsureNamespace(start, "wp", utils.DrawingNamespace)
+	}
+	w.pkg.SetPart(w.part, "", []byte(start+content+end))
+}
+
+func (w *writer) body(d *Document) (string, error) {
+	var body strings.Builder
+	for i, section := range d.Sections {
+		last := i == len(d.Sections)-1
+		blocks := section.Blocks
+		properties := w.sectionProperties(section)
+		w.textWidth = section.page().textWidth()
+		// A section ends with the paragraph holding its properties, except
+		// the last one whose properties end the body.
+		var final *Paragraph
+		if !last {
+			final = &Paragraph{}
+			if n := len(blocks); n > 0 {
+				if paragraph, ok := blocks[n-1].(*Paragraph); ok {
+					final, blocks = paragraph, blocks[:n-1]
+				}
+			}
+		}
+		if err := w.blocks(&body, blocks); err != nil {
+			return "", err
+		}
+		if last {
+			body.WriteString(properties)
+			continue
+		}
+		markup, err := w.paragraph(final, properties)
+		if err != nil {
+			return "", err
+		}
+		body.WriteString(markup)
+	}
+	return body.String(), nil
+}
+
+// sectionProperties returns the w:sectPr element of a section, referring to
+// its headers and footers.
+func (w *writer) sectionProperties(section *Section) string {
+	properties := section.properties
+	if properties == "" {
+		properties = "<w:sectPr></w:sectPr>"
+	}
+	page := section.page()
+	if section.properties != "" && page == section.readPage &&
+		maps.Equal(section.Headers, section.readHeaders) && maps.Equal(section.Footers, section.readFooters) {
+		return properties
+	}
+
+	var references strings.Builder
+	for _, kind := range []struct {
+		name    string
+		stories map[HeaderFooterType]*HeaderFooter
+	}{{"w:headerReference", section.Headers}, {"w:footerReference", section.Footers}} {
+		for _, pages := range []HeaderFooterType{DefaultPages, FirstPage, EvenPages} {
+			if story := kind.stories[pages]; story != nil {
+				references.WriteString("<" + kind.name + ` w:type="` + pageTypes[pages] + `" r:id="` + w.storyIDs[story] + `"/>`)
+			}
+		}
+	}
+	if section.Headers[FirstPage] != nil || section.Footers[FirstPage] != nil {
+		references.WriteString("<w:titlePg/>")
+	}
+
+	element := utils.Children(properties)[0]
+	inner := element.Inner(properties)
+	var kept strings.Builder
+	for _, child := range utils.Children(inner) {
+		if name := child.Name(inner); name != "w:headerReference" && name != "w:footerReference" {
+			kept.WriteString(child.Outer(inner))
+		}
+	}
+	properties = openTag(element.StartTag(properties)) + kept.String() + "</w:sectPr>"
+	return pageProperties(properties, page, references.String())
+}
+
+// page returns the page setup of a section, with that of new sections
+// completed by the default one.
+func (s *Section) page() PageSetup {
+	page := s.Page
+	if s.properties == "" {
+		if page.Size == (PageSize{}) {
+			page.Size = defaultPage.Size
+		}
+		if page.Margins == (Margins{}) {
+			page.Margins = defaultPage.Margins
+		}
+	}
+	return page
+}
+
+// textWidth returns the width between the margins, which tables fill.
+func (p PageSetup) textWidth() int {
+	size, margins := p.Size, p.Margins
+	if size == (PageSize{}) {
+		size = defaultPage.Size
+	}
+	if margins == (Margins{}) {
+		margins = defaultPage.Margins
+	}
+	width := size.Width
+	if p.Landscape {
+		width = size.Height
+	}
+	return max(width-margins.Left-margins.Right-margins.Gutter, 0)
+}
+
+// pageProperties returns the w:sectPr element sectPr with the page setup
+// page and the elements of references, such as header references, added.
+func pageProperties(sectPr string, page PageSetup, references string) string {
+	var modeled strings.Builder
+	modeled.WriteString(references)
+	if page.Start != StartNextPage {
+		modeled.WriteString(`<w:type w:val="` + sectionStarts[page.Start] + `"/>`)
+	}
+	if size := page.Size; size != (PageSize{}) {
+		orientation := ""
+		if page.Landscape {
+			size.Width, size.Height = size.Height, size.Width
+			orientation = ` w:orient="landscape"`
+		}
+		modeled.WriteString(fmt.Sprintf(`<w:pgSz w:w="%d" w:h="%d"%s/>`, size.Width, size.Height, orientation))
+	}
+	if margins := page.Margins; margins != (Margins{}) {
+		modeled.WriteString(fmt.Sprintf(`<w:pgMar w:top="%d" w:right="%d" w:bottom="%d" w:left="%d" w:header="%d" w:footer="%d" w:gutter="%d"/>`,
+			margins.Top, margins.Right, margins.Bottom, margins.Left, margins.Header, margins.Footer, margins.Gutter))
+	}
+	if page.Columns > 0 {
+		modeled.WriteString(`<w:cols w:num="` + strconv.Itoa(page.Columns) + `"`)
+		if page.ColumnSpace > 0 {
+			modeled.WriteString(` w:space="` + strconv.Itoa(page.ColumnSpace) + `"`)
+		}
+		modeled.WriteString("/>")
+	}
+
+	element := utils.Children(sectPr)[0]
+	inner := element.Inner(sectPr)
+	var kept strings.Builder
+	for _, child := range utils.Children(inner) {
+		outer := child.Outer(inner)
+		switch child.Name(inner) {
+		case "w:type":
+			// A section without a w:type starts on the next page.
+			if val(outer) == sectionStarts[page.Start] {
+				kept.WriteString(outer)
+			}
+		default:
+			kept.WriteString(outer)
+		}
+	}
+	ordered := utils.OrderProperties("w:sectPr", kept.String(), modeled.String())
+	ordered = strings.TrimSuffix(strings.TrimPrefix(ordered, "<w:sectPr>"), "</w:sectPr>")
+	return openTag(element.StartTag(sectPr)) + ordered + "</w:sectPr>"
+}
+
+// PageTransformer returns a transformer giving the last section of the main
+// document part the page setup page, for documents that are not written
+// with the model such as those made from templates.
+func PageTransformer(page PageSetup) utils.Transformer {
+	return utils.TransformerFunc(func(name, _ string, content []byte) ([]byte, error) {
+		if name != template.DocumentPath {
+			return content, nil
+		}
+		document := string(content)
+		body, ok := utils.FindElement(document, "w:body", 0)
+		if !ok {
+			return content, nil
+		}
+		inner := body.Inner(document)
+		children := utils.Children(inner)
+		for i := len(children) - 1; i >= 0; i-- {
+			if child := children[i]; child.Name(inner) == "w:sectPr" {
+				start, end := body.InnerStart+child.Start, body.InnerStart+child.End
+				return []byte(document[:start] + pageProperties(document[start:end], page, "") + document[end:]), nil
+			}
+		}
+		return []byte(document[:body.InnerEnd] + pageProperties("<w:sectPr></w:sectPr>", page, "") + document[body.InnerEnd:]), nil
+	})
+}
+
+// sectionStarts are the values of the w:type element of section properties.
+var sectionStarts = map[SectionStart]string{
+	StartNextPage: "nextPage", StartContinuous: "continuous", StartNextColumn: "nextColumn",
+	StartEvenPage: "evenPage", StartOddPage: "oddPage",
+}
+
+// pageTypes are the values of the w:type attribute of header and footer
+// references.
+var pageTypes = map[HeaderFooterType]string{DefaultPages: "default", FirstPage: "first", EvenPages: "even"}
+
+func (w *writer) blocks(out *strings.Builder, blocks []Block) error {
+	for _, block := range blocks {
+		var markup string
+		var err error
+		switch block := block.(type) {
+		case *Paragraph:
+			markup, err = w.paragraph(block, "")
+		case *Table:
+			markup, err = w.table(block)
+		case *RawBlock:
+			markup = block.XML
+		}
+		if err != nil {
+			return err
+		}
+		out.WriteString(markup)
+	}
+	return nil
+}
+
+func (w *writer) paragraph(p *Paragraph, sectPr string) (string, error) {
+	var out strings.Builder
+	if p.start != "" {
+		out.WriteString(p.start)
+	} else {
+		out.WriteString("<w:p>")
+	}
+	out.WriteString(paragraphProperties(p.Properties, sectPr))
+	for _, inline := range p.Content {
+		switch inline := inline.(type) {
+		case *Run:
+			out.WriteString(utils.TextRun(runProperties(inline.Properties), inline.Text))
+		case *Break:
+			out.WriteString("<w:r>" + breakMarkup(inline.Type) + "</w:r>")
+		case *Field:
+			out.WriteString(fieldMarkup(inline))
+		case *Image:
+			markup, err := w.image(inline)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(markup)
+		case *RawInline:
+			out.WriteString(inline.XML)
+		}
+	}
+	out.WriteString("</w:p>")
+	return out.String(), nil
+}
+
+func paragraphProperties(properties ParagraphProperties, sectPr string) string {
+	var modeled strings.Builder
+	if properties.Style != "" {
+		modeled.WriteString(`<w:pStyle w:val="` + utils.EscapeXML(properties.Style) + `"/>`)
+	}
+	if properties.List != "" {
+		modeled.WriteString(`<w:numPr><w:ilvl w:val="` + strconv.Itoa(properties.ListLevel) + `"/>` +
+			`<w:numId w:val="` + utils.EscapeXML(properties.List) + `"/></w:numPr>`)
+	}
+	for _, toggle := range []struct {
+		on   bool
+		name string
+	}{{properties.KeepNext, "w:keepNext"}, {properties.KeepLines, "w:keepLines"}, {properties.PageBreakBefore, "w:pageBreakBefore"}} {
+		if toggle.on {
+			modeled.WriteString("<" + toggle.name + "/>")
+		}
+	}
+	if control := properties.WidowControl; control != nil {
+		modeled.WriteString(widowControlMarkup(*control))
+	}
+	if borders := properties.Borders; borders != nil {
+		modeled.WriteString("<w:pBdr>" + borderMarkup("w:top", borders.Top, 1) + borderMarkup("w:left", borders.Left, 4) +
+			borderMarkup("w:bottom", borders.Bottom, 1) + borderMarkup("w:right", borders.Right, 4) +
+			borderMarkup("w:between", borders.Between, 1) + "</w:pBdr>")
+	}
+	if properties.Shading != "" {
+		modeled.WriteString(shadingMarkup(properties.Shading))
+	}
+	modeled.WriteString(tabsMarkup(properties.Tabs))
+	if spacing := properties.Spacing; spacing != nil {
+		modeled.WriteString(spacingMarkup(*spacing))
+	}
+	if indent := properties.Indent; indent != nil {
+		modeled.WriteString(indentMarkup(*indent))
+	}
+	modeled.WriteString(valMarkup("w:jc", properties.Align))
+	return utils.OrderProperties("w:pPr", properties.extra, modeled.String(), sectPr)
+}
+
+func widowControlMarkup(on bool) string {
+	if on {
+		return "<w:widowControl/>"
+	}
+	return `<w:widowControl w:val="0"/>`
+}
+
+func tabsMarkup(tabs []TabStop) string {
+	if len(tabs) == 0 {
+		return ""
+	}
+	var out strings.Builder
+	out.WriteString("<w:tabs>")
+	for _, tab := range tabs {
+		align := tab.Align
+		if align == "" {
+			align = "left"
+		}
+		out.WriteString(`<w:tab w:val="` + utils.EscapeXML(align) + `"`)
+		if tab.Leader != "" {
+			out.WriteString(` w:leader="` + utils.EscapeXML(tab.Leader) + `"`)
+		}
+		out.WriteString(` w:pos="` + strconv.Itoa(tab.Position) + `"/>`)
+	}
+	out.WriteString("</w:tabs>")
+	return out.String()
+}
+
+// spacingMarkup writes the spacing that is set, leaving the rest to the
+// style.
+func spacingMarkup(spacing Spacing) string {
+	markup := "<w:spacing" + intAttr("w:before", spacing.Before) + intAttr("w:after", spacing.After)
+	if spacing.Line > 0 {
+		rule := spacing.LineRule
+		if rule == "" {
+			rule = "auto"
+		}
+		markup += intAttr("w:line", spacing.Line) + ` w:lineRule="` + utils.EscapeXML(rule) + `"`
+	}
+	return markup + "/>"
+}
+
+// indentMarkup writes the indents that are set, leaving the rest to the
+// style.
+func indentMarkup(indent Indent) string {
+	markup := "<w:ind" + intAttr("w:left", indent.Left) + intAttr("w:right", indent.Right)
+	if indent.Hanging > 0 {
+		markup += intAttr("w:hanging", indent.Hanging)
+	} else {
+		markup += intAttr("w:firstLine", indent.FirstLine)
+	}
+	return markup + "/>"
+}
+
+// intAttr returns an attribute holding a number, or nothing for zero.
+func intAttr(name string, value int) string {
+	if value == 0 {
+		return ""
+	}
+	return " " + name + `="` + strconv.Itoa(value) + `"`
+}
+
+func shadingMarkup(fill string) string {
+	return `<w:shd w:val="clear" w:color="auto" w:fill="` + utils.EscapeXML(fill) + `"/>`
+}
+
+func runProperties(properties RunProperties) string {
+	var modeled strings.Builder
+	if properties.Style != "" {
+		modeled.WriteString(`<w:rStyle w:val="` + utils.EscapeXML(properties.Style) + `"/>`)
+	}
+	if properties.Bold {
+		modeled.WriteString("<w:b/>")
+	}
+	if properties.Italic {
+		modeled.WriteString("<w:i/>")
+	}
+	modeled.WriteString(fontsMarkup(properties.Fonts))
+	for _, toggle := range []struct {
+		on   bool
+		name string
+	}{
+		{properties.AllCaps, "w:caps"}, {properties.SmallCaps, "w:smallCaps"}, {properties.Strike, "w:strike"},
+		{properties.DoubleStrike, "w:dstrike"}, {properties.Hidden, "w:vanish"},
+	} {
+		if toggle.on {
+			modeled.WriteString("<" + toggle.name + "/>")
+		}
+	}
+	modeled.WriteString(valMarkup("w:color", properties.Color))
+	if properties.Spacing != 0 {
+		modeled.WriteString(valMarkup("w:spacing", strconv.Itoa(properties.Spacing)))
+	}
+	if properties.Size > 0 {
+		size := strconv.Itoa(properties.Size)
+		modeled.WriteString(valMarkup("w:sz", size) + valMarkup("w:szCs", size))
+	}
+	modeled.WriteString(valMarkup("w:highlight", properties.Highlight))
+	modeled.WriteString(valMarkup("w:u", properties.Underline))
+	switch {
+	case properties.Superscript:
+		modeled.WriteString(valMarkup("w:vertAlign", "superscript"))
+	case properties.Subscript:
+		modeled.WriteString(valMarkup("w:vertAlign", "subscript"))
+	}
+	modeled.WriteString(valMarkup("w:lang", properties.Language))
+	return utils.OrderProperties("w:rPr", properties.extra, modeled.String())
+}
+
+// valMarkup returns an element called name with a w:val attribute, or
+// nothing when value is empty.
+func valMarkup(name, value string) string {
+	if value == "" {
+		return ""
+	}
+	return "<" + name + ` w:val="` + utils.EscapeXML(value) + `"/>`
+}
+
+// fontsMarkup returns the w:rFonts element of fonts, which sets the font of
+// Latin text for both its ASCII and other characters.
+func fontsMarkup(fonts Fonts) string {
+	if fonts == (Fonts{}) {
+		return ""
+	}
+	var markup strings.Builder
+	markup.WriteString("<w:rFonts")
+	for _, font := range []struct {
+		names []string
+		value string
+	}{
+		{[]string{"w:ascii", "w:hAnsi"}, fonts.ASCII}, {[]string{"w:eastAsia"}, fonts.EastAsia}, {[]string{"w:cs"}, fonts.ComplexScript},
+	} {
+		for _, name := range font.names {
+			if font.value != "" {
+				markup.WriteString(" " + name + `="` + utils.EscapeXML(font.value) + `"`)
+			}
+		}
+	}
+	markup.WriteString("/>")
+	return markup.String()
+}
+
+// fieldMarkup writes a complex field, whose result keeps the formatting of
+// the field when Word updates it.
+func fieldMarkup(field *Field) string {
+	rPr := runProperties(field.Properties)
+	fieldChar := func(fieldCharType string) string {
+		return "<w:r>" + rPr + `<w:fldChar w:fldCharType="` + fieldCharType + `"/></w:r>`
+	}
+	markup := fieldChar("begin") +
+		"<w:r>" + rPr + `<w:instrText xml:space="preserve"> ` + utils.EscapeXML(field.Code) + " </w:instrText></w:r>" +
+		fieldChar("separate")
+	if field.Result != "" {
+		markup += utils.TextRun(rPr, field.Result)
+	}
+	return markup + fieldChar("end")
+}
+
+func breakMarkup(breakType BreakType) string {
+	switch breakType {
+	case PageBreak:
+		return `<w:br w:type="page"/>`
+	case ColumnBreak:
+		return `<w:br w:type="column"/>`
+	}
+	return "<w:br/>"
+}
+
+// image stores the image as a media part and returns the run showing it. An
+// image that was read and not changed keeps its drawing.
+func (w *writer) image(img *Image) (string, error) {
+	if img.unchanged() {
+		return img.source.xml, nil
+	}
+	info, err := media.Decode(img.Data)
+	if err != nil {
+		return "", err
+	}
+	if info.Format == "svg" {
+		return "", errors.New("unsupported image: an SVG image needs a raster fallback")
+	}
+	picture := utils.Picture{RelID: w.addMedia(img.Data, info.Format)}
+	if img.SVG != nil {
+		svgInfo, err := media.Decode(img.SVG)
+		if err != nil {
+			return "", err
+		}
+		if svgInfo.Format != "svg" {
+			return "", fmt.Errorf("unsupported image: expected SVG, got %s", svgInfo.Format)
+		}
+		// The drawing is as large as the SVG, which the fallback is scaled to.
+		info = svgInfo
+		picture.SVGRelID = w.addMedia(img.SVG, svgInfo.Format)
+	}
+	w.pictures = true
+
+	width, height := img.Width, img.Height
+	naturalWidth, naturalHeight := info.Twips()
+	switch {
+	case width == 0 && height == 0:
+		width, height = naturalWidth, naturalHeight
+	case width == 0 && naturalHeight > 0:
+		width = height * naturalWidth / naturalHeight
+	case height == 0 && naturalWidth > 0:
+		height = width * naturalHeight / naturalWidth
+	}
+	if img.MaxWidth > 0 && width > img.MaxWidth {
+		width, height = img.MaxWidth, height*img.MaxWidth/width
+	}
+
+	id := w.nextID
+	w.nextID++
+	picture.ID, picture.Name = id, "Picture "+strconv.Itoa(id)
+	picture.Description, picture.Title = img.AltText, img.Title
+	picture.Width, picture.Height = width, height
+	if floating := img.Floating; floating != nil {
+		picture.Anchor = &utils.PictureAnchor{
+			Wrap:     wrapTypes[floating.Wrap],
+			Align:    floating.Align,
+			X:        floating.X,
+			Y:        floating.Y,
+			Distance: floating.Distance,
+		}
+	}
+	return utils.PictureRun("", picture), nil
+}
+
+// wrapTypes are the wraps of utils.PictureAnchor.
+var wrapTypes = map[WrapType]string{
+	WrapSquare:        "square",
+	WrapTight:         "tight",
+	WrapTopAndBottom:  "topAndBottom",
+	WrapBehindText:    "behind",
+	WrapInFrontOfText: "inFront",
+}
+
+// addMedia stores data as a media part next to the current part and returns
+// the id of the relationship to it.
+func (w *writer) addMedia(data []byte, format string) string {
+	name := w.pkg.NewPartName(path.Join(path.Dir(w.part), "media/image%d."+format))
+	w.pkg.SetPart(name, media.ContentType(format), data)
+	return w.pkg.Relate(w.part, imageRelType, name)
+}
+
+func (w *writer) table(t *Table) (string, error) {
+	columns := t.Columns()
+	if columns == 0 && t.grid == "" {
+		return "", nil
+	}
+
+	var out strings.Builder
+	out.WriteString("<w:tbl>")
+	out.WriteString(tableProperties(t.Properties))
+	// Rows of a table that was read are not padded: its grid already fits
+	// cells spanning several columns.
+	padded := t.grid == ""
+	var grid []int
+	if padded {
+		grid = t.gridWidths(columns, w.textWidth)
+		out.WriteString("<w:tblGrid>")
+		for _, width := range grid {
+			out.WriteString(`<w:gridCol w:w="` + strconv.Itoa(width) + `"/>`)
+		}
+		out.WriteString("</w:tblGrid>")
+	} else {
+		out.WriteString(t.grid)
+	}
+
+	for _, row := range t.Rows {
+		if row.raw != "" {
+			out.WriteString(row.raw)
+			continue
+		}
+		if row.start != "" {
+			out.WriteString(row.start)
+		} else {
+			out.WriteString("<w:tr>")
+		}
+		out.WriteString(row.exceptions)
+		if row.Properties.Header {
+			out.WriteString(utils.OrderProperties("w:trPr", row.Properties.extra, "<w:tblHeader/>"))
+		} else {
+			out.WriteString(utils.OrderProperties("w:trPr", row.Properties.extra))
+		}
+		column := 0
+		for _, cell := range row.Cells {
+			if cell.raw != "" {
+				out.WriteString(cell.raw)
+				column++
+				continue
+			}
+			span := max(cell.Properties.Span, 1)
+			properties := cell.Properties
+			// Cells of a new table are as wide as the grid columns they span.
+			if padded && properties.Width.Type == WidthUnset {
+				properties.Width = Twips(sum(grid[column:min(column+span, len(grid))]))
+			}
+			// Tables in the cell fill the cell rather than the page.
+			textWidth := w.textWidth
+			if padded {
+				w.textWidth = cellTextWidth(sum(grid[column:min(column+span, len(grid))]), t.Properties.Padding, properties.Padding)
+			} else if properties.Width.Type == WidthTwips {
+				w.textWidth = cellTextWidth(properties.Width.Value, t.Properties.Padding, properties.Padding)
+			}
+			column += span
+			out.WriteString("<w:tc>" + cellProperties(properties))
+			err := w.blocks(&out, cell.Blocks)
+			w.textWidth = textWidth
+			if err != nil {
+				return "", err
+			}
+			// A cell must end with a paragraph.
+			if n := len(cell.Blocks); n == 0 || !isParagraph(cell.Blocks[n-1]) {
+				out.WriteString("<w:p/>")
+			}
+			out.WriteString("</w:tc>")
+		}
+		for ; padded && column < columns; column++ {
+			out.WriteString("<w:tc>" + cellProperties(CellProperties{Width: Twips(grid[column])}) + "<w:p/></w:tc>")
+		}
+		out.WriteString("</w:tr>")
+	}
+	out.WriteString("</w:tbl>")
+	return out.String(), nil
+}
+
+// cellTextWidth returns the width inside a cell width wide, within the
+// padding of the cell or else that of its table. Without either, Word pads
+// cells by defaultCellPadding on both sides.
+func cellTextWidth(width int, tablePadding, cellPadding *Padding) int {
+	padding := Padding{Left: defaultCellPadding, Right: defaultCellPadding}
+	if cellPadding != nil {
+		padding = *cellPadding
+	} else if tablePadding != nil {
+		padding = *tablePadding
+	}
+	return max(width-padding.Left-padding.Right, 0)
+}
+
+// gridWidths returns the widths of the grid columns of a new table. Columns
+// without a width of their own share what the others leave of the width of
+// the table, which fills textWidth unless it is set.
+func (t *Table) gridWidths(columns, textWidth int) []int {
+	total := textWidth
+	switch width := t.Properties.Width; width.Type {
+	case WidthTwips:
+		total = width.Value
+	case WidthPercent:
+		total = textWidth * width.Value / 5000
+	}
+
+	widths := make([]int, columns)
+	remaining, shared := total, 0
+	for i := range widths {
+		var width Width
+		if i < len(t.Properties.Columns) {
+			width = t.Properties.Columns[i]
+		}
+		switch width.Type {
+		case WidthTwips:
+			widths[i] = width.Value
+		case WidthPercent:
+			widths[i] = total * width.Value / 5000
+		default:
+			widths[i] = -1
+			shared++
+			continue
+		}
+		remaining -= widths[i]
+	}
+	for i, width := range widths {
+		if width == -1 {
+			widths[i] = max(remaining/shared, 0)
+		}
+	}
+	return widths
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
+
+func tableProperties(properties TableProperties) string {
+	var modeled strings.Builder
+	if properties.Style != "" {
+		modeled.WriteString(`<w:tblStyle w:val="` + utils.EscapeXML(properties.Style) + `"/>`)
+	}
+	modeled.WriteString(widthMarkup("w:tblW", properties.Width))
+	if properties.Borders != nil {
+		modeled.WriteString(bordersMarkup("w:tblBorders", *properties.Borders))
+	}
+	if properties.Fixed {
+		modeled.WriteString(`<w:tblLayout w:type="fixed"/>`)
+	}
+	if properties.Padding != nil {
+		modeled.WriteString(paddingMarkup("w:tblCellMar", *properties.Padding))
+	}
+	if look := properties.Look; look != nil {
+		modeled.WriteString(lookMarkup(*look))
+	}
+	return utils.OrderProperties("w:tblPr", properties.extra, modeled.String())
+}
+
+func cellProperties(properties CellProperties) string {
+	var modeled strings.Builder
+	modeled.WriteString(widthMarkup("w:tcW", properties.Width))
+	if properties.Span > 1 {
+		modeled.WriteString(`<w:gridSpan w:val="` + strconv.Itoa(properties.Span) + `"/>`)
+	}
+	switch properties.Merge {
+	case MergeRestart:
+		modeled.WriteString(`<w:vMerge w:val="restart"/>`)
+	case MergeContinue:
+		modeled.WriteString(`<w:vMerge/>`)
+	}
+	if properties.Borders != nil {
+		modeled.WriteString(bordersMarkup("w:tcBorders", *properties.Borders))
+	}
+	if properties.Shading != "" {
+		modeled.WriteString(shadingMarkup(properties.Shading))
+	}
+	if properties.Padding != nil {
+		modeled.WriteString(paddingMarkup("w:tcMar", *properties.Padding))
+	}
+	if properties.VerticalAlign != "" {
+		modeled.WriteString(`<w:vAlign w:val="` + utils.EscapeXML(properties.VerticalAlign) + `"/>`)
+	}
+	return utils.OrderProperties("w:tcPr", properties.extra, modeled.String())
+}
+
+func widthMarkup(name string, width Width) string {
+	var widthType string
+	switch width.Type {
+	case WidthAuto:
+		widthType = "auto"
+	case WidthTwips:
+		widthType = "dxa"
+	case WidthPercent:
+		widthType = "pct"
+	default:
+		return ""
+	}
+	return "<" + name + ` w:w="` + strconv.Itoa(width.Value) + `" w:type="` + widthType + `"/>`
+}
+
+func bordersMarkup(name string, borders Borders) string {
+	sides := []struct {
+		name   string
+		border Border
+	}{
+		{"w:top", borders.Top}, {"w:left", borders.Left}, {"w:bottom", borders.Bottom},
+		{"w:right", borders.Right}, {"w:insideH", borders.InsideH}, {"w:insideV", borders.InsideV},
+	}
+	var out strings.Builder
+	out.WriteString("<" + name + ">")
+	for _, side := range sides {
+		out.WriteString(borderMarkup(side.name, side.border, 0))
+	}
+	out.WriteString("</" + name + ">")
+	return out.String()
+}
+
+// borderMarkup writes a side of borders that is space points away from the
+// text, or nothing when the border has no style.
+func borderMarkup(name string, border Border, space int) string {
+	if border.Style == "" {
+		return ""
+	}
+	color := border.Color
+	if color == "" {
+		color = "auto"
+	}
+	return "<" + name + ` w:val="` + utils.EscapeXML(border.Style) + `" w:sz="` + strconv.Itoa(border.Size) +
+		`" w:space="` + strconv.Itoa(space) + `" w:color="` + utils.EscapeXML(color) + `"/>`
+}
+
+func paddingMarkup(name string, padding Padding) string {
+	side := func(name string, width int) string {
+		return "<" + name + ` w:w="` + strconv.Itoa(width) + `" w:type="dxa"/>`
+	}
+	return "<" + name + ">" + side("w:top", padding.Top) + side("w:left", padding.Left) +
+		side("w:bottom", padding.Bottom) + side("w:right", padding.Right) + "</" + name + ">"
+}
+
+// lookMarkup writes a w:tblLook element, whose w:val holds the same flags as
+// its other attributes for older versions of Word.
+func lookMarkup(look TableLook) string {
+	flags := []struct {
+		name string
+		mask int
+		on   bool
+	}{
+		{"w:firstRow", 0x0020, look.FirstRow},
+		{"w:lastRow", 0x0040, look.LastRow},
+		{"w:firstColumn", 0x0080, look.FirstColumn},
+		{"w:lastColumn", 0x0100, look.LastColumn},
+		{"w:noHBand", 0x0200, !look.BandedRows},
+		{"w:noVBand", 0x0400, !look.BandedColumns},
+	}
+	value := 0
+	var attributes strings.Builder
+	for _, flag := range flags {
+		if flag.on {
+			value |= flag.mask
+			attributes.WriteString(" " + flag.name + `="1"`)
+		} else {
+			attributes.WriteString(" " + flag.name + `="0"`)
+		}
+	}
+	return fmt.Sprintf(`<w:tblLook w:val="%04X"%s/>`, value, attributes.String())
+}
+
+func isParagraph(block Block) bool {
+	_, ok := block.(*Paragraph)
+	return ok
+}
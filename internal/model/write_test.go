@@ -0,0 +1,346 @@
+package model
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/aliamerj/docxer/internal/template"
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+func testPNG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func partsByName(t *testing.T, doc *Document) map[string]string {
+	parts, err := doc.Parts()
+	if err != nil {
+		t.Fatalf("Parts returned an error: %v", err)
+	}
+	byName := map[string]string{}
+	for _, part := range parts {
+		byName[part.Name] = string(part.Content)
+	}
+	return byName
+}
+
+func TestDocument_Parts(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Report & summary").Style("Heading1")
+	paragraph := doc.AddParagraph("Total:\t")
+	paragraph.AddRun("42").Bold()
+	paragraph.AddBreak(LineBreak)
+	paragraph.AddImage(testPNG(t, 20, 10)).Alt("Chart")
+	paragraph.AddImage(testPNG(t, 20, 10)).Size(600, 0)
+
+	parts := partsByName(t, doc)
+	document := parts["word/document.xml"]
+	for _, expected := range []string{
+		`<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t xml:space="preserve">Report &amp; summary</w:t></w:r></w:p>`,
+		`<w:r><w:t xml:space="preserve">Total:</w:t><w:tab/></w:r><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">42</w:t></w:r><w:r><w:br/></w:r>`,
+		`<wp:extent cx="190500" cy="95250"/><wp:docPr id="1" name="Picture 1" descr="Chart"/>`,
+		`<wp:extent cx="381000" cy="190500"/><wp:docPr id="2" name="Picture 2" descr=""/>`,
+		`<a:blip r:embed="rId2"/>`,
+		`<a:blip r:embed="rId3"/>`,
+		`<w:sectPr><w:pgSz w:w="12240" w:h="15840"/>`,
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("Expected '%s' in '%s'", expected, document)
+		}
+	}
+	if !strings.HasSuffix(document, `</w:sectPr></w:body></w:document>`) {
+		t.Errorf("Expected the section properties to end the body, got '%s'", document)
+	}
+
+	rels := parts["word/_rels/document.xml.rels"]
+	for _, expected := range []string{`Target="styles.xml"`, `Target="media/image1.png"`, `Target="media/image2.png"`} {
+		if !strings.Contains(rels, expected) {
+			t.Errorf("Expected '%s' in '%s'", expected, rels)
+		}
+	}
+	if _, ok := parts["word/media/image1.png"]; !ok {
+		t.Errorf("Expected the image to be stored")
+	}
+	contentTypes := parts[utils.ContentTypesPath]
+	if utils.ContentType(contentTypes, "word/media/image1.png") != "image/png" || utils.ContentType(contentTypes, "word/styles.xml") != template.StylesContentType {
+		t.Errorf("Expected content types for images and styles, got '%s'", contentTypes)
+	}
+	if !utils.HasStyle(parts["word/styles.xml"], "Heading1") || parts["docProps/core.xml"] == "" {
+		t.Errorf("Expected the styles and package parts")
+	}
+}
+
+func TestDocument_PartsImages(t *testing.T) {
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="96" height="48"/>`)
+	bmp := make([]byte, 54)
+	copy(bmp, "BM")
+	bmp[14], bmp[18], bmp[22] = 40, 30, 10
+
+	doc := New()
+	paragraph := doc.AddParagraph("")
+	paragraph.AddSVG(svg, testPNG(t, 20, 10)).Titled("Logo")
+	paragraph.AddImage(bmp).Float(Floating{Wrap: WrapTopAndBottom, Align: "right"})
+	paragraph.AddImage(testPNG(t, 2000, 1000)).FitWidth(1440)
+
+	parts := partsByName(t, doc)
+	document := parts["word/document.xml"]
+	for _, expected := range []string{
+		`<wp:extent cx="914400" cy="457200"/><wp:docPr id="1" name="Picture 1" descr="" title="Logo"/>`,
+		`<a:blip r:embed="rId2"><a:extLst>`,
+		`r:embed="rId3"/></a:ext></a:extLst></a:blip>`,
+		`<wp:positionH relativeFrom="column"><wp:align>right</wp:align></wp:positionH>`,
+		`<wp:extent cx="285750" cy="95250"/><wp:wrapTopAndBottom/><wp:docPr id="2" `,
+		`<wp:extent cx="914400" cy="457200"/><wp:docPr id="3" `,
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("Expected '%s' in '%s'", expected, document)
+		}
+	}
+	contentTypes := parts[utils.ContentTypesPath]
+	if utils.ContentType(contentTypes, "word/media/image2.svg") != "image/svg+xml" || utils.ContentType(contentTypes, "word/media/image3.bmp") != "image/bmp" {
+		t.Errorf("Expected content types for SVG and BMP images, got '%s'", contentTypes)
+	}
+
+	image := readTestPackage(t, parts).Paragraphs()[0].Content[0].(*Image)
+	if image.Title != "Logo" || !bytes.Equal(image.SVG, svg) {
+		t.Errorf("Expected the SVG image to read back, got %+v", image)
+	}
+
+	doc.AddParagraph("").AddImage(svg)
+	if _, err := doc.Parts(); err == nil {
+		t.Error("Expected an error for an SVG image without a fallback")
+	}
+}
+
+func TestDocument_PartsSectionsAndTables(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Portrait").Style("Title")
+	doc.AddSection().AddTable()
+	table := doc.AddTable()
+	table.AddRow("a", "b")
+	table.AddRow("c").AddCell("").AddTable().AddRow("x")
+
+	document := partsByName(t, doc)["word/document.xml"]
+	for _, expected := range []string{
+		`<w:p><w:pPr><w:pStyle w:val="Title"/><w:sectPr>`,
+		`<w:tbl><w:tblPr><w:tblStyle w:val="TableGrid"/>`,
+		`<w:tblGrid><w:gridCol w:w="4986"/><w:gridCol w:w="4986"/></w:tblGrid>`,
+		`<w:tc><w:tcPr><w:tcW w:w="4986" w:type="dxa"/></w:tcPr><w:p><w:r><w:t xml:space="preserve">c</w:t></w:r></w:p></w:tc>`,
+		`</w:tbl><w:p/></w:tc></w:tr></w:tbl>`,
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("Expected '%s' in '%s'", expected, document)
+		}
+	}
+	if strings.Count(document, "<w:sectPr>") != 2 || strings.Count(document, "<w:tbl>") != 2 {
+		t.Errorf("Expected 2 sections and 2 tables, got '%s'", document)
+	}
+}
+
+func TestDocument_PartsPageSetup(t *testing.T) {
+	doc := New()
+	doc.Sections[0].Size(A4).Margins(Margins{Top: 1440, Right: 1440, Bottom: 1440, Left: 1440, Gutter: 360})
+	doc.AddParagraph("Portrait")
+	doc.AddSection().Landscape().AddTable().AddRow("a", "b")
+	doc.AddSection().Portrait().Start(StartContinuous).Columns(2, 720).AddParagraph("Columns")
+
+	document := partsByName(t, doc)["word/document.xml"]
+	for _, expected := range []string{
+		`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440" w:header="0" w:footer="0" w:gutter="360"/></w:sectPr>`,
+		`<w:sectPr><w:pgSz w:w="16838" w:h="11906" w:orient="landscape"/><w:pgMar w:top="1440"`,
+		`<w:tblGrid><w:gridCol w:w="6799"/><w:gridCol w:w="6799"/></w:tblGrid>`,
+		`<w:sectPr><w:type w:val="continuous"/><w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440" w:header="0" w:footer="0" w:gutter="360"/><w:cols w:num="2" w:space="720"/></w:sectPr></w:body>`,
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("Expected '%s' in '%s'", expected, document)
+		}
+	}
+}
+
+func TestPageTransformer(t *testing.T) {
+	document := `<w:document><w:body><w:p/><w:sectPr><w:type w:val="nextPage" /><w:pgSz w:w="12240" w:h="15840" /><w:pgNumType w:fmt="decimal" /></w:sectPr></w:body></w:document>`
+	transformer := PageTransformer(PageSetup{Size: Legal, Landscape: true})
+	content, err := transformer.Transform(template.DocumentPath, template.MainContentType, []byte(document))
+	if err != nil {
+		t.Fatalf("Transform returned an error: %v", err)
+	}
+	expected := `<w:document><w:body><w:p/><w:sectPr><w:type w:val="nextPage" /><w:pgSz w:w="20160" w:h="12240" w:orient="landscape"/><w:pgNumType w:fmt="decimal" /></w:sectPr></w:body></w:document>`
+	if string(content) != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, content)
+	}
+
+	content, _ = transformer.Transform(template.DocumentPath, template.MainContentType, []byte(`<w:document><w:body><w:p/></w:body></w:document>`))
+	if !strings.HasSuffix(string(content), `<w:p/><w:sectPr><w:pgSz w:w="20160" w:h="12240" w:orient="landscape"/></w:sectPr></w:body></w:document>`) {
+		t.Errorf("Expected section properties to be added, got '%s'", content)
+	}
+}
+
+func TestDocument_PartsRunFormatting(t *testing.T) {
+	doc := New()
+	paragraph := doc.AddParagraph("")
+	paragraph.AddRun("Styled").Bold().Fonts(Fonts{ASCII: "Georgia", EastAsia: "MS Mincho", ComplexScript: "Arial"}).
+		Size(14).Color("1F4E79").Highlight("yellow").Underline("double").SmallCaps().Spacing(20).Language("en-GB")
+	paragraph.AddRun("2").Superscript().Strike()
+	paragraph.AddRun("hidden").Hidden().AllCaps().DoubleStrike()
+
+	document := partsByName(t, doc)["word/document.xml"]
+	for _, expected := range []string{
+		`<w:rPr><w:rFonts w:ascii="Georgia" w:hAnsi="Georgia" w:eastAsia="MS Mincho" w:cs="Arial"/><w:b/><w:smallCaps/><w:color w:val="1F4E79"/>` +
+			`<w:spacing w:val="20"/><w:sz w:val="28"/><w:szCs w:val="28"/><w:highlight w:val="yellow"/><w:u w:val="double"/><w:lang w:val="en-GB"/></w:rPr>`,
+		`<w:rPr><w:strike/><w:vertAlign w:val="superscript"/></w:rPr><w:t xml:space="preserve">2</w:t>`,
+		`<w:rPr><w:caps/><w:dstrike/><w:vanish/></w:rPr><w:t xml:space="preserve">hidden</w:t>`,
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("Expected '%s' in '%s'", expected, document)
+		}
+	}
+}
+
+func TestDocument_PartsParagraphFormatting(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Coffee\t3.50").AddTab(8500, "right", "dot").Indent(Indent{Left: 360, Hanging: 360}).KeepNext()
+	doc.AddParagraph("Menu").Align("center").Spacing(Spacing{After: 240}).LineSpacing(1.5).
+		Borders(&ParagraphBorders{Bottom: Border{Style: "single", Size: 6, Color: "auto"}}).Shading("F2F2F2")
+	doc.AddParagraph("Appendix").PageBreakBefore().KeepLines().WidowControl(false)
+
+	document := partsByName(t, doc)["word/document.xml"]
+	for _, expected := range []string{
+		`<w:pPr><w:keepNext/><w:tabs><w:tab w:val="right" w:leader="dot" w:pos="8500"/></w:tabs><w:ind w:left="360" w:hanging="360"/></w:pPr>`,
+		`<w:pPr><w:pBdr><w:bottom w:val="single" w:sz="6" w:space="1" w:color="auto"/></w:pBdr>` +
+			`<w:shd w:val="clear" w:color="auto" w:fill="F2F2F2"/><w:spacing w:after="240" w:line="360" w:lineRule="auto"/><w:jc w:val="center"/></w:pPr>`,
+		`<w:pPr><w:keepLines/><w:pageBreakBefore/><w:widowControl w:val="0"/></w:pPr>`,
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("Expected '%s' in '%s'", expected, document)
+		}
+	}
+}
+
+func TestDocument_PartsNestedTable(t *testing.T) {
+	doc := New()
+	row := doc.AddTable().AddRow("", "", "", "")
+	row.Cells[0].AddTable().AddRow("a", "b")
+	row.Cells[1].Padding(Padding{Left: 93, Right: 100}).AddTable().AddRow("c")
+
+	document := partsByName(t, doc)["word/document.xml"]
+	for _, expected := range []string{
+		`<w:tblGrid><w:gridCol w:w="2493"/><w:gridCol w:w="2493"/><w:gridCol w:w="2493"/><w:gridCol w:w="2493"/></w:tblGrid>`,
+		`<w:tblGrid><w:gridCol w:w="1138"/><w:gridCol w:w="1138"/></w:tblGrid>`,
+		`<w:tblGrid><w:gridCol w:w="2300"/></w:tblGrid>`,
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("Expected '%s' in '%s'", expected, document)
+		}
+	}
+}
+
+func TestDocument_PartsTableFormatting(t *testing.T) {
+	doc := New()
+	table := doc.AddTable().Style("GridTable4-Accent1").Width(Twips(9000)).ColumnWidths(Percent(50)).Fixed()
+	table.Borders(AllBorders(Border{Style: "single", Size: 8, Color: "4472C4"})).Padding(Padding{Left: 60, Right: 60})
+	table.Look(TableLook{FirstRow: true, BandedRows: true})
+	table.AddHeaderRow("Item", "Qty", "Price")
+	row := table.AddRow()
+	row.AddCell("Widget").Merge(MergeRestart).VerticalAlign("center")
+	row.AddCell("Total").Span(2).Shading("D9E2F3")
+	table.AddRow("").Cells[0].Merge(MergeContinue)
+
+	document := partsByName(t, doc)["word/document.xml"]
+	for _, expected := range []string{
+		`<w:tblPr><w:tblStyle w:val="GridTable4-Accent1"/><w:tblW w:w="9000" w:type="dxa"/>` +
+			`<w:tblBorders><w:top w:val="single" w:sz="8" w:space="0" w:color="4472C4"/>`,
+		`</w:tblBorders><w:tblLayout w:type="fixed"/><w:tblCellMar><w:top w:w="0" w:type="dxa"/><w:left w:w="60" w:type="dxa"/>`,
+		`<w:tblLook w:val="0420" w:firstRow="1" w:lastRow="0" w:firstColumn="0" w:lastColumn="0" w:noHBand="0" w:noVBand="1"/></w:tblPr>`,
+		`<w:tblGrid><w:gridCol w:w="4500"/><w:gridCol w:w="2250"/><w:gridCol w:w="2250"/></w:tblGrid>`,
+		`<w:tr><w:trPr><w:tblHeader/></w:trPr>`,
+		`<w:tc><w:tcPr><w:tcW w:w="4500" w:type="dxa"/><w:vMerge w:val="restart"/><w:vAlign w:val="center"/></w:tcPr>`,
+		`<w:tc><w:tcPr><w:tcW w:w="4500" w:type="dxa"/><w:gridSpan w:val="2"/><w:shd w:val="clear" w:color="auto" w:fill="D9E2F3"/></w:tcPr>`,
+		`<w:tc><w:tcPr><w:tcW w:w="4500" w:type="dxa"/><w:vMerge/></w:tcPr><w:p></w:p></w:tc>` +
+			`<w:tc><w:tcPr><w:tcW w:w="2250" w:type="dxa"/></w:tcPr><w:p/></w:tc>`,
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("Expected '%s' in '%s'", expected, document)
+		}
+	}
+	if strings.Count(document, "<w:tblW ") != 1 || strings.Count(document, "<w:tblLook ") != 1 {
+		t.Errorf("Expected the table properties to replace the defaults, got '%s'", document)
+	}
+}
+
+func TestDocument_PartsHeadersAndFooters(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Body")
+	footer := doc.AddFooter(DefaultPages).AddParagraph("Page ")
+	footer.AddField("PAGE").Result = "1"
+	footer.AddRun(" of ")
+	footer.AddField("NUMPAGES")
+	doc.AddHeader(FirstPage).AddParagraph("").AddImage(testPNG(t, 20, 10))
+	doc.AddHeader(EvenPages).AddParagraph("Even")
+	doc.AddSection().AddHeader(DefaultPages).AddParagraph("Appendix")
+
+	parts := partsByName(t, doc)
+	document := parts["word/document.xml"]
+	for _, expected := range []string{
+		`<w:p><w:pPr><w:sectPr><w:headerReference w:type="first" r:id="rId2"/><w:headerReference w:type="even" r:id="rId3"/>` +
+			`<w:footerReference w:type="default" r:id="rId4"/><w:pgSz w:w="12240" w:h="15840"/>`,
+		`w:gutter="0"/><w:titlePg/></w:sectPr></w:pPr><w:r><w:t xml:space="preserve">Body</w:t></w:r></w:p>`,
+		`<w:sectPr><w:headerReference w:type="default" r:id="rId5"/><w:pgSz `,
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("Expected '%s' in '%s'", expected, document)
+		}
+	}
+
+	footerPart := parts["word/footer1.xml"]
+	if !strings.HasPrefix(footerPart, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+"\n"+`<w:ftr xmlns:w=`) ||
+		!strings.Contains(footerPart, `<w:r><w:t xml:space="preserve">Page </w:t></w:r><w:r><w:fldChar w:fldCharType="begin"/></w:r>`+
+			`<w:r><w:instrText xml:space="preserve"> PAGE </w:instrText></w:r><w:r><w:fldChar w:fldCharType="separate"/></w:r>`+
+			`<w:r><w:t xml:space="preserve">1</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r>`) ||
+		!strings.Contains(footerPart, `<w:r><w:instrText xml:space="preserve"> NUMPAGES </w:instrText></w:r><w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r>`) {
+		t.Errorf("Expected a footer with page fields, got '%s'", footerPart)
+	}
+	if !strings.Contains(parts["word/header1.xml"], `<w:hdr `) || !strings.Contains(parts["word/_rels/header1.xml.rels"], `Target="media/image1.png"`) {
+		t.Errorf("Expected the first page header to show its image, got '%s'", parts["word/header1.xml"])
+	}
+	if !strings.Contains(parts["word/header3.xml"], "Appendix") {
+		t.Errorf("Expected the header of the second section, got '%s'", parts["word/header3.xml"])
+	}
+	contentTypes := parts[utils.ContentTypesPath]
+	if utils.ContentType(contentTypes, "word/footer1.xml") != footerContentType || utils.ContentType(contentTypes, "word/header2.xml") != headerContentType {
+		t.Errorf("Expected content types for headers and footers, got '%s'", contentTypes)
+	}
+	if !strings.Contains(parts["word/settings.xml"], "<w:evenAndOddHeaders/>") || !strings.Contains(parts["word/_rels/document.xml.rels"], `Target="settings.xml"`) {
+		t.Errorf("Expected settings enabling even page headers, got '%s'", parts["word/settings.xml"])
+	}
+	if text := doc.Sections[0].Footers[DefaultPages].Blocks[0].(*Paragraph).Text(); text != "Page 1 of " {
+		t.Errorf("Expected the footer text to show field results, got %q", text)
+	}
+}
+
+func TestDocument_Save(t *testing.T) {
+	filePath := t.TempDir() + "/built.docx"
+	doc := New()
+	doc.AddParagraph("Hello")
+	if err := doc.Save(filePath); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+	parts, err := utils.ReadDocx(filePath)
+	if err != nil {
+		t.Fatalf("ReadDocx returned an error: %v", err)
+	}
+	if i := utils.FindPart(parts, "word/document.xml"); i == -1 || !strings.Contains(string(parts[i].Content), "Hello") {
+		t.Errorf("Expected the document part to be saved")
+	}
+
+	doc.AddParagraph("").AddImage([]byte("not an image"))
+	if err := doc.Save(filePath); err == nil {
+		t.Errorf("Expected an error for an unsupported image")
+	}
+}
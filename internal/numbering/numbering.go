@@ -0,0 +1,176 @@
+// Package numbering writes the numbering part of a document, which defines
+// the lists paragraphs join through w:numPr. A list is a w:num referring to
+// a w:abstractNum that holds the formats of its levels; lists sharing an
+// abstract numbering look the same but count on their own.
+package numbering
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+const (
+	ContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml"
+	RelType     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/numbering"
+	PartName    = "word/numbering.xml"
+
+	// Levels is the number of levels of a list.
+	Levels = 9
+
+	partStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">`
+	partEnd = `</w:numbering>`
+)
+
+// Level is the numbering of a level of a list. Format is a number format
+// such as "decimal", "lowerLetter", "upperRoman" or "bullet" and Text the
+// label, in which "%1" stands for the number of the first level and so on.
+// Font is the font of the label, for bullets drawn from symbol fonts.
+type Level struct {
+	Level  int
+	Format string
+	Text   string
+	Start  int
+	Font   string
+}
+
+// Bullets returns the levels of a bulleted list, which use bullets in turn.
+// Without bullets they are "•", "◦" and "▪".
+func Bullets(bullets ...string) []Level {
+	if len(bullets) == 0 {
+		bullets = []string{"•", "◦", "▪"}
+	}
+	levels := make([]Level, Levels)
+	for i := range levels {
+		levels[i] = Level{Level: i, Format: "bullet", Text: bullets[i%len(bullets)], Start: 1}
+	}
+	return levels
+}
+
+// Decimal returns the levels of a list numbered 1., 2., 3.
+func Decimal() []Level {
+	return numbered("decimal")
+}
+
+// Letters returns the levels of a list numbered a., b., c.
+func Letters() []Level {
+	return numbered("lowerLetter")
+}
+
+// Roman returns the levels of a list numbered i., ii., iii.
+func Roman() []Level {
+	return numbered("lowerRoman")
+}
+
+// Legal returns the levels of a list numbered 1., 1.1., 1.1.1., each level
+// repeating the numbers of the levels above it.
+func Legal() []Level {
+	levels := make([]Level, Levels)
+	text := ""
+	for i := range levels {
+		text += "%" + strconv.Itoa(i+1) + "."
+		levels[i] = Level{Level: i, Format: "decimal", Text: text, Start: 1}
+	}
+	return levels
+}
+
+func numbered(format string) []Level {
+	levels := make([]Level, Levels)
+	for i := range levels {
+		levels[i] = Level{Level: i, Format: format, Text: "%" + strconv.Itoa(i+1) + ".", Start: 1}
+	}
+	return levels
+}
+
+// AbstractNum returns the w:abstractNum element with the given id defining
+// levels. Each level is indented half an inch more than the one above it.
+func AbstractNum(id int, levels []Level) string {
+	var out strings.Builder
+	out.WriteString(`<w:abstractNum w:abstractNumId="` + strconv.Itoa(id) + `"><w:multiLevelType w:val="hybridMultilevel"/>`)
+	for _, level := range levels {
+		out.WriteString(`<w:lvl w:ilvl="` + strconv.Itoa(level.Level) + `">`)
+		out.WriteString(`<w:start w:val="` + strconv.Itoa(max(level.Start, 0)) + `"/>`)
+		out.WriteString(`<w:numFmt w:val="` + utils.EscapeXML(level.Format) + `"/>`)
+		out.WriteString(`<w:lvlText w:val="` + utils.EscapeXML(level.Text) + `"/><w:lvlJc w:val="left"/>`)
+		out.WriteString(`<w:pPr><w:ind w:left="` + strconv.Itoa(720*(level.Level+1)) + `" w:hanging="360"/></w:pPr>`)
+		if level.Font != "" {
+			font := utils.EscapeXML(level.Font)
+			out.WriteString(`<w:rPr><w:rFonts w:ascii="` + font + `" w:hAnsi="` + font + `" w:hint="default"/></w:rPr>`)
+		}
+		out.WriteString(`</w:lvl>`)
+	}
+	out.WriteString(`</w:abstractNum>`)
+	return out.String()
+}
+
+// Num returns the w:num element with the given id of a list using the
+// abstract numbering abstractID. The levels of restart start over at their
+// Start instead of continuing the lists that share the abstract numbering.
+func Num(id, abstractID int, restart []Level) string {
+	var out strings.Builder
+	out.WriteString(`<w:num w:numId="` + strconv.Itoa(id) + `"><w:abstractNumId w:val="` + strconv.Itoa(abstractID) + `"/>`)
+	for _, level := range restart {
+		out.WriteString(`<w:lvlOverride w:ilvl="` + strconv.Itoa(level.Level) + `"><w:startOverride w:val="` + strconv.Itoa(max(level.Start, 0)) + `"/></w:lvlOverride>`)
+	}
+	out.WriteString(`</w:num>`)
+	return out.String()
+}
+
+// Part returns a numbering part holding the w:abstractNum elements
+// abstractNums and the w:num elements nums.
+func Part(abstractNums, nums string) []byte {
+	return []byte(partStart + abstractNums + nums + partEnd)
+}
+
+// Add adds the w:abstractNum elements abstractNums and the w:num elements
+// nums to the numbering part numbering, after the elements of their kind as
+// the schema requires.
+func Add(numbering, abstractNums, nums string) string {
+	root, ok := utils.FindElement(numbering, "w:numbering", 0)
+	if !ok {
+		return numbering
+	}
+	if root.InnerEnd == root.End {
+		tag := strings.TrimSuffix(root.StartTag(numbering), "/>") + ">"
+		return numbering[:root.Start] + tag + abstractNums + nums + partEnd + numbering[root.End:]
+	}
+	inner := root.Inner(numbering)
+	children := utils.Children(inner)
+	// after returns where the elements called name end, or where the first
+	// element called one of next begins.
+	after := func(name string, next ...string) int {
+		position := -1
+		for _, child := range children {
+			switch childName := child.Name(inner); {
+			case childName == name:
+				position = child.End
+			case position == -1 && slices.Contains(next, childName):
+				position = child.Start
+			}
+		}
+		if position == -1 {
+			return len(inner)
+		}
+		return position
+	}
+	abstractEnd := after("w:abstractNum", "w:num", "w:numIdMacAtCleanup")
+	numEnd := max(after("w:num", "w:numIdMacAtCleanup"), abstractEnd)
+	inner = inner[:abstractEnd] + abstractNums + inner[abstractEnd:numEnd] + nums + inner[numEnd:]
+	return numbering[:root.InnerStart] + inner + numbering[root.InnerEnd:]
+}
+
+// NextID returns an id greater than those in the attribute attr of the
+// elements called name of the numbering part numbering.
+func NextID(numbering, name, attr string) int {
+	next := 1
+	for _, element := range utils.FindElements(numbering, name) {
+		value, _ := utils.Attr(element.StartTag(numbering), attr)
+		if id, err := strconv.Atoi(value); err == nil {
+			next = max(next, id+1)
+		}
+	}
+	return next
+}
@@ -0,0 +1,51 @@
+package numbering
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLevels(t *testing.T) {
+	bullets := Bullets("-", "+")
+	if len(bullets) != Levels || bullets[0].Text != "-" || bullets[1].Text != "+" || bullets[2].Text != "-" || bullets[2].Format != "bullet" {
+		t.Errorf("Expected bullets used in turn, got %+v", bullets)
+	}
+	if legal := Legal(); legal[2].Text != "%1.%2.%3." || legal[2].Format != "decimal" {
+		t.Errorf("Expected legal numbering, got %+v", legal[2])
+	}
+	if roman := Roman(); roman[1].Text != "%2." || roman[1].Format != "lowerRoman" || roman[1].Start != 1 {
+		t.Errorf("Expected roman numbering, got %+v", roman[1])
+	}
+}
+
+func TestAbstractNumAndNum(t *testing.T) {
+	abstract := AbstractNum(2, []Level{{Level: 1, Format: "bullet", Text: "", Start: 1, Font: "Wingdings"}})
+	expected := `<w:abstractNum w:abstractNumId="2"><w:multiLevelType w:val="hybridMultilevel"/><w:lvl w:ilvl="1"><w:start w:val="1"/>` +
+		`<w:numFmt w:val="bullet"/><w:lvlText w:val=""/><w:lvlJc w:val="left"/><w:pPr><w:ind w:left="1440" w:hanging="360"/></w:pPr>` +
+		`<w:rPr><w:rFonts w:ascii="Wingdings" w:hAnsi="Wingdings" w:hint="default"/></w:rPr></w:lvl></w:abstractNum>`
+	if abstract != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, abstract)
+	}
+	num := Num(5, 2, []Level{{Level: 0, Start: 3}})
+	if num != `<w:num w:numId="5"><w:abstractNumId w:val="2"/><w:lvlOverride w:ilvl="0"><w:startOverride w:val="3"/></w:lvlOverride></w:num>` {
+		t.Errorf("Unexpected num '%s'", num)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	numbering := `<w:numbering xmlns:w="w"><w:abstractNum w:abstractNumId="0"/><w:num w:numId="4"/><w:numIdMacAtCleanup w:val="1"/></w:numbering>`
+	added := Add(numbering, `<w:abstractNum w:abstractNumId="1"/>`, `<w:num w:numId="5"/>`)
+	expected := `<w:numbering xmlns:w="w"><w:abstractNum w:abstractNumId="0"/><w:abstractNum w:abstractNumId="1"/><w:num w:numId="4"/><w:num w:numId="5"/><w:numIdMacAtCleanup w:val="1"/></w:numbering>`
+	if added != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, added)
+	}
+	if added := Add(`<w:numbering xmlns:w="w"/>`, `<w:abstractNum/>`, `<w:num/>`); added != `<w:numbering xmlns:w="w"><w:abstractNum/><w:num/></w:numbering>` {
+		t.Errorf("Unexpected numbering '%s'", added)
+	}
+	if next := NextID(numbering, "w:num", "w:numId"); next != 5 {
+		t.Errorf("Expected the next list id 5, got %d", next)
+	}
+	if next := NextID(string(Part("", "")), "w:abstractNum", "w:abstractNumId"); next != 1 || !strings.HasSuffix(string(Part("", "")), "</w:numbering>") {
+		t.Errorf("Expected the first id of an empty part to be 1, got %d", next)
+	}
+}
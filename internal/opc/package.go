@@ -0,0 +1,233 @@
+// Package opc is the Open Packaging Conventions layer of DOCX files: the
+// parts of a package, the relationships between them and their content
+// types. Writers add parts and relationships through a Package, which keeps
+// [Content_Types].xml and the relationships parts in step.
+package opc
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/aliamerj/docxer/internal/docxerr"
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+const (
+	RelationshipsContentType = "application/vnd.openxmlformats-package.relationships+xml"
+
+	emptyContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="` + RelationshipsContentType + `"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/></Types>`
+)
+
+// Package is a DOCX package held in memory.
+type Package struct {
+	parts []utils.DocxPart
+}
+
+// Relationship links a part, or the package itself, to a target part or an
+// external resource. Target is as written in the relationships part.
+type Relationship struct {
+	ID       string
+	Type     string
+	Target   string
+	External bool
+}
+
+// New returns a package holding only [Content_Types].xml.
+func New() *Package {
+	return &Package{parts: []utils.DocxPart{{Name: utils.ContentTypesPath, Content: []byte(emptyContentTypes)}}}
+}
+
+// FromParts returns a package of parts, which it takes ownership of.
+func FromParts(parts []utils.DocxPart) *Package {
+	return &Package{parts: parts}
+}
+
+// Open reads the DOCX file at filePath.
+func Open(filePath string) (*Package, error) {
+	parts, err := utils.ReadDocx(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return FromParts(parts), nil
+}
+
+// Read reads a DOCX package of size bytes from r.
+func Read(r io.ReaderAt, size int64) (*Package, error) {
+	parts, err := utils.ReadDocxFrom(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return FromParts(parts), nil
+}
+
+// Clone returns a copy of the package that can be changed without changing p.
+func (p *Package) Clone() *Package {
+	return &Package{parts: append([]utils.DocxPart(nil), p.parts...)}
+}
+
+// Parts returns the parts of the package in the order they were added.
+func (p *Package) Parts() []utils.DocxPart {
+	return p.parts
+}
+
+// Save writes the package to a DOCX file at filePath, replacing it.
+func (p *Package) Save(filePath string) error {
+	return utils.WriteDocx(filePath, p.parts)
+}
+
+// Part returns the content of the part called name.
+func (p *Package) Part(name string) ([]byte, bool) {
+	if i := utils.FindPart(p.parts, name); i != -1 {
+		return p.parts[i].Content, true
+	}
+	return nil, false
+}
+
+// SetPart stores content as the part called name. A non-empty contentType is
+// registered in [Content_Types].xml: as the default for the extension of the
+// name when the package has none, and as an override of the part otherwise.
+// XML parts always get an override, as parts of many types share ".xml".
+func (p *Package) SetPart(name, contentType string, content []byte) {
+	p.parts = utils.SetPart(p.parts, name, content)
+	if contentType == "" || p.ContentType(name) == contentType {
+		return
+	}
+	contentTypes := p.contentTypes()
+	extension := strings.TrimPrefix(path.Ext(name), ".")
+	if extension != "" && extension != "xml" && !hasDefault(contentTypes, extension) {
+		contentTypes = utils.AddContentTypeDefault(contentTypes, extension, contentType)
+	} else {
+		contentTypes = utils.AddContentTypeOverride(contentTypes, name, contentType)
+	}
+	p.parts = utils.SetPart(p.parts, utils.ContentTypesPath, []byte(contentTypes))
+}
+
+// ContentType returns the content type of the part called name.
+func (p *Package) ContentType(name string) string {
+	return utils.ContentType(p.contentTypes(), name)
+}
+
+func (p *Package) contentTypes() string {
+	content, ok := p.Part(utils.ContentTypesPath)
+	if !ok {
+		return emptyContentTypes
+	}
+	return string(content)
+}
+
+// NewPartName returns the first name the pattern gives with a number from 1
+// in place of its %d that no part of the package has, for example
+// "word/media/image3.png" for "word/media/image%d.png".
+func (p *Package) NewPartName(pattern string) string {
+	for n := 1; ; n++ {
+		name := fmt.Sprintf(pattern, n)
+		if _, ok := p.Part(name); !ok {
+			return name
+		}
+	}
+}
+
+// Relate returns the id of a relationship of type relType from the part
+// called source to the part called target, adding the relationship when
+// there is none yet. An empty source relates the package itself.
+func (p *Package) Relate(source, relType, target string) string {
+	return p.relate(source, relType, relativeTarget(source, target), false)
+}
+
+// RelateExternal returns the id of a relationship of type relType from the
+// part called source to an external resource such as a hyperlink URL.
+func (p *Package) RelateExternal(source, relType, url string) string {
+	return p.relate(source, relType, url, true)
+}
+
+func (p *Package) relate(source, relType, target string, external bool) string {
+	relsPath := utils.RelsPath(source)
+	rels, _ := p.Part(relsPath)
+	updated, id := utils.AddRelationship(string(rels), relType, target, external)
+	if updated != string(rels) {
+		p.parts = utils.SetPart(p.parts, relsPath, []byte(updated))
+	}
+	return id
+}
+
+// Relationships returns the relationships of the part called source, or of
+// the package for an empty source.
+func (p *Package) Relationships(source string) []Relationship {
+	content, _ := p.Part(utils.RelsPath(source))
+	rels := string(content)
+	var relationships []Relationship
+	for _, element := range utils.FindElements(rels, "Relationship") {
+		tag := element.StartTag(rels)
+		relationship := Relationship{}
+		relationship.ID, _ = utils.Attr(tag, "Id")
+		relationship.Type, _ = utils.Attr(tag, "Type")
+		relationship.Target, _ = utils.Attr(tag, "Target")
+		mode, _ := utils.Attr(tag, "TargetMode")
+		relationship.External = mode == "External"
+		relationships = append(relationships, relationship)
+	}
+	return relationships
+}
+
+// Related returns the name of the first part the part called source relates
+// to with relType, or "" when there is none.
+func (p *Package) Related(source, relType string) string {
+	for _, relationship := range p.Relationships(source) {
+		if relationship.Type == relType && !relationship.External {
+			return utils.ResolveTarget(source, relationship.Target)
+		}
+	}
+	return ""
+}
+
+// Target returns the name of the part the relationship with id rID of the
+// part called source points to, or "" for a missing or external one.
+func (p *Package) Target(source, rID string) string {
+	for _, relationship := range p.Relationships(source) {
+		if relationship.ID == rID && !relationship.External {
+			return utils.ResolveTarget(source, relationship.Target)
+		}
+	}
+	return ""
+}
+
+// Transform passes every part through transformer, with its content type.
+func (p *Package) Transform(transformer utils.Transformer) error {
+	for i, part := range p.parts {
+		content, err := transformer.Transform(part.Name, p.ContentType(part.Name), part.Content)
+		if err != nil {
+			return fmt.Errorf("error updating '%s': %w", part.Name, docxerr.InPart(err, part.Name))
+		}
+		p.parts[i].Content = content
+	}
+	return nil
+}
+
+func hasDefault(contentTypes, extension string) bool {
+	for _, element := range utils.FindElements(contentTypes, "Default") {
+		if value, _ := utils.Attr(element.StartTag(contentTypes), "Extension"); strings.EqualFold(value, extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// relativeTarget returns the target of a relationship from the part called
+// source to the part called target.
+func relativeTarget(source, target string) string {
+	from := strings.Split(path.Dir(source), "/")
+	if from[0] == "." {
+		from = nil
+	}
+	to := strings.Split(target, "/")
+	common := 0
+	for common < len(from) && common < len(to)-1 && from[common] == to[common] {
+		common++
+	}
+	return strings.Repeat("../", len(from)-common) + strings.Join(to[common:], "/")
+}
@@ -0,0 +1,119 @@
+package opc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+func TestPackage_SetPart(t *testing.T) {
+	pkg := New()
+	pkg.SetPart("word/media/image1.png", "image/png", []byte("png"))
+	pkg.SetPart("word/media/image2.png", "image/png", []byte("png"))
+	pkg.SetPart("word/styles.xml", "application/vnd.styles+xml", []byte("<w:styles/>"))
+	pkg.SetPart("word/notes.xml", "", []byte("<notes/>"))
+
+	contentTypes, _ := pkg.Part(utils.ContentTypesPath)
+	if count := strings.Count(string(contentTypes), `Extension="png"`); count != 1 {
+		t.Errorf("Expected a single png default, got %d in %s", count, contentTypes)
+	}
+	if strings.Contains(string(contentTypes), "image1.png") {
+		t.Errorf("Expected no override for a part covered by a default, got %s", contentTypes)
+	}
+	if contentType := pkg.ContentType("word/styles.xml"); contentType != "application/vnd.styles+xml" {
+		t.Errorf("Expected an override for the styles part, got %q", contentType)
+	}
+	if contentType := pkg.ContentType("word/notes.xml"); contentType != "application/xml" {
+		t.Errorf("Expected the xml default for a part without a content type, got %q", contentType)
+	}
+	if content, ok := pkg.Part("word/notes.xml"); !ok || string(content) != "<notes/>" {
+		t.Errorf("Expected the notes part, got %q", content)
+	}
+}
+
+func TestPackage_Relate(t *testing.T) {
+	pkg := New()
+	first := pkg.Relate("word/document.xml", "styles", "word/styles.xml")
+	again := pkg.Relate("word/document.xml", "styles", "word/styles.xml")
+	item := pkg.Relate("word/document.xml", "customXml", "customXml/item1.xml")
+	link := pkg.RelateExternal("word/document.xml", "hyperlink", "https://example.com")
+	root := pkg.Relate("", "officeDocument", "word/document.xml")
+
+	if first != "rId1" || again != first || item != "rId2" || link != "rId3" || root != "rId1" {
+		t.Errorf("Unexpected ids %s %s %s %s %s", first, again, item, link, root)
+	}
+	relationships := pkg.Relationships("word/document.xml")
+	if len(relationships) != 3 {
+		t.Fatalf("Expected 3 relationships, got %+v", relationships)
+	}
+	if relationships[0].Target != "styles.xml" || relationships[1].Target != "../customXml/item1.xml" {
+		t.Errorf("Expected targets relative to the part, got %+v", relationships)
+	}
+	if !relationships[2].External || relationships[2].Target != "https://example.com" {
+		t.Errorf("Unexpected external relationship %+v", relationships[2])
+	}
+	if target := pkg.Relationships("")[0].Target; target != "word/document.xml" {
+		t.Errorf("Expected a package relationship target of word/document.xml, got %q", target)
+	}
+
+	if related := pkg.Related("word/document.xml", "customXml"); related != "customXml/item1.xml" {
+		t.Errorf("Expected the custom XML part, got %q", related)
+	}
+	if target := pkg.Target("word/document.xml", "rId1"); target != "word/styles.xml" {
+		t.Errorf("Expected the styles part, got %q", target)
+	}
+	if target := pkg.Target("word/document.xml", "rId3"); target != "" {
+		t.Errorf("Expected no part for an external relationship, got %q", target)
+	}
+}
+
+func TestPackage_NewPartName(t *testing.T) {
+	pkg := New()
+	pkg.SetPart("word/media/image1.png", "image/png", nil)
+	pkg.SetPart("word/media/image2.png", "image/png", nil)
+	if name := pkg.NewPartName("word/media/image%d.png"); name != "word/media/image3.png" {
+		t.Errorf("Expected word/media/image3.png, got %s", name)
+	}
+}
+
+func TestPackage_Clone(t *testing.T) {
+	pkg := New()
+	pkg.SetPart("word/document.xml", "", []byte("original"))
+	clone := pkg.Clone()
+	clone.SetPart("word/document.xml", "", []byte("changed"))
+	clone.Relate("", "officeDocument", "word/document.xml")
+
+	if content, _ := pkg.Part("word/document.xml"); string(content) != "original" {
+		t.Errorf("Expected the original package to be unchanged, got %q", content)
+	}
+	if relationships := pkg.Relationships(""); relationships != nil {
+		t.Errorf("Expected no relationships in the original package, got %+v", relationships)
+	}
+}
+
+func TestPackage_Transform(t *testing.T) {
+	pkg := New()
+	pkg.SetPart("word/document.xml", "application/main+xml", []byte("body"))
+	err := pkg.Transform(utils.TransformerFunc(func(name, contentType string, content []byte) ([]byte, error) {
+		if name == "word/document.xml" {
+			return []byte(contentType + ":" + string(content)), nil
+		}
+		return content, nil
+	}))
+	if err != nil {
+		t.Fatalf("Transform returned an error: %v", err)
+	}
+	if content, _ := pkg.Part("word/document.xml"); string(content) != "application/main+xml:body" {
+		t.Errorf("Unexpected transformed content %q", content)
+	}
+
+	failure := errors.New("failed")
+	err = pkg.Transform(utils.TransformerFunc(func(name, contentType string, content []byte) ([]byte, error) {
+		return nil, failure
+	}))
+	if !errors.Is(err, failure) || !strings.Contains(err.Error(), utils.ContentTypesPath) {
+		t.Errorf("Expected the error to name the part, got %v", err)
+	}
+}
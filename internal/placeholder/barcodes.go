@@ -0,0 +1,131 @@
+package placeholder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aliamerj/docxer/internal/barcode"
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+// Barcode is the value of a {{qr:key}}, {{barcode:code128:key}} or
+// {{barcode:ean13:key}} placeholder. Width and Height are the size of the
+// image in twentieths of a point; when zero, QR codes are an inch square,
+// Code 128 barcodes half an inch high with a point per bar module and EAN-13
+// barcodes their nominal 37.29 by 25.93 mm. Level is the QR error correction
+// level, "L", "M" (the default), "Q" or "H".
+type Barcode struct {
+	Value  string
+	Width  int
+	Height int
+	Level  string
+}
+
+// symbologies are the placeholder prefixes of each kind of barcode.
+var symbologies = []struct {
+	prefix string
+	name   string
+}{
+	{"qr:", "QR code"},
+	{"barcode:code128:", "Code 128 barcode"},
+	{"barcode:ean13:", "EAN-13 barcode"},
+}
+
+// BarcodeWriter replaces barcode placeholders with PNG images of the codes,
+// inline where the placeholder was. The value of a key is encoded for each
+// kind of placeholder that uses it; a value that kind cannot encode, such as
+// one that is not a valid EAN-13 number for an EAN-13 placeholder, makes
+// writing the part return an error. Keys are handled in sorted order.
+func BarcodeWriter(barcodes map[string]Barcode) (PartWriter, error) {
+	keys := make([]string, 0, len(barcodes))
+	for key := range barcodes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := barcodes[key]
+		if _, err := barcode.ParseLevel(value.Level); err != nil {
+			return nil, fmt.Errorf("barcode %q: %w", key, err)
+		}
+		if value.Width < 0 || value.Height < 0 {
+			return nil, fmt.Errorf("barcode %q: negative size %dx%d", key, value.Width, value.Height)
+		}
+	}
+
+	return func(content string, rels *Relations) (string, error) {
+		firstID := utils.NextDrawingID(content)
+		id := firstID
+		for _, key := range keys {
+			value := barcodes[key]
+			for _, symbology := range symbologies {
+				placeholder := "{{" + symbology.prefix + key + "}}"
+				if !strings.Contains(content, placeholder) {
+					continue
+				}
+				image, width, height, err := value.image(symbology.prefix)
+				if err != nil {
+					return "", fmt.Errorf("barcode %q: %w", key, err)
+				}
+				rID := ""
+				content = replacePlaceholder(content, placeholder, nil, func(runProps string) string {
+					if rID == "" {
+						rID = rels.AddImage(image, "png")
+					}
+					id++
+					return utils.InlinePicture(runProps, rID, id-1, symbology.name+" "+key, value.Value, width, height)
+				})
+			}
+		}
+		if id != firstID {
+			content = utils.EnsureNamespace(content, "wp", utils.DrawingNamespace)
+		}
+		return content, nil
+	}, nil
+}
+
+// image draws the barcode for a placeholder prefix and returns the PNG data
+// with the size to show it at.
+func (b Barcode) image(prefix string) ([]byte, int, int, error) {
+	if prefix == "qr:" {
+		level, _ := barcode.ParseLevel(b.Level)
+		matrix, err := barcode.QR(b.Value, level)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		width, height := b.Width, b.Height
+		if width == 0 {
+			width = 1440
+		}
+		if height == 0 {
+			height = width
+		}
+		data, err := barcode.MatrixPNG(matrix, 8)
+		return data, width, height, err
+	}
+
+	var modules []bool
+	var err error
+	width, height := 0, 0
+	if prefix == "barcode:ean13:" {
+		modules, err = barcode.EAN13(b.Value)
+		width, height = 2115, 1470
+	} else {
+		modules, err = barcode.Code128(b.Value)
+		width, height = (len(modules)+2*barcode.LinearQuietZone)*20, 720
+	}
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if b.Width != 0 {
+		width = b.Width
+	}
+	if b.Height != 0 {
+		height = b.Height
+	}
+	// Bars three pixels per module wide, with the height in proportion.
+	const scale = 3
+	pixels := max(1, scale*(len(modules)+2*barcode.LinearQuietZone)*height/width)
+	data, err := barcode.LinearPNG(modules, scale, pixels)
+	return data, width, height, err
+}
@@ -0,0 +1,131 @@
+package placeholder
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+func TestBarcodeWriter(t *testing.T) {
+	inputContent := `<w:document xmlns:w="w"><w:body>` +
+		`<w:p><w:r><w:t>{{qr:PAYMENT_URL}}</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>SKU {{barcode:code128:SKU}}</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>{{barcode:ean13:EAN}}</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:drawing><wp:inline><wp:docPr id="7" name="Logo"/></wp:inline></w:drawing></w:r></w:p>` +
+		`</w:body></w:document>`
+	writer, err := BarcodeWriter(map[string]Barcode{
+		"PAYMENT_URL": {Value: "https://pay.example.com/inv/42", Width: 2880},
+		"SKU":         {Value: "SKU-12345678"},
+		"EAN":         {Value: "400638133393", Height: 1000},
+	})
+	if err != nil {
+		t.Fatalf("BarcodeWriter returned an error: %v", err)
+	}
+
+	rels := &Relations{}
+	outputContent, err := writer(inputContent, rels)
+	if err != nil {
+		t.Fatalf("PartWriter returned an error: %v", err)
+	}
+
+	if strings.Contains(outputContent, "{{") {
+		t.Errorf("Expected every placeholder to be replaced, got '%s'", outputContent)
+	}
+	if !strings.HasPrefix(outputContent, `<w:document xmlns:w="w" xmlns:wp="`+utils.DrawingNamespace+`">`) {
+		t.Errorf("Expected the drawing namespace to be declared, got '%s'", outputContent)
+	}
+	for _, expected := range []string{
+		`<wp:extent cx="1828800" cy="1828800"/><wp:docPr id="`,
+		`<w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">SKU </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:drawing>`,
+		`<wp:extent cx="1343025" cy="635000"/>`,
+		`<a:blip r:embed="rId3"/>`,
+	} {
+		if !strings.Contains(outputContent, expected) {
+			t.Errorf("Expected '%s' in '%s'", expected, outputContent)
+		}
+	}
+	// Drawings are numbered after the existing ones, in the order of the keys.
+	for _, drawing := range []string{`id="8" name="EAN-13 barcode EAN"`, `id="9" name="QR code PAYMENT_URL"`, `id="10" name="Code 128 barcode SKU"`} {
+		if !strings.Contains(outputContent, `<wp:docPr `+drawing) {
+			t.Errorf("Expected a drawing with %s in '%s'", drawing, outputContent)
+		}
+	}
+
+	if len(mediaParts(rels)) != 3 {
+		t.Fatalf("Expected 3 images, got %d", len(mediaParts(rels)))
+	}
+	for _, media := range mediaParts(rels) {
+		if !strings.HasPrefix(media.Name, "word/media/") || !strings.HasSuffix(media.Name, ".png") {
+			t.Errorf("Unexpected image part %s", media.Name)
+		}
+		if _, err := png.Decode(bytes.NewReader(media.Content)); err != nil {
+			t.Errorf("Expected %s to be a PNG: %v", media.Name, err)
+		}
+		target := strings.TrimPrefix(media.Name, "word/")
+		if !strings.Contains(relsContent(rels), `Type="`+ImageRelType+`" Target="`+target+`"`) {
+			t.Errorf("Expected a relationship to %s, got '%s'", target, relsContent(rels))
+		}
+	}
+}
+
+func TestBarcodeWriter_Errors(t *testing.T) {
+	if _, err := BarcodeWriter(map[string]Barcode{"QR": {Value: "x", Level: "Z"}}); err == nil {
+		t.Errorf("Expected an error for an unknown error correction level")
+	}
+	if _, err := BarcodeWriter(map[string]Barcode{"QR": {Value: "x", Width: -1}}); err == nil {
+		t.Errorf("Expected an error for a negative size")
+	}
+
+	writer, err := BarcodeWriter(map[string]Barcode{"CODE": {Value: "SKU-1"}})
+	if err != nil {
+		t.Fatalf("BarcodeWriter returned an error: %v", err)
+	}
+	// A value that is a fine Code 128 barcode is not an EAN-13 number.
+	if _, err := writer(`<w:p><w:r><w:t>{{barcode:code128:CODE}}</w:t></w:r></w:p>`, &Relations{}); err != nil {
+		t.Errorf("Expected a Code 128 barcode, got %v", err)
+	}
+	if _, err := writer(`<w:p><w:r><w:t>{{barcode:ean13:CODE}}</w:t></w:r></w:p>`, &Relations{}); err == nil || !strings.Contains(err.Error(), `barcode "CODE"`) {
+		t.Errorf("Expected an error naming the key, got %v", err)
+	}
+}
+
+func TestUpdateParts_AddsImages(t *testing.T) {
+	testFilePath := t.TempDir() + "/test.docx"
+	err := utils.WriteDocx(testFilePath, []utils.DocxPart{
+		{Name: utils.ContentTypesPath, Content: []byte(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="xml" ContentType="application/xml"/></Types>`)},
+		{Name: "word/document.xml", Content: []byte(`<w:document xmlns:w="w"><w:p><w:r><w:t>{{qr:URL}}</w:t></w:r><w:r><w:t>{{qr:URL}}</w:t></w:r></w:p></w:document>`)},
+	})
+	if err != nil {
+		t.Fatalf("WriteDocx returned an error: %v", err)
+	}
+	writer, err := BarcodeWriter(map[string]Barcode{"URL": {Value: "https://example.com"}})
+	if err != nil {
+		t.Fatalf("BarcodeWriter returned an error: %v", err)
+	}
+	if err := UpdateParts(testFilePath, writer); err != nil {
+		t.Fatalf("UpdateParts returned an error: %v", err)
+	}
+
+	parts := readTestDocx(t, testFilePath)
+	images := 0
+	for name := range parts {
+		if strings.HasPrefix(name, "word/media/") {
+			images++
+		}
+	}
+	if images != 1 {
+		t.Errorf("Expected one shared image part, got %d", images)
+	}
+	if !strings.Contains(parts[utils.ContentTypesPath], `<Default Extension="png" ContentType="image/png"/>`) {
+		t.Errorf("Expected a content type for PNG images, got '%s'", parts[utils.ContentTypesPath])
+	}
+	if strings.Count(parts["word/_rels/document.xml.rels"], "<Relationship ") != 1 {
+		t.Errorf("Expected one image relationship, got '%s'", parts["word/_rels/document.xml.rels"])
+	}
+	if strings.Count(parts["word/document.xml"], `r:embed="rId1"`) != 2 {
+		t.Errorf("Expected both placeholders to show the image, got '%s'", parts["word/document.xml"])
+	}
+}
@@ -0,0 +1,115 @@
+package placeholder
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aliamerj/docxer/internal/locale"
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+// filters are the functions a placeholder can apply to its value, as in
+// {{NAME | upper}}. They run left to right on the formatted value.
+var filters = map[string]func(string) string{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+	"title": titleCase,
+}
+
+// valueFilters format the value of a placeholder for the locale of the
+// render, as in {{TOTAL | currency:EUR}}. The text after the colon is their
+// argument: the number of decimals, a currency code, or a date style or
+// picture such as "long" or "d MMMM yyyy".
+var valueFilters = map[string]func(value any, arg string, loc *locale.Locale) (string, error){
+	"number": func(value any, arg string, loc *locale.Locale) (string, error) {
+		decimals := -1
+		if arg != "" {
+			var err error
+			if decimals, err = strconv.Atoi(arg); err != nil || decimals < 0 {
+				return "", fmt.Errorf("invalid number of decimals %q", arg)
+			}
+		}
+		if number, ok := utils.ToNumber(value); ok {
+			return loc.Number(number, decimals), nil
+		}
+		return loc.Format(value), nil
+	},
+	"currency": func(value any, arg string, loc *locale.Locale) (string, error) {
+		if number, ok := utils.ToNumber(value); ok {
+			return loc.Currency(number, arg)
+		}
+		return loc.Format(value), nil
+	},
+	"date": func(value any, arg string, loc *locale.Locale) (string, error) {
+		if arg == "" {
+			arg = "medium"
+		}
+		if t, ok := utils.ToTime(value); ok {
+			return loc.Date(t, arg), nil
+		}
+		return loc.Format(value), nil
+	},
+}
+
+// IsFilter reports whether name is a filter placeholders can use.
+func IsFilter(name string) bool {
+	base, _, _ := strings.Cut(name, ":")
+	if _, ok := valueFilters[base]; ok {
+		return true
+	}
+	_, ok := filters[name]
+	return ok
+}
+
+// ParsePlaceholder splits the text between the braces of a placeholder into
+// its key and the names of its filters.
+func ParsePlaceholder(inner string) (string, []string) {
+	fields := strings.Split(inner, "|")
+	names := make([]string, 0, len(fields)-1)
+	for _, name := range fields[1:] {
+		names = append(names, strings.TrimSpace(name))
+	}
+	return strings.TrimSpace(fields[0]), names
+}
+
+// formatValue writes a value for loc, or with utils.FormatValue when loc is
+// nil, and applies the filters named after its key. Value filters without a
+// locale use locale.Default.
+func formatValue(value any, names []string, loc *locale.Locale) (string, error) {
+	text := utils.FormatValue(value)
+	if loc != nil {
+		text = loc.Format(value)
+	} else {
+		loc = locale.Default
+	}
+	for _, name := range names {
+		base, arg, _ := strings.Cut(name, ":")
+		if filter, ok := valueFilters[base]; ok {
+			formatted, err := filter(value, strings.TrimSpace(arg), loc)
+			if err != nil {
+				return "", fmt.Errorf("filter %q: %w", name, err)
+			}
+			text = formatted
+		} else if filter, ok := filters[name]; ok {
+			text = filter(text)
+		}
+	}
+	return text, nil
+}
+
+func titleCase(value string) string {
+	var result strings.Builder
+	startOfWord := true
+	for _, r := range value {
+		if startOfWord && unicode.IsLetter(r) {
+			result.WriteRune(unicode.ToUpper(r))
+		} else {
+			result.WriteRune(r)
+		}
+		startOfWord = unicode.IsSpace(r) || r == '-'
+	}
+	return result.String()
+}
@@ -0,0 +1,55 @@
+package placeholder
+
+import (
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+// Link is the value of a {{link:key}} placeholder. URL makes an external
+// hyperlink and Bookmark a link to a bookmark in the same document; when both
+// are set the URL wins. Text defaults to the URL or the bookmark name.
+type Link struct {
+	Text     string
+	URL      string
+	Bookmark string
+	Tooltip  string
+}
+
+// HyperlinkWriter replaces {{link:key}} placeholders with hyperlinks. The runs
+// of the link keep the formatting of the run that held the placeholder and get
+// the Hyperlink character style.
+func HyperlinkWriter(links map[string]Link) PartWriter {
+	return func(content string, rels *Relations) (string, error) {
+		for key, link := range links {
+			if link.URL == "" && link.Bookmark == "" {
+				continue
+			}
+			content = replacePlaceholder(content, "{{link:"+key+"}}", nil, func(runProps string) string {
+				return hyperlink(link, runProps, rels)
+			})
+		}
+		return content, nil
+	}
+}
+
+func hyperlink(link Link, runProps string, rels *Relations) string {
+	tag := "<w:hyperlink>"
+	text := link.Text
+	if link.URL != "" {
+		tag = utils.SetAttr(tag, "r:id", rels.Add(HyperlinkRelType, link.URL, true))
+		if text == "" {
+			text = link.URL
+		}
+	} else {
+		tag = utils.SetAttr(tag, "w:anchor", link.Bookmark)
+		if text == "" {
+			text = link.Bookmark
+		}
+	}
+	if link.Tooltip != "" {
+		tag = utils.SetAttr(tag, "w:tooltip", link.Tooltip)
+	}
+	tag = utils.SetAttr(tag, "w:history", "1")
+
+	runProps = utils.MergeRunProperties(runProps, `<w:rPr><w:rStyle w:val="Hyperlink"/></w:rPr>`)
+	return tag + utils.TextRun(runProps, text) + "</w:hyperlink>"
+}
@@ -0,0 +1,80 @@
+package placeholder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+func TestHyperlinkWriter_ExternalLink(t *testing.T) {
+	inputContent := `<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>Visit {{link:portal}}.</w:t></w:r></w:p>`
+	expectedOutput := `<w:p><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">Visit </w:t></w:r>` +
+		`<w:hyperlink r:id="rId1" w:history="1"><w:r><w:rPr><w:rStyle w:val="Hyperlink"/><w:b/></w:rPr><w:t xml:space="preserve">the portal</w:t></w:r></w:hyperlink>` +
+		`<w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">.</w:t></w:r></w:p>`
+	writer := HyperlinkWriter(map[string]Link{
+		"portal": {Text: "the portal", URL: "https://portal.example.com"},
+	})
+
+	rels := &Relations{}
+	outputContent, err := writer(inputContent, rels)
+	if err != nil {
+		t.Fatalf("PartWriter returned an error: %v", err)
+	}
+
+	if outputContent != expectedOutput {
+		t.Errorf("Expected '%s', got '%s'", expectedOutput, outputContent)
+	}
+	if !strings.Contains(relsContent(rels), `Target="https://portal.example.com" TargetMode="External"`) {
+		t.Errorf("Expected an external relationship, got '%s'", relsContent(rels))
+	}
+}
+
+func TestHyperlinkWriter_BookmarkLink(t *testing.T) {
+	inputContent := `<w:p><w:r><w:t>{{link:summary}}</w:t></w:r></w:p>`
+	expectedOutput := `<w:p><w:hyperlink w:anchor="summary" w:tooltip="Jump to summary" w:history="1"><w:r><w:rPr><w:rStyle w:val="Hyperlink"/></w:rPr><w:t xml:space="preserve">summary</w:t></w:r></w:hyperlink></w:p>`
+	writer := HyperlinkWriter(map[string]Link{
+		"summary": {Bookmark: "summary", Tooltip: "Jump to summary"},
+	})
+
+	rels := &Relations{}
+	outputContent, err := writer(inputContent, rels)
+	if err != nil {
+		t.Fatalf("PartWriter returned an error: %v", err)
+	}
+
+	if outputContent != expectedOutput {
+		t.Errorf("Expected '%s', got '%s'", expectedOutput, outputContent)
+	}
+	if rels.changed {
+		t.Errorf("Expected no relationship for a bookmark link, got '%s'", relsContent(rels))
+	}
+}
+
+func TestUpdateParts_HeaderLinkAndStyle(t *testing.T) {
+	tempDir := t.TempDir()
+	testFilePath := tempDir + "/test.docx"
+	err := utils.WriteDocx(testFilePath, []utils.DocxPart{
+		{Name: "word/document.xml", Content: []byte(`<w:document xmlns:w="w"><w:p/></w:document>`)},
+		{Name: "word/header1.xml", Content: []byte(`<w:hdr xmlns:w="w"><w:p><w:r><w:t>{{link:site}}</w:t></w:r></w:p></w:hdr>`)},
+		{Name: "word/styles.xml", Content: []byte(`<w:styles xmlns:w="w"></w:styles>`)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test DOCX: %v", err)
+	}
+
+	if err := UpdateParts(testFilePath, HyperlinkWriter(map[string]Link{"site": {URL: "https://example.com"}})); err != nil {
+		t.Fatalf("UpdateParts returned an error: %v", err)
+	}
+
+	parts := readTestDocx(t, testFilePath)
+	if !strings.Contains(parts["word/_rels/header1.xml.rels"], `Target="https://example.com"`) {
+		t.Errorf("Expected the relationship in the header rels, got '%s'", parts["word/_rels/header1.xml.rels"])
+	}
+	if _, ok := parts["word/_rels/document.xml.rels"]; ok {
+		t.Errorf("Expected the document rels to be left alone")
+	}
+	if !utils.HasStyle(parts["word/styles.xml"], "Hyperlink") {
+		t.Errorf("Expected the Hyperlink style to be added, got '%s'", parts["word/styles.xml"])
+	}
+}
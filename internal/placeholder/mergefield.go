@@ -0,0 +1,113 @@
+package placeholder
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/aliamerj/docxer/internal/locale"
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+// mergeField is a MERGEFIELD of a Word mail-merge template, such as
+// { MERGEFIELD FirstName \b "Dear " \* Upper }.
+type mergeField struct {
+	Name   string
+	Before string
+	After  string
+	Case   string
+	Number string
+	Date   string
+}
+
+func parseMergeField(instr string) (mergeField, bool) {
+	args := utils.FieldArgs(instr)
+	if len(args) < 2 || !strings.EqualFold(args[0], "MERGEFIELD") {
+		return mergeField{}, false
+	}
+	field := mergeField{Name: args[1]}
+	for i := 2; i+1 < len(args); i++ {
+		switch args[i] {
+		case `\b`:
+			field.Before = args[i+1]
+		case `\f`:
+			field.After = args[i+1]
+		case `\*`:
+			if name := strings.ToLower(args[i+1]); name != "mergeformat" && name != "charformat" {
+				field.Case = name
+			}
+		case `\#`:
+			field.Number = args[i+1]
+		case `\@`:
+			field.Date = args[i+1]
+		default:
+			continue
+		}
+		i++
+	}
+	return field, true
+}
+
+// format returns the text a merge field shows for value, written for loc or
+// with fmt when loc is nil. Empty values show nothing, not even the \b and \f
+// text.
+func (f mergeField) format(value any, loc *locale.Locale) string {
+	pictures := loc
+	if pictures == nil {
+		pictures = locale.Default
+	}
+	text := ""
+	if value != nil {
+		if loc != nil {
+			text = loc.Format(value)
+		} else {
+			text = fmt.Sprint(value)
+		}
+		if t, ok := utils.ToTime(value); ok && f.Date != "" {
+			text = pictures.Date(t, f.Date)
+		} else if number, ok := utils.ToNumber(value); ok && f.Number != "" {
+			text = pictures.Picture(number, f.Number)
+		}
+	}
+	if text == "" {
+		return ""
+	}
+
+	switch f.Case {
+	case "upper":
+		text = strings.ToUpper(text)
+	case "lower":
+		text = strings.ToLower(text)
+	case "caps":
+		text = titleCase(text)
+	case "firstcap":
+		r, size := utf8.DecodeRuneInString(text)
+		text = string(unicode.ToUpper(r)) + text[size:]
+	}
+	return f.Before + text + f.After
+}
+
+// mergeFields replaces the MERGEFIELDs of content with the values returned by
+// value, written for loc, removing their field codes. Fields for which value reports no value
+// are kept.
+func mergeFields(content string, loc *locale.Locale, value func(name string) (any, bool, error)) (string, error) {
+	if !strings.Contains(content, "<w:fld") {
+		return content, nil
+	}
+	fields := utils.FindFields(content)
+	for i := len(fields) - 1; i >= 0; i-- {
+		field, ok := parseMergeField(fields[i].Instr)
+		if !ok {
+			continue
+		}
+		v, ok, err := value(field.Name)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			content = utils.ReplaceField(content, fields[i], field.format(v, loc))
+		}
+	}
+	return content, nil
+}
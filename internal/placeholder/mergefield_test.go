@@ -0,0 +1,125 @@
+package placeholder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aliamerj/docxer/internal/docxerr"
+	"github.com/aliamerj/docxer/internal/locale"
+)
+
+const (
+	simpleMergeField  = `<w:p><w:fldSimple w:instr=" MERGEFIELD FirstName \b &quot;Dear &quot; \f &quot;,&quot; \* Upper \* MERGEFORMAT "><w:r><w:rPr><w:b/></w:rPr><w:t>«FirstName»</w:t></w:r></w:fldSimple></w:p>`
+	complexMergeField = `<w:p><w:r><w:t xml:space="preserve">Total: </w:t></w:r><w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText xml:space="preserve"> MERGEFIELD </w:instrText></w:r><w:r><w:instrText xml:space="preserve">Total \# "$#,##0.00" </w:instrText></w:r><w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:rPr><w:i/></w:rPr><w:t>«Total»</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r></w:p>`
+)
+
+func TestParseMergeField(t *testing.T) {
+	field, ok := parseMergeField(`MERGEFIELD "First Name" \b "Dear " \f "," \* FirstCap \* MERGEFORMAT`)
+	if !ok {
+		t.Fatalf("Expected a MERGEFIELD")
+	}
+	expected := mergeField{Name: "First Name", Before: "Dear ", After: ",", Case: "firstcap"}
+	if field != expected {
+		t.Errorf("parseMergeField() = %+v, want %+v", field, expected)
+	}
+	if _, ok := parseMergeField(`DOCPROPERTY Title`); ok {
+		t.Errorf("Expected other fields not to be MERGEFIELDs")
+	}
+}
+
+func TestMergeField_Format(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 14, 7, 0, 0, time.UTC)
+	tests := []struct {
+		field    mergeField
+		value    any
+		expected string
+	}{
+		{mergeField{Before: "Dear ", After: ","}, "ali", "Dear ali,"},
+		{mergeField{Before: "Dear ", After: ","}, "", ""},
+		{mergeField{Before: "Dear "}, nil, ""},
+		{mergeField{Case: "upper"}, "ali", "ALI"},
+		{mergeField{Case: "lower"}, "ALI", "ali"},
+		{mergeField{Case: "caps"}, "ali amer", "Ali Amer"},
+		{mergeField{Case: "firstcap"}, "ali amer", "Ali amer"},
+		{mergeField{Number: "#,##0.00"}, 1234567.891, "1,234,567.89"},
+		{mergeField{Number: "$0.0"}, "-3.14", "$-3.1"},
+		{mergeField{Number: "0.##"}, 2.5, "2.5"},
+		{mergeField{Number: "000"}, 7, "007"},
+		{mergeField{Number: "0.00"}, "n/a", "n/a"},
+		{mergeField{Date: "d MMMM yyyy"}, date, "5 March 2024"},
+		{mergeField{Date: "dddd, dd/MM/yy h:mm AM/PM"}, date, "Tuesday, 05/03/24 2:07 PM"},
+		{mergeField{Date: "'Due' yyyy-MM-dd"}, "2024-03-05", "Due 2024-03-05"},
+	}
+	for _, test := range tests {
+		if got := test.field.format(test.value, nil); got != test.expected {
+			t.Errorf("%+v.format(%v) = %q, want %q", test.field, test.value, got, test.expected)
+		}
+	}
+
+	de, err := locale.Parse("de-DE")
+	if err != nil {
+		t.Fatalf("locale.Parse returned an error: %v", err)
+	}
+	localized := []struct {
+		field    mergeField
+		value    any
+		expected string
+	}{
+		{mergeField{Number: "#,##0.00"}, 1234567.891, "1.234.567,89"},
+		{mergeField{Date: "d. MMMM yyyy"}, date, "5. März 2024"},
+		{mergeField{}, 2.5, "2,5"},
+		{mergeField{}, date, "05.03.2024"},
+	}
+	for _, test := range localized {
+		if got := test.field.format(test.value, de); got != test.expected {
+			t.Errorf("%+v.format(%v, de-DE) = %q, want %q", test.field, test.value, got, test.expected)
+		}
+	}
+}
+
+func TestTextPlaceholderWriter_MergeFields(t *testing.T) {
+	docxWriter := TextPlaceholderWriter(map[string]string{"FirstName": "ali & co", "Total": "1234.5"}, nil)()
+
+	outputContent, err := docxWriter(simpleMergeField + complexMergeField)
+	if err != nil {
+		t.Fatalf("DocxWriter returned an error: %v", err)
+	}
+	expectedOutput := `<w:p><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">Dear ALI &amp; CO,</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t xml:space="preserve">Total: </w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t xml:space="preserve">$1,234.50</w:t></w:r></w:p>`
+	if outputContent != expectedOutput {
+		t.Errorf("Expected '%s', got '%s'", expectedOutput, outputContent)
+	}
+
+	// Fields without a replacement are left for Word to merge.
+	outputContent, err = TextPlaceholderWriter(map[string]string{}, nil)()(simpleMergeField)
+	if err != nil || outputContent != simpleMergeField {
+		t.Errorf("Expected the field to be kept, got '%s' (%v)", outputContent, err)
+	}
+}
+
+func TestResolverWriter_MergeFields(t *testing.T) {
+	loopField := `<w:fldSimple w:instr=" MERGEFIELD Name "><w:r><w:t>«Name»</w:t></w:r></w:fldSimple>`
+	inputContent := simpleMergeField + `{{#each items}}` + loopField + `{{/each}}`
+	values := map[string]any{
+		"FirstName": "ali",
+		"items":     []map[string]any{{"Name": "Pen"}, {"Name": "Ink"}},
+	}
+
+	outputContent, err := ResolverWriter(context.Background(), MapResolver(values), nil)()(inputContent)
+	if err != nil {
+		t.Fatalf("DocxWriter returned an error: %v", err)
+	}
+	expectedOutput := `<w:p><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">Dear ALI,</w:t></w:r></w:p>` +
+		`<w:r><w:t xml:space="preserve">Pen</w:t></w:r><w:r><w:t xml:space="preserve">Ink</w:t></w:r>`
+	if outputContent != expectedOutput {
+		t.Errorf("Expected '%s', got '%s'", expectedOutput, outputContent)
+	}
+
+	_, err = ResolverWriter(context.Background(), MapResolver(map[string]any{}), nil)()(complexMergeField)
+	var missingErr *docxerr.MissingKeyError
+	if !errors.As(err, &missingErr) || missingErr.Key != "Total" {
+		t.Errorf("Expected a MissingKeyError for Total, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package placeholder
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/aliamerj/docxer/internal/docxerr"
+	"github.com/aliamerj/docxer/internal/media"
+	"github.com/aliamerj/docxer/internal/opc"
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+const (
+	relationshipsNamespace = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	HyperlinkRelType       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink"
+	ImageRelType           = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/image"
+	stylesPath             = "word/styles.xml"
+)
+
+// builtinStyles are the definitions of styles that writers may reference but
+// that templates do not always define.
+var builtinStyles = map[string]string{
+	"Hyperlink": `<w:style w:type="character" w:styleId="Hyperlink"><w:name w:val="Hyperlink"/><w:basedOn w:val="DefaultParagraphFont"/><w:uiPriority w:val="99"/><w:unhideWhenUsed/><w:rPr><w:color w:val="0563C1"/><w:u w:val="single"/></w:rPr></w:style>`,
+	"TableGrid": `<w:style w:type="table" w:styleId="TableGrid"><w:name w:val="Table Grid"/><w:basedOn w:val="TableNormal"/><w:uiPriority w:val="39"/><w:tblPr><w:tblBorders><w:top w:val="single" w:sz="4" w:space="0" w:color="auto"/><w:left w:val="single" w:sz="4" w:space="0" w:color="auto"/><w:bottom w:val="single" w:sz="4" w:space="0" w:color="auto"/><w:right w:val="single" w:sz="4" w:space="0" w:color="auto"/><w:insideH w:val="single" w:sz="4" w:space="0" w:color="auto"/><w:insideV w:val="single" w:sz="4" w:space="0" w:color="auto"/></w:tblBorders></w:tblPr></w:style>`,
+}
+
+// Relations gives a PartWriter access to the relationships of the part it is
+// transforming, such as word/_rels/header1.xml.rels for word/header1.xml. A
+// zero Relations keeps them in a package of its own with word/document.xml
+// as the part.
+type Relations struct {
+	pkg     *opc.Package
+	part    string
+	changed bool
+}
+
+func (r *Relations) source() (*opc.Package, string) {
+	if r.pkg == nil {
+		r.pkg, r.part = opc.New(), "word/document.xml"
+	}
+	return r.pkg, r.part
+}
+
+// Add returns the id of a relationship from the part to target, creating the
+// relationship when the part does not have it yet. An internal target is
+// relative to the folder of the part.
+func (r *Relations) Add(relType, target string, external bool) string {
+	pkg, part := r.source()
+	before, _ := pkg.Part(utils.RelsPath(part))
+	var id string
+	if external {
+		id = pkg.RelateExternal(part, relType, target)
+	} else {
+		id = pkg.Relate(part, relType, utils.ResolveTarget(part, target))
+	}
+	if after, _ := pkg.Part(utils.RelsPath(part)); string(after) != string(before) {
+		r.changed = true
+	}
+	return id
+}
+
+// AddImage stores image data as a new part of the package and returns the id
+// of the relationship that embeds it. Identical images share one part.
+func (r *Relations) AddImage(data []byte, extension string) string {
+	pkg, part := r.source()
+	sum := sha1.Sum(data)
+	target := "media/docxer-" + hex.EncodeToString(sum[:8]) + "." + extension
+	if name := utils.ResolveTarget(part, target); !hasPart(pkg, name) {
+		pkg.SetPart(name, media.ContentType(extension), data)
+	}
+	return r.Add(ImageRelType, target, false)
+}
+
+func hasPart(pkg *opc.Package, name string) bool {
+	_, ok := pkg.Part(name)
+	return ok
+}
+
+// PartWriter transforms the content of a story part. Unlike a DocxWriter it
+// may add relationships and images to the part it is working on.
+type PartWriter func(content string, rels *Relations) (string, error)
+
+// UpdateParts applies writer to the document, header, footer, footnote and
+// endnote parts of the DOCX file and stores any relationships it added.
+func UpdateParts(filePath string, writer PartWriter) error {
+	pkg, err := opc.Open(filePath)
+	if err != nil {
+		return err
+	}
+
+	var stories []string
+	for _, part := range pkg.Parts() {
+		if utils.IsStoryPart(part.Name) {
+			stories = append(stories, part.Name)
+		}
+	}
+	for _, name := range stories {
+		content, _ := pkg.Part(name)
+		rels := &Relations{pkg: pkg, part: name}
+		updated, err := writer(string(content), rels)
+		if err != nil {
+			return fmt.Errorf("error updating '%s': %w", name, docxerr.InPart(err, name))
+		}
+		if rels.changed {
+			updated = utils.EnsureNamespace(updated, "r", relationshipsNamespace)
+		}
+		pkg.SetPart(name, "", []byte(updated))
+	}
+	addReferencedStyles(pkg)
+	return pkg.Save(filePath)
+}
+
+// addReferencedStyles defines the builtin styles that story parts refer to
+// but word/styles.xml lacks.
+func addReferencedStyles(pkg *opc.Package) {
+	content, ok := pkg.Part(stylesPath)
+	if !ok {
+		return
+	}
+	styles := string(content)
+	for styleID, definition := range builtinStyles {
+		if utils.HasStyle(styles, styleID) {
+			continue
+		}
+		reference := `w:val="` + styleID + `"`
+		for _, part := range pkg.Parts() {
+			if utils.IsStoryPart(part.Name) && strings.Contains(string(part.Content), reference) {
+				styles = utils.AddStyle(styles, styleID, definition)
+				break
+			}
+		}
+	}
+	pkg.SetPart(stylesPath, "", []byte(styles))
+}
@@ -0,0 +1,78 @@
+package placeholder
+
+import (
+	"archive/zip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+func TestUpdateParts_AddsRelationships(t *testing.T) {
+	tempDir := t.TempDir()
+	testFilePath := tempDir + "/test.docx"
+	createTestDocx(testFilePath, `<w:document xmlns:w="w"><w:p><w:r><w:t>{{LINK}}</w:t></w:r></w:p></w:document>`)
+
+	writer := RichTextWriter(map[string]RichValue{
+		"LINK": {Markdown: "[Docs](https://example.com)"},
+	})
+	if err := UpdateParts(testFilePath, writer); err != nil {
+		t.Fatalf("UpdateParts returned an error: %v", err)
+	}
+
+	parts := readTestDocx(t, testFilePath)
+	if !strings.Contains(parts["word/_rels/document.xml.rels"], `Target="https://example.com"`) {
+		t.Errorf("Expected the hyperlink relationship to be stored, got '%s'", parts["word/_rels/document.xml.rels"])
+	}
+	document := parts["word/document.xml"]
+	if !strings.Contains(document, `xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`) {
+		t.Errorf("Expected the relationships namespace to be declared, got '%s'", document)
+	}
+	if !strings.Contains(document, `<w:hyperlink r:id="rId1" w:history="1">`) {
+		t.Errorf("Expected a hyperlink, got '%s'", document)
+	}
+}
+
+// readTestDocx returns the contents of every part of a DOCX file by name
+func readTestDocx(t *testing.T, filePath string) map[string]string {
+	r, err := zip.OpenReader(filePath)
+	if err != nil {
+		t.Fatalf("Failed to open updated DOCX file: %v", err)
+	}
+	defer r.Close()
+
+	parts := map[string]string{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", f.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", f.Name, err)
+		}
+		parts[f.Name] = string(content)
+	}
+	return parts
+}
+
+// relsContent returns the relationships part of the part rels belongs to.
+func relsContent(rels *Relations) string {
+	pkg, part := rels.source()
+	content, _ := pkg.Part(utils.RelsPath(part))
+	return string(content)
+}
+
+// mediaParts returns the images added to the package of rels.
+func mediaParts(rels *Relations) []utils.DocxPart {
+	pkg, _ := rels.source()
+	var media []utils.DocxPart
+	for _, part := range pkg.Parts() {
+		if strings.HasPrefix(part.Name, "word/media/") {
+			media = append(media, part)
+		}
+	}
+	return media
+}
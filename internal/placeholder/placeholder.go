@@ -1,93 +1,52 @@
 package placeholder
 
 import (
-	"archive/zip"
-	"io"
-	"os"
 	"strings"
 
+	"github.com/aliamerj/docxer/internal/locale"
+	"github.com/aliamerj/docxer/internal/opc"
 	"github.com/aliamerj/docxer/internal/utils"
 )
 
 type PlaceholderAction func() utils.DocxWriter
 
 func UpdateDocx(filePath string, action PlaceholderAction) error {
-	// Open the existing DOCX file for reading
-	zipReader, err := zip.OpenReader(filePath)
-	if err != nil {
-		return err
-	}
-	defer zipReader.Close()
+	return TransformDocx(filePath, action())
+}
 
-	// Create a temporary output file
-	tempFilePath := filePath + ".tmp"
-	outputFile, err := os.Create(tempFilePath)
+// TransformDocx rewrites the DOCX file, passing every part through transformer.
+func TransformDocx(filePath string, transformer utils.Transformer) error {
+	pkg, err := opc.Open(filePath)
 	if err != nil {
 		return err
 	}
-	defer outputFile.Close()
-
-	// Create a new ZIP writer
-	zipWriter := zip.NewWriter(outputFile)
-	defer zipWriter.Close()
-
-	// Setup the docxWriter function for updating placeholders
-	docxer := action() // docxPlaceholderWriter(replacements)
-
-	// Process each file in the zip archive
-	for _, file := range zipReader.File {
-		rc, err := file.Open()
-		if err != nil {
-			return err
-		}
-
-		fileContent, err := io.ReadAll(rc)
-		rc.Close()
-		if err != nil {
-			return err
-		}
-
-		newFile, err := zipWriter.Create(file.Name)
-		if err != nil {
-			return err
-		}
-		// Apply transformation if it's the document XML or other target files
-		updatedContent := docxer(string(fileContent))
-		if _, err = newFile.Write([]byte(updatedContent)); err != nil {
-			return err
-		}
-	}
-
-	// Replace the original file with the updated version
-	if err := zipWriter.Close(); err != nil {
-		return err
-	}
-	if err := outputFile.Close(); err != nil {
-		return err
-	}
-	if err := os.Rename(tempFilePath, filePath); err != nil {
+	if err := pkg.Transform(transformer); err != nil {
 		return err
 	}
-
-	return nil
+	return pkg.Save(filePath)
 }
 
 // DocxPlaceholderWriter creates a function to replace placeholders with their corresponding replacements.
-func TextPlaceholderWriter(replacements map[string]string) PlaceholderAction {
+// MERGEFIELD number and date pictures are written for loc, or for locale.Default when it is nil.
+func TextPlaceholderWriter(replacements map[string]string, loc *locale.Locale) PlaceholderAction {
 	return func() utils.DocxWriter {
-		return func(fileContent string) string {
+		return func(fileContent string) (string, error) {
 			updatedTemplate := fileContent
 			for placeholder, replacement := range replacements {
 				updatedTemplate = strings.ReplaceAll(updatedTemplate, "{{"+placeholder+"}}", replacement)
 			}
-			return updatedTemplate
+			// Word mail-merge templates use MERGEFIELDs instead of braces
+			return mergeFields(updatedTemplate, loc, func(name string) (any, bool, error) {
+				replacement, ok := replacements[name]
+				return replacement, ok, nil
+			})
 		}
 	}
 }
 
 func LoopPlaceholderWriter(data map[string]interface{}) PlaceholderAction {
 	return func() utils.DocxWriter {
-		return func(fileContent string) string {
+		return func(fileContent string) (string, error) {
 			updatedContent := fileContent
 
 			// Process each loop key in the data map
@@ -103,10 +62,15 @@ func LoopPlaceholderWriter(data map[string]interface{}) PlaceholderAction {
 
 				// Find the loop section in the updatedContent
 				startLoop := strings.Index(updatedContent, startMarker)
-				endLoop := strings.Index(updatedContent, endMarker) + len(endMarker)
-				if startLoop == -1 || endLoop == -1 {
+				if startLoop == -1 {
 					continue // Loop markers not found, continue with next
 				}
+				// The end marker must follow the start marker
+				endLoop := strings.Index(updatedContent[startLoop:], endMarker)
+				if endLoop == -1 {
+					continue
+				}
+				endLoop += startLoop + len(endMarker)
 
 				// Extract the template section for the loop
 				loopTemplate := updatedContent[startLoop+len(startMarker) : endLoop-len(endMarker)]
@@ -126,7 +90,7 @@ func LoopPlaceholderWriter(data map[string]interface{}) PlaceholderAction {
 				updatedContent = updatedContent[:startLoop] + result.String() + updatedContent[endLoop:]
 			}
 
-			return updatedContent
+			return updatedContent, nil
 		}
 	}
 }
@@ -2,10 +2,13 @@ package placeholder
 
 import (
 	"archive/zip"
+	"errors"
 	"io"
 	"os"
 	"strings"
 	"testing"
+
+	"github.com/aliamerj/docxer/internal/utils"
 )
 
 func TestTextPlaceholderWriter_BasicReplacement(t *testing.T) {
@@ -18,11 +21,14 @@ func TestTextPlaceholderWriter_BasicReplacement(t *testing.T) {
 	expectedOutput := "Hello John Doe, welcome to GoLand."
 
 	// Create the placeholder writer action
-	action := TextPlaceholderWriter(replacements)
+	action := TextPlaceholderWriter(replacements, nil)
 	docxWriter := action()
 
 	// Execute the writer
-	outputContent := docxWriter(inputContent)
+	outputContent, err := docxWriter(inputContent)
+	if err != nil {
+		t.Fatalf("DocxWriter returned an error: %v", err)
+	}
 
 	// Verify the output
 	if outputContent != expectedOutput {
@@ -39,11 +45,14 @@ func TestTextPlaceholderWriter_NoReplacements(t *testing.T) {
 	expectedOutput := "Hello there."
 
 	// Create the placeholder writer action
-	action := TextPlaceholderWriter(replacements)
+	action := TextPlaceholderWriter(replacements, nil)
 	docxWriter := action()
 
 	// Execute the writer
-	outputContent := docxWriter(inputContent)
+	outputContent, err := docxWriter(inputContent)
+	if err != nil {
+		t.Fatalf("DocxWriter returned an error: %v", err)
+	}
 
 	// Verify the output
 	if outputContent != expectedOutput {
@@ -60,11 +69,14 @@ func TestTextPlaceholderWriter_MultipleOccurrences(t *testing.T) {
 	expectedOutput := "Hello Alice, you are Alice right?"
 
 	// Create the placeholder writer action
-	action := TextPlaceholderWriter(replacements)
+	action := TextPlaceholderWriter(replacements, nil)
 	docxWriter := action()
 
 	// Execute the writer
-	outputContent := docxWriter(inputContent)
+	outputContent, err := docxWriter(inputContent)
+	if err != nil {
+		t.Fatalf("DocxWriter returned an error: %v", err)
+	}
 
 	// Verify the output
 	if outputContent != expectedOutput {
@@ -81,11 +93,14 @@ func TestTextPlaceholderWriter_EmptyStrings(t *testing.T) {
 	expectedOutput := "Hi !"
 
 	// Create the placeholder writer action
-	action := TextPlaceholderWriter(replacements)
+	action := TextPlaceholderWriter(replacements, nil)
 	docxWriter := action()
 
 	// Execute the writer
-	outputContent := docxWriter(inputContent)
+	outputContent, err := docxWriter(inputContent)
+	if err != nil {
+		t.Fatalf("DocxWriter returned an error: %v", err)
+	}
 
 	// Verify the output
 	if outputContent != expectedOutput {
@@ -109,7 +124,10 @@ func TestLoopPlaceholderWriter_BasicLoop(t *testing.T) {
 	docxWriter := action()
 
 	// Execute the writer
-	outputContent := docxWriter(inputContent)
+	outputContent, err := docxWriter(inputContent)
+	if err != nil {
+		t.Fatalf("DocxWriter returned an error: %v", err)
+	}
 
 	// Verify the output
 	if outputContent != expectedOutput {
@@ -134,7 +152,10 @@ func TestLoopPlaceholderWriter_MultipleLoops(t *testing.T) {
 	action := LoopPlaceholderWriter(data)
 	docxWriter := action()
 
-	outputContent := docxWriter(inputContent)
+	outputContent, err := docxWriter(inputContent)
+	if err != nil {
+		t.Fatalf("DocxWriter returned an error: %v", err)
+	}
 
 	if outputContent != expectedOutput {
 		t.Errorf("Expected '%s', got '%s'", expectedOutput, outputContent)
@@ -153,7 +174,10 @@ func TestLoopPlaceholderWriter_MissingMarkers(t *testing.T) {
 	action := LoopPlaceholderWriter(data)
 	docxWriter := action()
 
-	outputContent := docxWriter(inputContent)
+	outputContent, err := docxWriter(inputContent)
+	if err != nil {
+		t.Fatalf("DocxWriter returned an error: %v", err)
+	}
 
 	if outputContent != expectedOutput {
 		t.Errorf("Expected '%s', got '%s'", expectedOutput, outputContent)
@@ -170,13 +194,40 @@ func TestLoopPlaceholderWriter_IncorrectDataFormat(t *testing.T) {
 	action := LoopPlaceholderWriter(data)
 	docxWriter := action()
 
-	outputContent := docxWriter(inputContent)
+	outputContent, err := docxWriter(inputContent)
+	if err != nil {
+		t.Fatalf("DocxWriter returned an error: %v", err)
+	}
 
 	if outputContent != expectedOutput {
 		t.Errorf("Expected '%s', got '%s'", expectedOutput, outputContent)
 	}
 }
 
+func TestLoopPlaceholderWriter_UnmatchedEndMarker(t *testing.T) {
+	data := map[string]interface{}{
+		"items": []map[string]string{
+			{"NAME": "Item 1", "PRICE": "10"},
+		},
+	}
+	for _, inputContent := range []string{
+		"Items:\n{{#each items}}- {{NAME}}, ${{PRICE}}\n",
+		"{{/each}}\nItems:\n{{#each items}}- {{NAME}}, ${{PRICE}}\n",
+	} {
+		action := LoopPlaceholderWriter(data)
+		docxWriter := action()
+
+		outputContent, err := docxWriter(inputContent)
+		if err != nil {
+			t.Fatalf("DocxWriter returned an error: %v", err)
+		}
+
+		if outputContent != inputContent {
+			t.Errorf("Expected '%s', got '%s'", inputContent, outputContent)
+		}
+	}
+}
+
 func TestUpdateDocx_Basic(t *testing.T) {
 	// Setup a temporary directory
 	tempDir, err := os.MkdirTemp("", "testUpdateDocx_Basic")
@@ -193,7 +244,7 @@ func TestUpdateDocx_Basic(t *testing.T) {
 	action := TextPlaceholderWriter(map[string]string{
 		"TITLE": "Updated Title",
 		"BODY":  "Updated Body",
-	})
+	}, nil)
 
 	// Call UpdateDocx with the action
 	if err := UpdateDocx(testFilePath, action); err != nil {
@@ -217,7 +268,7 @@ func TestUpdateDocx_MultipleReplacements(t *testing.T) {
 	action := TextPlaceholderWriter(map[string]string{
 		"NAME": "Alice",
 		"AGE":  "30",
-	})
+	}, nil)
 
 	if err := UpdateDocx(testFilePath, action); err != nil {
 		t.Errorf("UpdateDocx returned an error: %v", err)
@@ -240,7 +291,7 @@ func TestUpdateDocx_NoReplacements(t *testing.T) {
 
 	action := TextPlaceholderWriter(map[string]string{
 		"UNUSED": "Nothing",
-	})
+	}, nil)
 
 	if err := UpdateDocx(testFilePath, action); err != nil {
 		t.Errorf("UpdateDocx returned an error: %v", err)
@@ -253,7 +304,7 @@ func TestUpdateDocx_FileDoesNotExist(t *testing.T) {
 	nonexistentFile := "/path/to/nonexistent.docx"
 	action := TextPlaceholderWriter(map[string]string{
 		"TITLE": "Title",
-	})
+	}, nil)
 
 	err := UpdateDocx(nonexistentFile, action)
 	if err == nil {
@@ -261,6 +312,46 @@ func TestUpdateDocx_FileDoesNotExist(t *testing.T) {
 	}
 }
 
+func TestTransformDocx_PartAware(t *testing.T) {
+	tempDir := t.TempDir()
+	testFilePath := tempDir + "/test.docx"
+	err := utils.WriteDocx(testFilePath, []utils.DocxPart{
+		{Name: "[Content_Types].xml", Content: []byte(`<Types><Default Extension="xml" ContentType="application/xml"/><Override PartName="/word/header1.xml" ContentType="header"/></Types>`)},
+		{Name: "word/document.xml", Content: []byte("<w:t>{{NAME}}</w:t>")},
+		{Name: "word/header1.xml", Content: []byte("<w:t>{{NAME}}</w:t>")},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test DOCX: %v", err)
+	}
+
+	seen := map[string]string{}
+	headersOnly := utils.TransformerFunc(func(name, contentType string, content []byte) ([]byte, error) {
+		seen[name] = contentType
+		if contentType != "header" {
+			return content, nil
+		}
+		return []byte(strings.ReplaceAll(string(content), "{{NAME}}", "Header")), nil
+	})
+	if err := TransformDocx(testFilePath, headersOnly); err != nil {
+		t.Fatalf("TransformDocx returned an error: %v", err)
+	}
+
+	verifyUpdatedDocx(t, testFilePath, "<w:t>{{NAME}}</w:t>")
+	if header := readTestDocx(t, testFilePath)["word/header1.xml"]; header != "<w:t>Header</w:t>" {
+		t.Errorf("Expected only the header to be transformed, got '%s'", header)
+	}
+	if seen["word/header1.xml"] != "header" || seen["word/document.xml"] != "application/xml" || len(seen) != 3 {
+		t.Errorf("Expected every part with its content type, got %v", seen)
+	}
+
+	failing := utils.TransformerFunc(func(name, contentType string, content []byte) ([]byte, error) {
+		return nil, errors.New("rejected")
+	})
+	if err := TransformDocx(testFilePath, failing); err == nil || !strings.Contains(err.Error(), "rejected") {
+		t.Errorf("Expected the transformer error, got %v", err)
+	}
+}
+
 // createTestDocx creates a simple DOCX file with the specified content
 func createTestDocx(filePath string, content string) {
 	// Create a file
@@ -0,0 +1,321 @@
+package placeholder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aliamerj/docxer/internal/docxerr"
+	"github.com/aliamerj/docxer/internal/locale"
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+var (
+	blockPattern       = regexp.MustCompile(`\{\{\s*([#/])(each|if)\b\s*([^{}\s]*)\s*\}\}`)
+	placeholderPattern = regexp.MustCompile(`\{\{\s*([^{}#/:|\s][^{}:|]*(?:\|[^{}]*)?)\}\}`)
+)
+
+// Scope tells a Resolver where a placeholder is. Outside loops Loop is "";
+// inside {{#each loop}} it names the loop and Index and Item describe the
+// current item.
+type Scope struct {
+	Loop  string
+	Index int
+	Item  map[string]any
+}
+
+// Resolver returns the value of a placeholder. A value for a loop must be a
+// slice of maps. Returning docxerr.ErrMissingKey reports that the key has no
+// value.
+type Resolver func(ctx context.Context, key string, scope Scope) (any, error)
+
+// MapResolver resolves placeholders from values and reports keys that are not
+// in it as missing.
+func MapResolver(values map[string]any) Resolver {
+	return func(ctx context.Context, key string, scope Scope) (any, error) {
+		value, ok := values[key]
+		if !ok {
+			return nil, docxerr.ErrMissingKey
+		}
+		return value, nil
+	}
+}
+
+type resolverKey struct {
+	key   string
+	loop  string
+	index int
+}
+
+// ResolverWriter fills {{key}} placeholders, {{#each key}} loops,
+// {{#if key}} conditions and Word MERGEFIELDs with values asked from resolver
+// when a placeholder is found. A condition shows its content when its value is
+// true, a non-zero number or a non-empty string or list. Inside a loop the
+// keys of the current item are used before asking the resolver. Values are
+// written for loc, or with fmt when it is nil, and filters named after the
+// key, as in {{NAME | upper}} or {{TOTAL | currency:EUR}}, are applied to
+// them. Every key is resolved at most once per scope and render; an error from
+// the resolver aborts the render.
+func ResolverWriter(ctx context.Context, resolver Resolver, loc *locale.Locale) PlaceholderAction {
+	return func() utils.DocxWriter {
+		memo := map[resolverKey]any{}
+		r := render{locale: loc}
+		r.resolve = func(key string, scope Scope) (any, error) {
+			memoKey := resolverKey{key: key, loop: scope.Loop, index: scope.Index}
+			if value, ok := memo[memoKey]; ok {
+				return value, nil
+			}
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			value, err := resolver(ctx, key, scope)
+			var missingErr *docxerr.MissingKeyError
+			switch {
+			case errors.As(err, &missingErr):
+				return nil, err
+			case errors.Is(err, docxerr.ErrMissingKey):
+				return nil, &docxerr.MissingKeyError{Key: key, Err: err}
+			case err != nil:
+				return nil, fmt.Errorf("resolving placeholder %q: %w", key, err)
+			}
+			memo[memoKey] = value
+			return value, nil
+		}
+
+		return func(fileContent string) (string, error) {
+			if err := checkTemplate(fileContent); err != nil {
+				return "", err
+			}
+			return r.content(fileContent, Scope{})
+		}
+	}
+}
+
+// render is a single render of a template by ResolverWriter.
+type render struct {
+	resolve func(key string, scope Scope) (any, error)
+	locale  *locale.Locale
+}
+
+// checkTemplate reports {{#each}} and {{#if}} blocks that are not closed
+// properly and placeholders using unknown filters. Conditions can be nested,
+// loops cannot.
+func checkTemplate(content string) error {
+	for _, match := range placeholderPattern.FindAllStringSubmatchIndex(content, -1) {
+		_, names := ParsePlaceholder(content[match[2]:match[3]])
+		for _, name := range names {
+			if !IsFilter(name) {
+				return docxerr.NewSyntaxError(content, match[0], "unknown filter "+strconv.Quote(name))
+			}
+		}
+	}
+
+	var open []string
+	var offsets []int
+	for _, match := range blockPattern.FindAllStringSubmatchIndex(content, -1) {
+		kind := content[match[4]:match[5]]
+		if content[match[2]:match[3]] == "/" {
+			if len(open) == 0 || open[len(open)-1] != kind {
+				return docxerr.NewSyntaxError(content, match[0], "{{/"+kind+"}} without a matching {{#"+kind+"}}")
+			}
+			open, offsets = open[:len(open)-1], offsets[:len(offsets)-1]
+			continue
+		}
+		if content[match[6]:match[7]] == "" {
+			return docxerr.NewSyntaxError(content, match[0], "{{#"+kind+"}} needs a key")
+		}
+		if kind == "each" && slices.Contains(open, "each") {
+			return docxerr.NewSyntaxError(content, match[0], "nested {{#each}} loops are not supported")
+		}
+		open, offsets = append(open, kind), append(offsets, match[0])
+	}
+	if len(open) > 0 {
+		kind := open[len(open)-1]
+		return docxerr.NewSyntaxError(content, offsets[len(offsets)-1], "{{#"+kind+"}} without a matching {{/"+kind+"}}")
+	}
+	return nil
+}
+
+// content replaces the blocks and placeholders of content in a single pass,
+// so resolved values are never parsed as placeholders themselves. The content
+// must have passed checkTemplate.
+func (r render) content(content string, scope Scope) (string, error) {
+	var result strings.Builder
+	// literal writes template text, filling the MERGEFIELDs it contains.
+	literal := func(text string) error {
+		merged, err := mergeFields(text, r.locale, func(name string) (any, bool, error) {
+			value, err := r.lookup(name, scope)
+			return value, true, err
+		})
+		result.WriteString(merged)
+		return err
+	}
+	for {
+		placeholder := placeholderPattern.FindStringSubmatchIndex(content)
+		block := blockPattern.FindStringSubmatchIndex(content)
+		if block != nil && (placeholder == nil || block[0] < placeholder[0]) {
+			kind, key := content[block[4]:block[5]], content[block[6]:block[7]]
+			end, next := blockEnd(content, block[1], kind)
+			body := content[block[1]:end]
+
+			var rendered string
+			var err error
+			if kind == "each" {
+				rendered, err = r.loop(key, body)
+			} else {
+				rendered, err = r.condition(key, body, scope)
+			}
+			if err != nil {
+				return "", err
+			}
+			if err := literal(content[:block[0]]); err != nil {
+				return "", err
+			}
+			result.WriteString(rendered)
+			content = content[next:]
+			continue
+		}
+		if placeholder == nil {
+			if err := literal(content); err != nil {
+				return "", err
+			}
+			return result.String(), nil
+		}
+
+		key, names := ParsePlaceholder(content[placeholder[2]:placeholder[3]])
+		value, err := r.lookup(key, scope)
+		if err != nil {
+			return "", err
+		}
+		if err := literal(content[:placeholder[0]]); err != nil {
+			return "", err
+		}
+		if value != nil {
+			text, err := formatValue(value, names, r.locale)
+			if err != nil {
+				return "", fmt.Errorf("placeholder %q: %w", key, err)
+			}
+			result.WriteString(utils.EscapeXML(text))
+		}
+		content = content[placeholder[1]:]
+	}
+}
+
+// blockEnd returns the offsets of the start and the end of the tag closing the
+// block of the given kind whose body starts at from.
+func blockEnd(content string, from int, kind string) (int, int) {
+	depth := 1
+	for _, match := range blockPattern.FindAllStringSubmatchIndex(content[from:], -1) {
+		if content[from+match[4]:from+match[5]] != kind {
+			continue
+		}
+		if content[from+match[2]:from+match[3]] == "#" {
+			depth++
+			continue
+		}
+		if depth--; depth == 0 {
+			return from + match[0], from + match[1]
+		}
+	}
+	return len(content), len(content)
+}
+
+// lookup returns the value of key from the current loop item or, failing
+// that, from the resolver.
+func (r render) lookup(key string, scope Scope) (any, error) {
+	if value, ok := scope.Item[key]; ok {
+		return value, nil
+	}
+	return r.resolve(key, scope)
+}
+
+func (r render) condition(key, body string, scope Scope) (string, error) {
+	value, err := r.lookup(key, scope)
+	if err != nil || !truthy(value) {
+		return "", err
+	}
+	return r.content(body, scope)
+}
+
+// truthy reports whether a condition value shows its block: false, zero
+// numbers and empty strings, slices and maps hide it.
+func truthy(value any) bool {
+	if value == nil {
+		return false
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() > 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() != 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() != 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() != 0
+	case reflect.Pointer, reflect.Interface:
+		return !v.IsNil()
+	}
+	return true
+}
+
+func (r render) loop(loop, body string) (string, error) {
+	value, err := r.resolve(loop, Scope{})
+	if err != nil {
+		return "", err
+	}
+	items, err := loopItems(value)
+	if err != nil {
+		return "", fmt.Errorf("loop %q: %w", loop, err)
+	}
+
+	var result strings.Builder
+	for i, item := range items {
+		iteration, err := r.content(body, Scope{Loop: loop, Index: i, Item: item})
+		if err != nil {
+			return "", err
+		}
+		result.WriteString(iteration)
+	}
+	return result.String(), nil
+}
+
+// loopItems converts the value of a loop to its items.
+func loopItems(value any) ([]map[string]any, error) {
+	switch items := value.(type) {
+	case nil:
+		return nil, nil
+	case []map[string]any:
+		return items, nil
+	case []map[string]string:
+		converted := make([]map[string]any, len(items))
+		for i, item := range items {
+			converted[i] = make(map[string]any, len(item))
+			for key, value := range item {
+				converted[i][key] = value
+			}
+		}
+		return converted, nil
+	}
+
+	list := reflect.ValueOf(value)
+	if list.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("unsupported loop value %T, expected a slice of maps", value)
+	}
+	converted := make([]map[string]any, list.Len())
+	for i := range converted {
+		item, ok := list.Index(i).Interface().(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unsupported loop item %T, expected map[string]any", list.Index(i).Interface())
+		}
+		converted[i] = item
+	}
+	return converted, nil
+}
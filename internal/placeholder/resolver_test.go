@@ -0,0 +1,220 @@
+package placeholder
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aliamerj/docxer/internal/docxerr"
+	"github.com/aliamerj/docxer/internal/locale"
+)
+
+func TestResolverWriter_ResolvesLazilyAndMemoizes(t *testing.T) {
+	inputContent := "<w:t>{{USER}} owes {{ RATE }}</w:t>{{#each items}}<w:t>{{NAME}}: {{PRICE}}</w:t>{{/each}}<w:t>{{USER}}</w:t>"
+	calls := map[string]int{}
+	resolver := func(ctx context.Context, key string, scope Scope) (any, error) {
+		calls[key]++
+		switch key {
+		case "USER":
+			return "Ali & Co", nil
+		case "RATE":
+			return 1.5, nil
+		case "items":
+			return []map[string]any{{"NAME": "Pen"}, {"NAME": "Ink"}}, nil
+		case "PRICE":
+			return scope.Item["NAME"].(string) + " price " + strings.Repeat("$", scope.Index+1), nil
+		}
+		return nil, nil
+	}
+
+	docxWriter := ResolverWriter(context.Background(), resolver, nil)()
+	outputContent, err := docxWriter(inputContent)
+	if err != nil {
+		t.Fatalf("DocxWriter returned an error: %v", err)
+	}
+
+	expectedOutput := "<w:t>Ali &amp; Co owes 1.5</w:t><w:t>Pen: Pen price $</w:t><w:t>Ink: Ink price $$</w:t><w:t>Ali &amp; Co</w:t>"
+	if outputContent != expectedOutput {
+		t.Errorf("Expected '%s', got '%s'", expectedOutput, outputContent)
+	}
+	if calls["USER"] != 1 || calls["PRICE"] != 2 || calls["NAME"] != 0 {
+		t.Errorf("Expected one call per key and scope, got %v", calls)
+	}
+
+	// The memo lives for a single render, so a new writer asks again.
+	if _, err := ResolverWriter(context.Background(), resolver, nil)()("{{USER}}"); err != nil || calls["USER"] != 2 {
+		t.Errorf("Expected a fresh render to resolve again, got %v (%v)", calls, err)
+	}
+}
+
+func TestResolverWriter_ErrorsReportTheKey(t *testing.T) {
+	failure := errors.New("cache unavailable")
+	resolver := func(ctx context.Context, key string, scope Scope) (any, error) {
+		if key == "RATE" {
+			return nil, failure
+		}
+		return "x", nil
+	}
+
+	_, err := ResolverWriter(context.Background(), resolver, nil)()("{{USER}} {{RATE}}")
+	if !errors.Is(err, failure) || !strings.Contains(err.Error(), `"RATE"`) {
+		t.Errorf("Expected the failing key in the error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ResolverWriter(ctx, resolver, nil)()("{{USER}}"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled context to abort rendering, got %v", err)
+	}
+}
+
+func TestResolverWriter_InvalidLoopValue(t *testing.T) {
+	resolver := func(ctx context.Context, key string, scope Scope) (any, error) {
+		return "not a list", nil
+	}
+
+	if _, err := ResolverWriter(context.Background(), resolver, nil)()("{{#each items}}{{NAME}}{{/each}}"); err == nil {
+		t.Errorf("Expected an error for a loop value that is not a list")
+	}
+}
+
+func TestUpdateDocx_ResolverErrorKeepsFile(t *testing.T) {
+	tempDir := t.TempDir()
+	testFilePath := tempDir + "/test.docx"
+	createTestDocx(testFilePath, "<w:t>{{MISSING}}</w:t>")
+	resolver := func(ctx context.Context, key string, scope Scope) (any, error) {
+		return nil, errors.New("unknown key")
+	}
+
+	err := UpdateDocx(testFilePath, ResolverWriter(context.Background(), resolver, nil))
+	if err == nil || !strings.Contains(err.Error(), "MISSING") {
+		t.Fatalf("Expected the failing key in the error, got %v", err)
+	}
+	verifyUpdatedDocx(t, testFilePath, "<w:t>{{MISSING}}</w:t>")
+	if _, err := os.Stat(testFilePath + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("Expected the temporary file to be removed, got %v", err)
+	}
+}
+
+func TestResolverWriter_TypedErrors(t *testing.T) {
+	resolver := MapResolver(map[string]any{"items": []map[string]any{{"NAME": "Pen"}}})
+
+	_, err := ResolverWriter(context.Background(), resolver, nil)()("{{#each items}}{{NAME}} {{PRICE}}{{/each}}")
+	var missingErr *docxerr.MissingKeyError
+	if !errors.As(err, &missingErr) || missingErr.Key != "PRICE" || !errors.Is(err, docxerr.ErrMissingKey) {
+		t.Errorf("Expected a MissingKeyError for PRICE, got %v", err)
+	}
+
+	tests := map[string]string{
+		"{{#each items}}{{NAME}}":                          "{{#each}} without a matching {{/each}}",
+		"<w:t>{{/each}}</w:t>":                             "{{/each}} without a matching {{#each}}",
+		"{{#each items}}{{#each items}}{{/each}}{{/each}}": "nested {{#each}} loops are not supported",
+	}
+	for content, msg := range tests {
+		_, err := ResolverWriter(context.Background(), resolver, nil)()(content)
+		var syntaxErr *docxerr.TemplateSyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.Msg != msg || syntaxErr.Line != 1 {
+			t.Errorf("Expected syntax error '%s' for %s, got %v", msg, content, err)
+		}
+	}
+}
+
+func TestUpdateDocx_TypedErrorsKnowTheirPart(t *testing.T) {
+	tempDir := t.TempDir()
+	testFilePath := tempDir + "/test.docx"
+	createTestDocx(testFilePath, "<w:t>\n{{#each items}}</w:t>")
+
+	err := UpdateDocx(testFilePath, ResolverWriter(context.Background(), MapResolver(nil), nil))
+	var syntaxErr *docxerr.TemplateSyntaxError
+	if !errors.As(err, &syntaxErr) || syntaxErr.Part != "word/document.xml" || syntaxErr.Line != 2 || syntaxErr.Column != 1 {
+		t.Errorf("Expected a syntax error located in word/document.xml, got %v", err)
+	}
+
+	if err := UpdateDocx(tempDir+"/missing.docx", ResolverWriter(context.Background(), MapResolver(nil), nil)); !errors.Is(err, docxerr.ErrTemplateNotFound) {
+		t.Errorf("Expected ErrTemplateNotFound, got %v", err)
+	}
+}
+
+func TestResolverWriter_Filters(t *testing.T) {
+	resolver := MapResolver(map[string]any{"NAME": "  ada lovelace "})
+
+	outputContent, err := ResolverWriter(context.Background(), resolver, nil)()("{{NAME | trim | title}} / {{ NAME|upper }}")
+	if err != nil {
+		t.Fatalf("DocxWriter returned an error: %v", err)
+	}
+	if outputContent != "Ada Lovelace /   ADA LOVELACE " {
+		t.Errorf("Expected filtered values, got '%s'", outputContent)
+	}
+
+	_, err = ResolverWriter(context.Background(), resolver, nil)()("{{NAME | shout}}")
+	if !errors.Is(err, docxerr.ErrTemplateSyntax) || !strings.Contains(err.Error(), `unknown filter "shout"`) {
+		t.Errorf("Expected an unknown filter error, got %v", err)
+	}
+}
+
+func TestResolverWriter_Locale(t *testing.T) {
+	resolver := MapResolver(map[string]any{
+		"TOTAL": 1234.5,
+		"DUE":   time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC),
+		"COUNT": 3,
+	})
+	content := "{{TOTAL}} | {{TOTAL | number:2}} | {{ TOTAL | currency }} | {{TOTAL|currency:USD}} | {{DUE}} | {{DUE | date:long | upper}} | {{COUNT}}"
+
+	de, _ := locale.Parse("de-DE")
+	outputContent, err := ResolverWriter(context.Background(), resolver, de)()(content)
+	if err != nil {
+		t.Fatalf("DocxWriter returned an error: %v", err)
+	}
+	expectedOutput := "1234,5 | 1.234,50 | 1.234,50\u00a0€ | 1.234,50\u00a0$ | 05.03.2024 | 5. MÄRZ 2024 | 3"
+	if outputContent != expectedOutput {
+		t.Errorf("Expected '%s', got '%s'", expectedOutput, outputContent)
+	}
+
+	ar, _ := locale.Parse("ar-SA")
+	outputContent, err = ResolverWriter(context.Background(), resolver, ar)()("{{TOTAL | number:2}} {{DUE | date:long}} {{COUNT}}")
+	if err != nil {
+		t.Fatalf("DocxWriter returned an error: %v", err)
+	}
+	if expectedOutput := "١٬٢٣٤٫٥٠ ٥ مارس ٢٠٢٤ ٣"; outputContent != expectedOutput {
+		t.Errorf("Expected '%s', got '%s'", expectedOutput, outputContent)
+	}
+
+	// Without a locale values keep their Go formatting and filters use US English.
+	outputContent, err = ResolverWriter(context.Background(), resolver, nil)()("{{TOTAL}} {{TOTAL | currency:EUR}}")
+	if err != nil || outputContent != "1234.5 €1,234.50" {
+		t.Errorf("Expected unlocalized values, got '%s' (%v)", outputContent, err)
+	}
+
+	_, err = ResolverWriter(context.Background(), resolver, nil)()("{{TOTAL | currency:XYZW}}")
+	if err == nil || !strings.Contains(err.Error(), `unknown currency "XYZW"`) {
+		t.Errorf("Expected an unknown currency error, got %v", err)
+	}
+}
+
+func TestResolverWriter_Conditions(t *testing.T) {
+	resolver := MapResolver(map[string]any{
+		"paid":     true,
+		"discount": 0,
+		"notes":    "",
+		"items":    []map[string]any{{"NAME": "Pen", "gift": true}, {"NAME": "Ink", "gift": false}},
+	})
+	inputContent := "{{#if paid}}PAID{{#if discount}} with discount{{/if}}{{/if}}" +
+		"{{#if notes}}Notes{{/if}}" +
+		"{{#each items}}[{{NAME}}{{#if gift}} (gift){{/if}}]{{/each}}"
+
+	outputContent, err := ResolverWriter(context.Background(), resolver, nil)()(inputContent)
+	if err != nil {
+		t.Fatalf("DocxWriter returned an error: %v", err)
+	}
+	if outputContent != "PAID[Pen (gift)][Ink]" {
+		t.Errorf("Expected 'PAID[Pen (gift)][Ink]', got '%s'", outputContent)
+	}
+
+	_, err = ResolverWriter(context.Background(), resolver, nil)()("{{#if paid}}{{#each items}}{{/if}}{{/each}}")
+	if !errors.Is(err, docxerr.ErrTemplateSyntax) || !strings.Contains(err.Error(), "{{/if}} without a matching {{#if}}") {
+		t.Errorf("Expected crossed blocks to be rejected, got %v", err)
+	}
+}
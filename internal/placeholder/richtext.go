@@ -0,0 +1,237 @@
+package placeholder
+
+import (
+	"strings"
+
+	"github.com/aliamerj/docxer/internal/markdown"
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+// RichRun is a piece of text with character formatting. A run with a Link
+// becomes a hyperlink to that URL.
+type RichRun struct {
+	Text      string
+	Bold      bool
+	Italic    bool
+	Underline bool
+	Link      string
+}
+
+// RichParagraph is a paragraph of runs, optionally with a paragraph style.
+type RichParagraph struct {
+	Style string
+	Runs  []RichRun
+}
+
+// RichValue is the value of a rich text placeholder, written either as
+// Markdown or as paragraphs of runs. Markdown takes precedence when both are set.
+type RichValue struct {
+	Markdown   string
+	Paragraphs []RichParagraph
+}
+
+// RichTextWriter replaces {{key}} placeholders with formatted content. A
+// placeholder that is alone in its paragraph is replaced by one paragraph per
+// paragraph of the value, keeping the paragraph properties of the original;
+// a placeholder inside other text is replaced by formatted runs, with
+// paragraphs separated by line breaks. Either way the runs inherit the
+// formatting of the run that held the placeholder.
+func RichTextWriter(values map[string]RichValue) PartWriter {
+	return func(content string, rels *Relations) (string, error) {
+		for key, value := range values {
+			// The value is converted once the placeholder is found, so parts
+			// without it get no hyperlink relationships.
+			var paragraphs []markdown.Paragraph
+			converted := func() []markdown.Paragraph {
+				if paragraphs == nil {
+					paragraphs = richParagraphs(value, func(url string) string {
+						return rels.Add(HyperlinkRelType, url, true)
+					})
+				}
+				return paragraphs
+			}
+			content = replacePlaceholder(content, "{{"+key+"}}",
+				func(paragraph string, _ bool) string {
+					return blockParagraphs(paragraph, converted())
+				},
+				func(runProps string) string {
+					return inlineRuns(runProps, converted())
+				})
+		}
+		return content, nil
+	}
+}
+
+func richParagraphs(value RichValue, link markdown.LinkResolver) []markdown.Paragraph {
+	if value.Markdown != "" {
+		return markdown.Convert(value.Markdown, link)
+	}
+	paragraphs := make([]markdown.Paragraph, 0, len(value.Paragraphs))
+	for _, paragraph := range value.Paragraphs {
+		var runs strings.Builder
+		for _, run := range paragraph.Runs {
+			runs.WriteString(richRun(run, link))
+		}
+		paragraphs = append(paragraphs, markdown.Paragraph{Style: paragraph.Style, Content: runs.String()})
+	}
+	return paragraphs
+}
+
+func richRun(run RichRun, link markdown.LinkResolver) string {
+	var rPr strings.Builder
+	if run.Link != "" {
+		rPr.WriteString(`<w:rStyle w:val="Hyperlink"/>`)
+	}
+	if run.Bold {
+		rPr.WriteString("<w:b/>")
+	}
+	if run.Italic {
+		rPr.WriteString("<w:i/>")
+	}
+	if run.Underline {
+		rPr.WriteString(`<w:u w:val="single"/>`)
+	}
+	runProps := ""
+	if rPr.Len() > 0 {
+		runProps = "<w:rPr>" + rPr.String() + "</w:rPr>"
+	}
+	markup := utils.TextRun(runProps, run.Text)
+	if run.Link != "" {
+		markup = `<w:hyperlink r:id="` + link(run.Link) + `" w:history="1">` + markup + `</w:hyperlink>`
+	}
+	return markup
+}
+
+// blockParagraphs renders paragraphs in place of the paragraph that held the
+// placeholder. A style other than Normal replaces the paragraph's own style.
+func blockParagraphs(paragraph string, paragraphs []markdown.Paragraph) string {
+	paragraphProps := ""
+	if pPr, ok := utils.FindElement(paragraph, "w:pPr", 0); ok {
+		paragraphProps = pPr.Outer(paragraph)
+	}
+	runProps := ""
+	if run, ok := utils.FindElement(paragraph, "w:r", 0); ok {
+		runProps = utils.RunProperties(run.Outer(paragraph))
+	}
+
+	var result strings.Builder
+	for _, p := range paragraphs {
+		result.WriteString("<w:p>")
+		result.WriteString(withStyle(paragraphProps, p.Style))
+		result.WriteString(applyRunProperties(p.Content, runProps))
+		result.WriteString("</w:p>")
+	}
+	return result.String()
+}
+
+func inlineRuns(runProps string, paragraphs []markdown.Paragraph) string {
+	var result strings.Builder
+	for i, p := range paragraphs {
+		if i > 0 {
+			result.WriteString("<w:r>" + runProps + "<w:br/></w:r>")
+		}
+		result.WriteString(applyRunProperties(p.Content, runProps))
+	}
+	return result.String()
+}
+
+func withStyle(paragraphProps, style string) string {
+	if style == "" || style == "Normal" {
+		return paragraphProps
+	}
+	pStyle := `<w:pStyle w:val="` + utils.EscapeXML(style) + `"/>`
+	if paragraphProps == "" {
+		return "<w:pPr>" + pStyle + "</w:pPr>"
+	}
+	if element, ok := utils.FindElement(paragraphProps, "w:pStyle", 0); ok {
+		return paragraphProps[:element.Start] + pStyle + paragraphProps[element.End:]
+	}
+	if strings.HasSuffix(paragraphProps, "/>") {
+		return "<w:pPr>" + pStyle + "</w:pPr>"
+	}
+	start := strings.IndexByte(paragraphProps, '>') + 1
+	return paragraphProps[:start] + pStyle + paragraphProps[start:]
+}
+
+// applyRunProperties merges base into the properties of every run in markup.
+func applyRunProperties(markup, base string) string {
+	if base == "" {
+		return markup
+	}
+	var result strings.Builder
+	last := 0
+	for _, run := range utils.FindElements(markup, "w:r") {
+		outer := run.Outer(markup)
+		own := utils.RunProperties(outer)
+		startTag := run.StartTag(markup)
+		rest := outer[len(startTag):]
+		if own != "" {
+			rest = strings.Replace(rest, own, "", 1)
+		}
+		result.WriteString(markup[last:run.Start])
+		result.WriteString(startTag + utils.MergeRunProperties(base, own) + rest)
+		last = run.End
+	}
+	result.WriteString(markup[last:])
+	return result.String()
+}
+
+// replacePlaceholder replaces every occurrence of placeholder in content. When
+// the placeholder is the only text of its paragraph, block renders the markup
+// that replaces the whole paragraph, told whether the paragraph is the last
+// one of its body, cell, header or other container. Otherwise the run holding the placeholder
+// is split around it and inline renders the markup put in between, given the
+// properties of that run; empty pieces of the split run are dropped. A nil
+// block or inline leaves the placeholders it would handle alone, as are
+// placeholders that are not inside a single w:t, for example because Word
+// split them over several runs.
+func replacePlaceholder(content, placeholder string, block func(paragraph string, last bool) string, inline func(runProps string) string) string {
+	for from := 0; ; {
+		i := strings.Index(content[from:], placeholder)
+		if i == -1 {
+			return content
+		}
+		pos := from + i
+
+		if paragraph, ok := utils.EnclosingElement(content, "w:p", pos); ok && block != nil {
+			outer := paragraph.Outer(content)
+			if strings.TrimSpace(utils.ExtractText(outer)) == placeholder {
+				replacement := block(outer, endsContainer(content[paragraph.End:]))
+				content = content[:paragraph.Start] + replacement + content[paragraph.End:]
+				from = paragraph.Start + len(replacement)
+				continue
+			}
+		}
+
+		if inline == nil {
+			from = pos + len(placeholder)
+			continue
+		}
+		text, okText := utils.EnclosingElement(content, "w:t", pos)
+		run, okRun := utils.EnclosingElement(content, "w:r", pos)
+		if !okText || !okRun || text.Start < run.Start || pos+len(placeholder) > text.InnerEnd {
+			from = pos + len(placeholder)
+			continue
+		}
+
+		runProps := utils.RunProperties(run.Outer(content))
+		preserve := `<w:t xml:space="preserve">`
+		before, after := "", ""
+		if head := content[run.Start:text.Start]; head != run.StartTag(content)+runProps || pos > text.InnerStart {
+			before = head + preserve + content[text.InnerStart:pos] + "</w:t></w:r>"
+		}
+		if tail := content[text.End:run.End]; tail != "</w:r>" || pos+len(placeholder) < text.InnerEnd {
+			after = "<w:r>" + runProps + preserve + content[pos+len(placeholder):text.InnerEnd] + "</w:t>" + tail
+		}
+		replacement := before + inline(runProps) + after
+		content = content[:run.Start] + replacement + content[run.End:]
+		from = run.Start + len(replacement) - len(after)
+	}
+}
+
+// endsContainer reports whether rest, the content after a block, closes the
+// element holding the block or only leaves the section properties of the body.
+func endsContainer(rest string) bool {
+	rest = strings.TrimLeft(rest, " \t\r\n")
+	return strings.HasPrefix(rest, "</") || strings.HasPrefix(rest, "<w:sectPr")
+}
@@ -0,0 +1,101 @@
+package placeholder
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRichTextWriter_MarkdownParagraph(t *testing.T) {
+	inputContent := `<w:body><w:p><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:rPr><w:sz w:val="28"/></w:rPr><w:t>{{DESCRIPTION}}</w:t></w:r></w:p></w:body>`
+	writer := RichTextWriter(map[string]RichValue{
+		"DESCRIPTION": {Markdown: "# Overview\n\nSome **bold** text"},
+	})
+
+	outputContent, err := writer(inputContent, &Relations{})
+	if err != nil {
+		t.Fatalf("PartWriter returned an error: %v", err)
+	}
+
+	heading := `<w:p><w:pPr><w:pStyle w:val="Heading1"/><w:jc w:val="center"/></w:pPr><w:r><w:rPr><w:sz w:val="28"/></w:rPr><w:t xml:space="preserve">Overview</w:t></w:r></w:p>`
+	if !strings.HasPrefix(outputContent, "<w:body>"+heading+`<w:p><w:pPr><w:jc w:val="center"/></w:pPr>`) {
+		t.Errorf("Expected one paragraph per Markdown line, got '%s'", outputContent)
+	}
+	if !strings.Contains(outputContent, `<w:r><w:rPr><w:b/><w:sz w:val="28"/></w:rPr><w:t xml:space="preserve">bold </w:t></w:r>`) {
+		t.Errorf("Expected bold run to keep the placeholder formatting, got '%s'", outputContent)
+	}
+	if strings.Contains(outputContent, "{{DESCRIPTION}}") {
+		t.Errorf("Expected placeholder to be replaced, got '%s'", outputContent)
+	}
+}
+
+func TestRichTextWriter_InlineRunsWithLink(t *testing.T) {
+	inputContent := `<w:p><w:r><w:rPr><w:i/></w:rPr><w:t>See {{PORTAL}} now</w:t></w:r></w:p>`
+	expectedOutput := `<w:p><w:r><w:rPr><w:i/></w:rPr><w:t xml:space="preserve">See </w:t></w:r>` +
+		`<w:hyperlink r:id="rId1" w:history="1"><w:r><w:rPr><w:rStyle w:val="Hyperlink"/><w:i/></w:rPr><w:t xml:space="preserve">portal</w:t></w:r></w:hyperlink>` +
+		`<w:r><w:rPr><w:i/></w:rPr><w:t xml:space="preserve"> now</w:t></w:r></w:p>`
+	writer := RichTextWriter(map[string]RichValue{
+		"PORTAL": {Paragraphs: []RichParagraph{{Runs: []RichRun{{Text: "portal", Link: "https://example.com/?a=1&b=2"}}}}},
+	})
+
+	rels := &Relations{}
+	outputContent, err := writer(inputContent, rels)
+	if err != nil {
+		t.Fatalf("PartWriter returned an error: %v", err)
+	}
+
+	if outputContent != expectedOutput {
+		t.Errorf("Expected '%s', got '%s'", expectedOutput, outputContent)
+	}
+	if !rels.changed || !strings.Contains(relsContent(rels), `Target="https://example.com/?a=1&amp;b=2" TargetMode="External"`) {
+		t.Errorf("Expected an external hyperlink relationship, got '%s'", relsContent(rels))
+	}
+}
+
+func TestRichTextWriter_LinkWithoutPlaceholder(t *testing.T) {
+	inputContent := `<w:p><w:r><w:t>No placeholder here</w:t></w:r></w:p>`
+	writer := RichTextWriter(map[string]RichValue{
+		"PORTAL": {Markdown: "[portal](https://example.com)"},
+	})
+
+	rels := &Relations{}
+	outputContent, err := writer(inputContent, rels)
+	if err != nil {
+		t.Fatalf("PartWriter returned an error: %v", err)
+	}
+
+	if outputContent != inputContent {
+		t.Errorf("Expected '%s', got '%s'", inputContent, outputContent)
+	}
+	if rels.changed {
+		t.Errorf("Expected no hyperlink relationship, got '%s'", relsContent(rels))
+	}
+}
+
+func TestRichTextWriter_InlineParagraphsBecomeBreaks(t *testing.T) {
+	inputContent := `<w:p><w:r><w:t>Note: {{NOTE}}</w:t></w:r></w:p>`
+	writer := RichTextWriter(map[string]RichValue{
+		"NOTE": {Paragraphs: []RichParagraph{
+			{Runs: []RichRun{{Text: "first", Bold: true}}},
+			{Runs: []RichRun{{Text: "second", Underline: true}}},
+		}},
+	})
+
+	outputContent, err := writer(inputContent, &Relations{})
+	if err != nil {
+		t.Fatalf("PartWriter returned an error: %v", err)
+	}
+
+	expected := `<w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">first</w:t></w:r><w:r><w:br/></w:r><w:r><w:rPr><w:u w:val="single"/></w:rPr><w:t xml:space="preserve">second</w:t></w:r>`
+	if !strings.Contains(outputContent, expected) {
+		t.Errorf("Expected runs separated by a break, got '%s'", outputContent)
+	}
+}
+
+func TestRichTextWriter_SplitPlaceholderIsLeftAlone(t *testing.T) {
+	inputContent := `<w:p><w:r><w:t>{{NO</w:t></w:r><w:r><w:t>TE}} and more</w:t></w:r></w:p>`
+	writer := RichTextWriter(map[string]RichValue{"NOTE": {Markdown: "text"}})
+
+	if outputContent, err := writer(inputContent, &Relations{}); err != nil || outputContent != inputContent {
+		t.Errorf("Expected content to be unchanged, got '%s'", outputContent)
+	}
+}
@@ -0,0 +1,303 @@
+package placeholder
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aliamerj/docxer/internal/locale"
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+// defaultTableWidth is the text width of the page in the bundled templates, in
+// twentieths of a point. Columns without a width share it equally.
+const defaultTableWidth = 9972
+
+// Table is the value of a {{table:key}} placeholder. Data is either a
+// [][]string or a slice of structs (or pointers to structs).
+//
+// Without Columns, a [][]string uses its first row as the header and a slice
+// of structs gets one column per exported field, headed by the field name or
+// its `docx` tag; `docx:"-"` skips a field.
+type Table struct {
+	Data     any
+	Columns  []TableColumn
+	Style    string
+	NoHeader bool
+}
+
+// TableColumn describes one column of a generated table. Field names the
+// struct field shown in the column; for [][]string data columns are matched by
+// position. Width is in twentieths of a point, Align is left, center or right
+// and Format is a fmt format such as "%.2f" or a value filter such as
+// "currency:EUR" applied to the value.
+type TableColumn struct {
+	Header string
+	Field  string
+	Width  int
+	Align  string
+	Format string
+}
+
+// TableWriter replaces each paragraph holding a {{table:key}} placeholder with
+// a table built from the data. The cell text keeps the formatting of the run
+// that held the placeholder. Placeholders inside other text are left alone.
+// The paragraph of a table without columns is removed.
+// Cell values are written for loc, or with fmt when it is nil.
+func TableWriter(tables map[string]Table, loc *locale.Locale) (PartWriter, error) {
+	grids := make(map[string]tableGrid, len(tables))
+	for key, table := range tables {
+		grid, err := newTableGrid(table, loc)
+		if err != nil {
+			return nil, fmt.Errorf("table %q: %w", key, err)
+		}
+		grids[key] = grid
+	}
+
+	return func(content string, rels *Relations) (string, error) {
+		for key, grid := range grids {
+			content = replacePlaceholder(content, "{{table:"+key+"}}", func(paragraph string, last bool) string {
+				runProps := ""
+				if run, ok := utils.FindElement(paragraph, "w:r", 0); ok {
+					runProps = utils.RunProperties(run.Outer(paragraph))
+				}
+				table := ""
+				if len(grid.columns) > 0 {
+					table = grid.markup(runProps)
+				}
+				// Cells, headers and footers must end with a paragraph.
+				if last {
+					return table + "<w:p/>"
+				}
+				return table
+			}, nil)
+		}
+		return content, nil
+	}, nil
+}
+
+// tableGrid is a table with its cell values already formatted.
+type tableGrid struct {
+	style   string
+	columns []TableColumn
+	header  []string
+	rows    [][]string
+}
+
+func newTableGrid(table Table, loc *locale.Locale) (tableGrid, error) {
+	grid := tableGrid{style: table.Style, columns: table.Columns}
+	if grid.style == "" {
+		grid.style = "TableGrid"
+	}
+
+	switch data := table.Data.(type) {
+	case [][]string:
+		rows := data
+		if len(grid.columns) == 0 {
+			width := 0
+			for _, row := range data {
+				width = max(width, len(row))
+			}
+			grid.columns = make([]TableColumn, width)
+			if !table.NoHeader && len(data) > 0 {
+				for i, header := range data[0] {
+					grid.columns[i].Header = header
+				}
+				rows = data[1:]
+			}
+		}
+		for _, row := range rows {
+			formatted := make([]string, len(grid.columns))
+			for j := range formatted {
+				if j < len(row) {
+					cell, err := formatCell(row[j], grid.columns[j].Format, loc)
+					if err != nil {
+						return tableGrid{}, err
+					}
+					formatted[j] = cell
+				}
+			}
+			grid.rows = append(grid.rows, formatted)
+		}
+	default:
+		rows, columns, err := structRows(table.Data, grid.columns, loc)
+		if err != nil {
+			return tableGrid{}, err
+		}
+		grid.rows, grid.columns = rows, columns
+	}
+
+	if !table.NoHeader {
+		grid.header = make([]string, len(grid.columns))
+		for i, column := range grid.columns {
+			grid.header[i] = column.Header
+		}
+	}
+	return grid, nil
+}
+
+func structRows(data any, columns []TableColumn, loc *locale.Locale) ([][]string, []TableColumn, error) {
+	value := reflect.ValueOf(data)
+	if value.Kind() != reflect.Slice {
+		return nil, nil, fmt.Errorf("unsupported table data %T, expected [][]string or a slice of structs", data)
+	}
+	elemType := value.Type().Elem()
+	if elemType.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("unsupported table data %T, expected [][]string or a slice of structs", data)
+	}
+
+	if len(columns) == 0 {
+		for i := 0; i < elemType.NumField(); i++ {
+			field := elemType.Field(i)
+			header := field.Tag.Get("docx")
+			if !field.IsExported() || header == "-" {
+				continue
+			}
+			if header == "" {
+				header = field.Name
+			}
+			columns = append(columns, TableColumn{Header: header, Field: field.Name})
+		}
+	}
+	for _, column := range columns {
+		if _, ok := elemType.FieldByName(column.Field); !ok {
+			return nil, nil, fmt.Errorf("field %q not found in %s", column.Field, elemType)
+		}
+	}
+
+	rows := make([][]string, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		item := reflect.Indirect(value.Index(i))
+		row := make([]string, len(columns))
+		if item.IsValid() {
+			for j, column := range columns {
+				cell, err := formatCell(item.FieldByName(column.Field).Interface(), column.Format, loc)
+				if err != nil {
+					return nil, nil, err
+				}
+				row[j] = cell
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, columns, nil
+}
+
+// formatCell applies a fmt format or a value filter to a cell value. Strings
+// holding numbers are formatted as numbers, so "%.2f" also works for
+// [][]string data. With a locale, numbers use its separators and digits.
+func formatCell(value any, format string, loc *locale.Locale) (string, error) {
+	base, _, _ := strings.Cut(format, ":")
+	if _, ok := valueFilters[base]; ok {
+		return formatValue(value, []string{format}, loc)
+	}
+	if format == "" {
+		if loc != nil {
+			return loc.Format(value), nil
+		}
+		return fmt.Sprint(value), nil
+	}
+
+	if text, ok := value.(string); ok {
+		verb := format[len(format)-1]
+		switch verb {
+		case 'd', 'x', 'X', 'o', 'b':
+			if n, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64); err == nil {
+				value = n
+			}
+		case 'f', 'F', 'e', 'E', 'g', 'G':
+			if n, err := strconv.ParseFloat(strings.TrimSpace(text), 64); err == nil {
+				value = n
+			}
+		}
+	}
+	text := fmt.Sprintf(format, value)
+	if _, isString := value.(string); !isString && loc != nil {
+		if _, isNumber := utils.ToNumber(value); isNumber {
+			text = loc.Localize(text)
+		}
+	}
+	return text, nil
+}
+
+func (g tableGrid) markup(runProps string) string {
+	widths := g.widths()
+	var result strings.Builder
+	result.WriteString(`<w:tbl><w:tblPr><w:tblStyle w:val="` + utils.EscapeXML(g.style) + `"/>`)
+	result.WriteString(`<w:tblW w:w="` + strconv.Itoa(sum(widths)) + `" w:type="dxa"/>`)
+	result.WriteString(`<w:tblLayout w:type="fixed"/>`)
+	result.WriteString(`<w:tblLook w:val="04A0" w:firstRow="` + boolAttr(g.header != nil) + `" w:lastRow="0" w:firstColumn="0" w:lastColumn="0" w:noHBand="0" w:noVBand="1"/>`)
+	result.WriteString("</w:tblPr><w:tblGrid>")
+	for _, width := range widths {
+		result.WriteString(`<w:gridCol w:w="` + strconv.Itoa(width) + `"/>`)
+	}
+	result.WriteString("</w:tblGrid>")
+
+	if g.header != nil {
+		headerProps := utils.MergeRunProperties(runProps, "<w:rPr><w:b/></w:rPr>")
+		g.writeRow(&result, "<w:trPr><w:tblHeader/></w:trPr>", g.header, widths, headerProps)
+	}
+	for _, row := range g.rows {
+		g.writeRow(&result, "", row, widths, runProps)
+	}
+	result.WriteString("</w:tbl>")
+	return result.String()
+}
+
+func (g tableGrid) writeRow(result *strings.Builder, rowProps string, cells []string, widths []int, runProps string) {
+	result.WriteString("<w:tr>" + rowProps)
+	for i, column := range g.columns {
+		result.WriteString(`<w:tc><w:tcPr><w:tcW w:w="` + strconv.Itoa(widths[i]) + `" w:type="dxa"/></w:tcPr><w:p>`)
+		if column.Align != "" {
+			result.WriteString(`<w:pPr><w:jc w:val="` + utils.EscapeXML(column.Align) + `"/></w:pPr>`)
+		}
+		if i < len(cells) && cells[i] != "" {
+			result.WriteString(utils.TextRun(runProps, cells[i]))
+		}
+		result.WriteString("</w:p></w:tc>")
+	}
+	result.WriteString("</w:tr>")
+}
+
+// widths returns the width of every column, sharing the space left by
+// columns with a width between those without one.
+func (g tableGrid) widths() []int {
+	widths := make([]int, len(g.columns))
+	remaining, unset := defaultTableWidth, 0
+	for i, column := range g.columns {
+		widths[i] = column.Width
+		if column.Width > 0 {
+			remaining -= column.Width
+		} else {
+			unset++
+		}
+	}
+	if unset > 0 {
+		share := max(remaining/unset, 0)
+		for i := range widths {
+			if widths[i] <= 0 {
+				widths[i] = share
+			}
+		}
+	}
+	return widths
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
+
+func boolAttr(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,162 @@
+package placeholder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aliamerj/docxer/internal/locale"
+)
+
+func TestTableWriter_StringRows(t *testing.T) {
+	inputContent := `<w:body><w:p><w:r><w:rPr><w:sz w:val="18"/></w:rPr><w:t>{{table:results}}</w:t></w:r></w:p><w:p/></w:body>`
+	writer, err := TableWriter(map[string]Table{
+		"results": {
+			Data: [][]string{{"Name", "Score"}, {"Ada", "9.5"}, {"Linus", "7"}},
+			Columns: []TableColumn{
+				{Header: "Name", Width: 3000},
+				{Header: "Score", Width: 1500, Align: "right", Format: "%.2f"},
+			},
+			Style: "LightList",
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("TableWriter returned an error: %v", err)
+	}
+
+	outputContent, err := writer(inputContent, &Relations{})
+	if err != nil {
+		t.Fatalf("PartWriter returned an error: %v", err)
+	}
+
+	if !strings.HasPrefix(outputContent, `<w:body><w:tbl><w:tblPr><w:tblStyle w:val="LightList"/><w:tblW w:w="4500" w:type="dxa"/>`) {
+		t.Errorf("Expected the paragraph to be replaced by a styled table, got '%s'", outputContent)
+	}
+	if !strings.HasSuffix(outputContent, `</w:tbl><w:p/></w:body>`) {
+		t.Errorf("Expected the following paragraph to be kept, got '%s'", outputContent)
+	}
+	if !strings.Contains(outputContent, `<w:gridCol w:w="3000"/><w:gridCol w:w="1500"/>`) {
+		t.Errorf("Expected the column widths in the grid, got '%s'", outputContent)
+	}
+	header := `<w:tr><w:trPr><w:tblHeader/></w:trPr><w:tc><w:tcPr><w:tcW w:w="3000" w:type="dxa"/></w:tcPr><w:p><w:r><w:rPr><w:b/><w:sz w:val="18"/></w:rPr><w:t xml:space="preserve">Name</w:t></w:r></w:p></w:tc>`
+	if !strings.Contains(outputContent, header) {
+		t.Errorf("Expected a bold repeating header row, got '%s'", outputContent)
+	}
+	cell := `<w:p><w:pPr><w:jc w:val="right"/></w:pPr><w:r><w:rPr><w:sz w:val="18"/></w:rPr><w:t xml:space="preserve">7.00</w:t></w:r></w:p>`
+	if !strings.Contains(outputContent, cell) {
+		t.Errorf("Expected a right aligned, formatted cell, got '%s'", outputContent)
+	}
+	// The header row given in Columns is not data, so the first row is kept.
+	if strings.Count(outputContent, "<w:tr>") != 4 {
+		t.Errorf("Expected a header and three data rows, got '%s'", outputContent)
+	}
+}
+
+func TestTableWriter_StructRows(t *testing.T) {
+	type result struct {
+		Name   string
+		Amount float64 `docx:"Total"`
+		secret string
+		Notes  string `docx:"-"`
+	}
+	writer, err := TableWriter(map[string]Table{
+		"results": {Data: []*result{{Name: "Ada", Amount: 12.5}, nil}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("TableWriter returned an error: %v", err)
+	}
+
+	outputContent, err := writer(`<w:p><w:r><w:t>{{table:results}}</w:t></w:r></w:p>`, &Relations{})
+	if err != nil {
+		t.Fatalf("PartWriter returned an error: %v", err)
+	}
+
+	if !strings.Contains(outputContent, `<w:tblStyle w:val="TableGrid"/>`) {
+		t.Errorf("Expected the default table style, got '%s'", outputContent)
+	}
+	if !strings.Contains(outputContent, `<w:gridCol w:w="4986"/><w:gridCol w:w="4986"/></w:tblGrid>`) {
+		t.Errorf("Expected two columns sharing the page width, got '%s'", outputContent)
+	}
+	for _, text := range []string{">Name<", ">Total<", ">Ada<", ">12.5<"} {
+		if !strings.Contains(outputContent, text) {
+			t.Errorf("Expected '%s' in the table, got '%s'", text, outputContent)
+		}
+	}
+	if strings.Contains(outputContent, "Notes") {
+		t.Errorf("Expected skipped fields to be left out, got '%s'", outputContent)
+	}
+}
+
+func TestTableWriter_InlinePlaceholderAndBadData(t *testing.T) {
+	writer, err := TableWriter(map[string]Table{"results": {Data: [][]string{{"A"}}}}, nil)
+	if err != nil {
+		t.Fatalf("TableWriter returned an error: %v", err)
+	}
+	inputContent := `<w:p><w:r><w:t>See {{table:results}}</w:t></w:r></w:p>`
+	if outputContent, err := writer(inputContent, &Relations{}); err != nil || outputContent != inputContent {
+		t.Errorf("Expected an inline placeholder to be left alone, got '%s'", outputContent)
+	}
+
+	if _, err := TableWriter(map[string]Table{"results": {Data: []int{1}}}, nil); err == nil {
+		t.Errorf("Expected an error for unsupported table data")
+	}
+	if _, err := TableWriter(map[string]Table{"results": {Data: []struct{ A string }{}, Columns: []TableColumn{{Field: "B"}}}}, nil); err == nil {
+		t.Errorf("Expected an error for an unknown field")
+	}
+}
+
+func TestTableWriter_LastParagraphAndNoColumns(t *testing.T) {
+	inputContent := `<w:hdr><w:p><w:r><w:t>{{table:totals}}</w:t></w:r></w:p></w:hdr>` +
+		`<w:tc><w:p><w:r><w:t>{{table:empty}}</w:t></w:r></w:p></w:tc>` +
+		`<w:body><w:p><w:r><w:t>{{table:empty}}</w:t></w:r></w:p><w:p/></w:body>`
+	writer, err := TableWriter(map[string]Table{
+		"totals": {Data: [][]string{{"Total"}, {"42"}}},
+		"empty":  {Data: [][]string{}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("TableWriter returned an error: %v", err)
+	}
+
+	outputContent, err := writer(inputContent, &Relations{})
+	if err != nil {
+		t.Fatalf("PartWriter returned an error: %v", err)
+	}
+
+	if !strings.Contains(outputContent, `</w:tbl><w:p/></w:hdr>`) {
+		t.Errorf("Expected a paragraph after a table ending the header, got '%s'", outputContent)
+	}
+	if !strings.HasSuffix(outputContent, `<w:tc><w:p/></w:tc><w:body><w:p/></w:body>`) {
+		t.Errorf("Expected a table without columns to write nothing but the paragraph a cell needs, got '%s'", outputContent)
+	}
+}
+
+func TestTableWriter_Locale(t *testing.T) {
+	type line struct {
+		Item  string
+		Price float64
+		Total float64
+	}
+	de, _ := locale.Parse("de-DE")
+	writer, err := TableWriter(map[string]Table{
+		"lines": {
+			Data:    []line{{Item: "Stift", Price: 1234.5, Total: 2469}},
+			Columns: []TableColumn{{Field: "Item"}, {Field: "Price", Format: "%.2f"}, {Field: "Total", Format: "currency:EUR"}},
+		},
+	}, de)
+	if err != nil {
+		t.Fatalf("TableWriter returned an error: %v", err)
+	}
+
+	outputContent, err := writer(`<w:p><w:r><w:t>{{table:lines}}</w:t></w:r></w:p>`, &Relations{})
+	if err != nil {
+		t.Fatalf("PartWriter returned an error: %v", err)
+	}
+	for _, text := range []string{">Stift<", ">1234,50<", ">2.469,00\u00a0€<"} {
+		if !strings.Contains(outputContent, text) {
+			t.Errorf("Expected a cell with %q, got '%s'", text, outputContent)
+		}
+	}
+
+	if _, err := TableWriter(map[string]Table{"lines": {Data: [][]string{{"1"}}, Columns: []TableColumn{{Format: "currency:XYZW"}}}}, nil); err == nil {
+		t.Errorf("Expected an error for an unknown currency")
+	}
+}
@@ -0,0 +1,407 @@
+package properties
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aliamerj/docxer/internal/opc"
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+const (
+	corePath   = "docProps/core.xml"
+	appPath    = "docProps/app.xml"
+	customPath = "docProps/custom.xml"
+
+	coreRelType   = "http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties"
+	appRelType    = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/extended-properties"
+	customRelType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/custom-properties"
+
+	coreContentType   = "application/vnd.openxmlformats-package.core-properties+xml"
+	appContentType    = "application/vnd.openxmlformats-officedocument.extended-properties+xml"
+	customContentType = "application/vnd.openxmlformats-officedocument.custom-properties+xml"
+
+	customFormatID = "{D5CDD505-2E9C-101B-9397-08002B2CF9AE}"
+)
+
+// Core holds the core properties stored in docProps/core.xml.
+type Core struct {
+	Title          string
+	Subject        string
+	Creator        string
+	Keywords       string
+	Description    string
+	LastModifiedBy string
+	Category       string
+	ContentStatus  string
+	Revision       string
+	Created        time.Time
+	Modified       time.Time
+}
+
+// App holds the application properties stored in docProps/app.xml.
+type App struct {
+	Application   string
+	AppVersion    string
+	Company       string
+	Manager       string
+	Template      string
+	HyperlinkBase string
+}
+
+// Properties are the document properties of a DOCX package. Custom values
+// may be strings, bools, integers, floats or time.Time.
+type Properties struct {
+	Core   Core
+	App    App
+	Custom map[string]any
+}
+
+type coreField struct {
+	element string
+	get     func(*Core) *string
+}
+
+var coreFields = []coreField{
+	{"dc:title", func(c *Core) *string { return &c.Title }},
+	{"dc:subject", func(c *Core) *string { return &c.Subject }},
+	{"dc:creator", func(c *Core) *string { return &c.Creator }},
+	{"cp:keywords", func(c *Core) *string { return &c.Keywords }},
+	{"dc:description", func(c *Core) *string { return &c.Description }},
+	{"cp:lastModifiedBy", func(c *Core) *string { return &c.LastModifiedBy }},
+	{"cp:revision", func(c *Core) *string { return &c.Revision }},
+	{"cp:category", func(c *Core) *string { return &c.Category }},
+	{"cp:contentStatus", func(c *Core) *string { return &c.ContentStatus }},
+}
+
+type appField struct {
+	element string
+	get     func(*App) *string
+}
+
+var appFields = []appField{
+	{"Application", func(a *App) *string { return &a.Application }},
+	{"AppVersion", func(a *App) *string { return &a.AppVersion }},
+	{"Company", func(a *App) *string { return &a.Company }},
+	{"Manager", func(a *App) *string { return &a.Manager }},
+	{"Template", func(a *App) *string { return &a.Template }},
+	{"HyperlinkBase", func(a *App) *string { return &a.HyperlinkBase }},
+}
+
+// Read returns the core, application and custom properties of a DOCX file.
+// Missing property parts leave the corresponding values empty.
+func Read(filePath string) (Properties, error) {
+	parts, err := utils.ReadDocx(filePath)
+	if err != nil {
+		return Properties{}, err
+	}
+	return readParts(parts)
+}
+
+func readParts(parts []utils.DocxPart) (Properties, error) {
+	var props Properties
+	if i := utils.FindPart(parts, corePath); i != -1 {
+		values, err := elementTexts(parts[i].Content)
+		if err != nil {
+			return Properties{}, fmt.Errorf("error parsing %s: %w", corePath, err)
+		}
+		for _, field := range coreFields {
+			*field.get(&props.Core) = values[localName(field.element)]
+		}
+		props.Core.Created, _ = time.Parse(time.RFC3339, values["created"])
+		props.Core.Modified, _ = time.Parse(time.RFC3339, values["modified"])
+	}
+	if i := utils.FindPart(parts, appPath); i != -1 {
+		values, err := elementTexts(parts[i].Content)
+		if err != nil {
+			return Properties{}, fmt.Errorf("error parsing %s: %w", appPath, err)
+		}
+		for _, field := range appFields {
+			*field.get(&props.App) = values[field.element]
+		}
+	}
+	if i := utils.FindPart(parts, customPath); i != -1 {
+		custom, err := readCustom(parts[i].Content)
+		if err != nil {
+			return Properties{}, fmt.Errorf("error parsing %s: %w", customPath, err)
+		}
+		props.Custom = custom
+	}
+	return props, nil
+}
+
+// elementTexts maps the local name of each child of the root element to its text.
+func elementTexts(content []byte) (map[string]string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	values := map[string]string{}
+	depth := 0
+	current := ""
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return values, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 2 {
+				current = t.Name.Local
+				values[current] = ""
+			}
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth == 2 {
+				values[current] += string(t)
+			}
+		}
+	}
+}
+
+func readCustom(content []byte) (map[string]any, error) {
+	var parsed struct {
+		Properties []struct {
+			Name  string `xml:"name,attr"`
+			Value struct {
+				XMLName xml.Name
+				Text    string `xml:",chardata"`
+			} `xml:",any"`
+		} `xml:"property"`
+	}
+	if err := xml.Unmarshal(content, &parsed); err != nil {
+		return nil, err
+	}
+	custom := map[string]any{}
+	for _, property := range parsed.Properties {
+		custom[property.Name] = customValue(property.Value.XMLName.Local, property.Value.Text)
+	}
+	return custom, nil
+}
+
+func customValue(kind, text string) any {
+	switch kind {
+	case "bool":
+		return text == "true" || text == "1"
+	case "i1", "i2", "i4", "i8", "int", "ui1", "ui2", "ui4", "ui8", "uint":
+		if n, err := strconv.Atoi(text); err == nil {
+			return n
+		}
+	case "r4", "r8", "decimal":
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f
+		}
+	case "filetime", "date":
+		if t, err := time.Parse(time.RFC3339, text); err == nil {
+			return t
+		}
+	}
+	return text
+}
+
+// Write stores props in the DOCX file. Core and application properties are
+// updated in place, so elements this package does not know about (such as
+// page and word counts) are kept; empty values remove their element. Custom
+// properties replace docProps/custom.xml as a whole. Cached results of
+// DOCPROPERTY and document information fields are refreshed afterwards.
+func Write(filePath string, props Properties) error {
+	pkg, err := opc.Open(filePath)
+	if err != nil {
+		return err
+	}
+	if err := writeParts(pkg, props); err != nil {
+		return err
+	}
+	return pkg.Save(filePath)
+}
+
+func writeParts(pkg *opc.Package, props Properties) error {
+	if _, ok := pkg.Part(utils.ContentTypesPath); !ok {
+		return fmt.Errorf("the document has no %s", utils.ContentTypesPath)
+	}
+
+	core := emptyCore
+	if content, ok := pkg.Part(corePath); ok {
+		core = string(content)
+	}
+	for _, field := range coreFields {
+		core = setElement(core, field.element, "", *field.get(&props.Core))
+	}
+	core = setElement(core, "dcterms:created", ` xsi:type="dcterms:W3CDTF"`, formatTime(props.Core.Created))
+	core = setElement(core, "dcterms:modified", ` xsi:type="dcterms:W3CDTF"`, formatTime(props.Core.Modified))
+	pkg.SetPart(corePath, coreContentType, []byte(core))
+	pkg.Relate("", coreRelType, corePath)
+
+	app := emptyApp
+	if content, ok := pkg.Part(appPath); ok {
+		app = string(content)
+	}
+	for _, field := range appFields {
+		app = setElement(app, field.element, "", *field.get(&props.App))
+	}
+	pkg.SetPart(appPath, appContentType, []byte(app))
+	pkg.Relate("", appRelType, appPath)
+
+	if _, ok := pkg.Part(customPath); props.Custom != nil || ok {
+		pkg.SetPart(customPath, customContentType, []byte(customXML(props.Custom)))
+		pkg.Relate("", customRelType, customPath)
+	}
+
+	return pkg.Transform(utils.TransformerFunc(func(name, _ string, content []byte) ([]byte, error) {
+		if strings.HasPrefix(name, "word/") && strings.HasSuffix(name, ".xml") {
+			return []byte(UpdateFields(string(content), props)), nil
+		}
+		return content, nil
+	}))
+}
+
+const emptyCore = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+	`<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:dcmitype="http://purl.org/dc/dcmitype/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"></cp:coreProperties>`
+
+const emptyApp = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+	`<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/extended-properties" xmlns:vt="http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes"></Properties>`
+
+// setElement sets the text of a direct child of the root element, adding the
+// child before the root's end tag when needed and removing it when value is empty.
+func setElement(content, name, attrs, value string) string {
+	element, found := utils.FindElement(content, name, 0)
+	if value == "" {
+		if !found {
+			return content
+		}
+		return content[:element.Start] + content[element.End:]
+	}
+	markup := "<" + name + attrs + ">" + utils.EscapeXML(value) + "</" + name + ">"
+	if found {
+		return content[:element.Start] + markup + content[element.End:]
+	}
+	end := strings.LastIndex(content, "</")
+	if end == -1 {
+		return content
+	}
+	return content[:end] + markup + content[end:]
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
+
+func customXML(custom map[string]any) string {
+	names := make([]string, 0, len(custom))
+	for name := range custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result strings.Builder
+	result.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	result.WriteString(`<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/custom-properties" xmlns:vt="http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes">`)
+	for i, name := range names {
+		kind, text := customVariant(custom[name])
+		fmt.Fprintf(&result, `<property fmtid="%s" pid="%d" name="%s"><vt:%s>%s</vt:%s></property>`,
+			customFormatID, i+2, utils.EscapeXML(name), kind, utils.EscapeXML(text), kind)
+	}
+	result.WriteString(`</Properties>`)
+	return result.String()
+}
+
+func customVariant(value any) (string, string) {
+	switch v := value.(type) {
+	case bool:
+		return "bool", strconv.FormatBool(v)
+	case int, int8, int16, int32, uint8, uint16:
+		return "i4", fmt.Sprint(v)
+	case int64, uint, uint32, uint64:
+		return "i8", fmt.Sprint(v)
+	case float32, float64:
+		return "r8", fmt.Sprint(v)
+	case time.Time:
+		return "filetime", formatTime(v)
+	default:
+		return "lpwstr", fmt.Sprint(v)
+	}
+}
+
+// Value returns the value of a property by the name Word uses for it in
+// DOCPROPERTY fields, such as "Title", "Author" or "Company", falling back to
+// custom properties. Names are matched case-insensitively.
+func (p Properties) Value(name string) (string, bool) {
+	builtIn := map[string]string{
+		"title":          p.Core.Title,
+		"subject":        p.Core.Subject,
+		"author":         p.Core.Creator,
+		"creator":        p.Core.Creator,
+		"keywords":       p.Core.Keywords,
+		"comments":       p.Core.Description,
+		"description":    p.Core.Description,
+		"lastsavedby":    p.Core.LastModifiedBy,
+		"lastmodifiedby": p.Core.LastModifiedBy,
+		"category":       p.Core.Category,
+		"contentstatus":  p.Core.ContentStatus,
+		"revisionnumber": p.Core.Revision,
+		"company":        p.App.Company,
+		"manager":        p.App.Manager,
+		"template":       p.App.Template,
+		"hyperlinkbase":  p.App.HyperlinkBase,
+	}
+	if value, ok := builtIn[strings.ToLower(name)]; ok {
+		return value, true
+	}
+	for key, value := range p.Custom {
+		if strings.EqualFold(key, name) {
+			if t, ok := value.(time.Time); ok {
+				return t.Format("2006-01-02"), true
+			}
+			return fmt.Sprint(value), true
+		}
+	}
+	return "", false
+}
+
+// UpdateFields refreshes the cached results of DOCPROPERTY fields and of the
+// document information fields (TITLE, AUTHOR, SUBJECT, KEYWORDS, COMMENTS,
+// LASTSAVEDBY) so the part shows props before Word recalculates its fields.
+func UpdateFields(content string, props Properties) string {
+	fields := utils.FindFields(content)
+	for i := len(fields) - 1; i >= 0; i-- {
+		field := fields[i]
+		args := utils.FieldArgs(field.Instr)
+		if len(args) == 0 {
+			continue
+		}
+		name := ""
+		switch field.Name() {
+		case "DOCPROPERTY":
+			if len(args) > 1 {
+				name = args[1]
+			}
+		case "TITLE", "AUTHOR", "SUBJECT", "KEYWORDS", "COMMENTS", "LASTSAVEDBY":
+			name = args[0]
+		}
+		if name == "" {
+			continue
+		}
+		if value, ok := props.Value(name); ok {
+			content = utils.SetFieldResult(content, field, value)
+		}
+	}
+	return content
+}
+
+func localName(name string) string {
+	if i := strings.IndexByte(name, ':'); i != -1 {
+		return name[i+1:]
+	}
+	return name
+}
@@ -0,0 +1,159 @@
+package properties
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aliamerj/docxer/internal/utils"
+)
+
+func TestWriteAndRead(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "testProperties")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	filePath := filepath.Join(tempDir, "props.docx")
+	createTestDocx(t, filePath, map[string]string{
+		"[Content_Types].xml": `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"></Types>`,
+		"docProps/app.xml":    `<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/extended-properties"><Pages>3</Pages><Company>Old</Company></Properties>`,
+		"word/document.xml":   `<w:p><w:fldSimple w:instr=" DOCPROPERTY  Company "><w:r><w:t>Old</w:t></w:r></w:fldSimple><w:fldSimple w:instr=" TITLE "><w:r><w:t>x</w:t></w:r></w:fldSimple><w:fldSimple w:instr=" DOCPROPERTY &quot;Case Number&quot; "><w:r><w:t>0</w:t></w:r></w:fldSimple></w:p>`,
+	})
+
+	created := time.Date(2024, time.April, 30, 10, 0, 0, 0, time.UTC)
+	props := Properties{
+		Core: Core{Title: "Invoice {{NUMBER}}", Creator: "Ali", Keywords: "invoice, 2024", Created: created},
+		App:  App{Company: "ACME & Co"},
+		Custom: map[string]any{
+			"Case Number": 1234,
+			"Approved":    true,
+			"Due":         created,
+			"Client":      "Berlin",
+		},
+	}
+	if err := Write(filePath, props); err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+
+	got, err := Read(filePath)
+	if err != nil {
+		t.Fatalf("Read returned an error: %v", err)
+	}
+	if got.Core.Title != props.Core.Title || got.Core.Creator != "Ali" || got.Core.Keywords != "invoice, 2024" || !got.Core.Created.Equal(created) {
+		t.Errorf("Unexpected core properties %+v", got.Core)
+	}
+	if got.App.Company != "ACME & Co" {
+		t.Errorf("Unexpected app properties %+v", got.App)
+	}
+	if got.Custom["Case Number"] != 1234 || got.Custom["Approved"] != true || got.Custom["Client"] != "Berlin" {
+		t.Errorf("Unexpected custom properties %v", got.Custom)
+	}
+	if due, ok := got.Custom["Due"].(time.Time); !ok || !due.Equal(created) {
+		t.Errorf("Expected custom date property, got %v", got.Custom["Due"])
+	}
+
+	parts, err := utils.ReadDocx(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read docx: %v", err)
+	}
+	content := map[string]string{}
+	for _, part := range parts {
+		content[part.Name] = string(part.Content)
+	}
+	if !strings.Contains(content["docProps/app.xml"], "<Pages>3</Pages>") {
+		t.Errorf("Expected unknown app properties to be kept, got %q", content["docProps/app.xml"])
+	}
+	for _, target := range []string{"docProps/core.xml", "docProps/app.xml", "docProps/custom.xml"} {
+		if !strings.Contains(content["_rels/.rels"], `Target="`+target+`"`) {
+			t.Errorf("Expected relationship to %s, got %q", target, content["_rels/.rels"])
+		}
+		if !strings.Contains(content["[Content_Types].xml"], `PartName="/`+target+`"`) {
+			t.Errorf("Expected content type for %s, got %q", target, content["[Content_Types].xml"])
+		}
+	}
+	document := content["word/document.xml"]
+	for _, expected := range []string{">ACME &amp; Co</w:t>", ">Invoice {{NUMBER}}</w:t>", ">1234</w:t>"} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("Expected fields to show %q, got %q", expected, document)
+		}
+	}
+}
+
+func TestWrite_RemovesEmptyValues(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "testPropertiesRemove")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	filePath := filepath.Join(tempDir, "props.docx")
+	createTestDocx(t, filePath, map[string]string{
+		"[Content_Types].xml": `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"></Types>`,
+		"docProps/core.xml":   emptyCore[:len(emptyCore)-len("</cp:coreProperties>")] + `<dc:title>Old</dc:title><dc:subject>Kept</dc:subject></cp:coreProperties>`,
+	})
+
+	props, err := Read(filePath)
+	if err != nil {
+		t.Fatalf("Read returned an error: %v", err)
+	}
+	props.Core.Title = ""
+	if err := Write(filePath, props); err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+
+	got, err := Read(filePath)
+	if err != nil {
+		t.Fatalf("Read returned an error: %v", err)
+	}
+	if got.Core.Title != "" || got.Core.Subject != "Kept" {
+		t.Errorf("Unexpected core properties %+v", got.Core)
+	}
+}
+
+func TestPropertiesValue(t *testing.T) {
+	props := Properties{Core: Core{Creator: "Ali"}, Custom: map[string]any{"Region": "EU"}}
+	tests := []struct {
+		name     string
+		expected string
+		found    bool
+	}{
+		{"Author", "Ali", true},
+		{"region", "EU", true},
+		{"Unknown", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := props.Value(tt.name)
+			if got != tt.expected || found != tt.found {
+				t.Errorf("Value(%q) = %q, %v; want %q, %v", tt.name, got, found, tt.expected, tt.found)
+			}
+		})
+	}
+}
+
+// createTestDocx creates a DOCX file holding the given parts
+func createTestDocx(t *testing.T, filePath string, parts map[string]string) {
+	file, err := os.Create(filePath)
+	if err != nil {
+		t.Fatalf("Failed to create test docx: %v", err)
+	}
+	defer file.Close()
+
+	zipWriter := zip.NewWriter(file)
+	defer zipWriter.Close()
+
+	for name, content := range parts {
+		writer, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		if _, err := writer.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
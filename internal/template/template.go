@@ -1,50 +1,81 @@
 package template
 
 import (
-	"archive/zip"
 	"embed"
 	"fmt"
 	"io/fs"
 	"strings"
+
+	"github.com/aliamerj/docxer/internal/opc"
 )
 
 //go:embed templates/*
 var templateFS embed.FS
 
-func CreateDocxTemplate(zipFile *zip.Writer) error {
-	return fs.WalkDir(templateFS, "templates", func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return fmt.Errorf("error walking through templates: %w", err)
-		}
-		if d.IsDir() {
-			return nil // Skip directories, as we only want to process files
-		}
+const (
+	MainContentType   = "application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"
+	StylesContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"
 
-		// Prepare the ZIP file path based on whether it's a .rels file or not
-		filePath := strings.TrimPrefix(path, "templates/")
-		var zipPath string
-		if strings.HasSuffix(filePath, ".rels") {
-			zipPath = "_rels/" + filePath // Special handling for .rels files
-		} else {
-			zipPath = filePath
-		}
+	OfficeDocumentRelType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument"
+	StylesRelType         = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles"
+
+	DocumentPath = "word/document.xml"
+)
+
+// packageParts are the content and relationship types of the document
+// properties every generated document starts with.
+var packageParts = []struct{ name, contentType, relType string }{
+	{"docProps/core.xml", "application/vnd.openxmlformats-package.core-properties+xml", "http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties"},
+	{"docProps/app.xml", "application/vnd.openxmlformats-officedocument.extended-properties+xml", "http://schemas.openxmlformats.org/officeDocument/2006/relationships/extended-properties"},
+}
 
-		// Attempt to create a new file within the ZIP archive
-		newFile, err := zipFile.Create(zipPath)
+// wordParts are the content and relationship types of the parts of the word
+// folder that templates of generated documents hold.
+var wordParts = map[string]struct{ contentType, relType string }{
+	"document.xml": {MainContentType, OfficeDocumentRelType},
+	"styles.xml":   {StylesContentType, StylesRelType},
+}
+
+// NewPackage returns a package holding the parts shared by every generated
+// document: the document properties and their relationships.
+func NewPackage() (*opc.Package, error) {
+	pkg := opc.New()
+	for _, part := range packageParts {
+		content, err := fs.ReadFile(templateFS, "templates/"+part.name)
 		if err != nil {
-			return fmt.Errorf("error creating file '%s' in ZIP archive: %w", zipPath, err)
+			return nil, fmt.Errorf("error reading contents of '%s': %w", part.name, err)
 		}
+		pkg.SetPart(part.name, part.contentType, content)
+		pkg.Relate("", part.relType, part.name)
+	}
+	return pkg, nil
+}
 
-		// Read and write the file content to the ZIP archive
-		fileContent, err := fs.ReadFile(templateFS, path)
+// AddWordParts adds the files of the template directory of files to the word
+// folder of pkg. The main document is related to the package and the other
+// parts to the main document.
+func AddWordParts(pkg *opc.Package, files fs.FS) error {
+	return fs.WalkDir(files, "template", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("error walking through template: %w", err)
+		}
+		if d.IsDir() {
+			return nil
+		}
+		file := strings.TrimPrefix(path, "template/")
+		content, err := fs.ReadFile(files, path)
 		if err != nil {
 			return fmt.Errorf("error reading contents of '%s': %w", path, err)
 		}
-
-		if _, err := newFile.Write(fileContent); err != nil {
-			return fmt.Errorf("error writing contents to '%s' in ZIP archive: %w", zipPath, err)
+		name := "word/" + file
+		part := wordParts[file]
+		pkg.SetPart(name, part.contentType, content)
+		switch {
+		case name == DocumentPath:
+			pkg.Relate("", part.relType, name)
+		case part.relType != "":
+			pkg.Relate(DocumentPath, part.relType, name)
 		}
-
 		return nil
 	})
 }
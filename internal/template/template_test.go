@@ -1,51 +1,56 @@
 package template
 
 import (
-	"archive/zip"
-	"bytes"
 	"strings"
 	"testing"
-)
+	"testing/fstest"
 
-func TestCreateDocxTemplate(t *testing.T) {
-	buffer := new(bytes.Buffer)
-	zipWriter := zip.NewWriter(buffer)
+	"github.com/aliamerj/docxer/internal/utils"
+)
 
-	// Assuming your CreateDocxTemplate function and context setup is corrected and available
-	err := CreateDocxTemplate(zipWriter)
+func TestNewPackage(t *testing.T) {
+	pkg, err := NewPackage()
 	if err != nil {
-		t.Fatalf("CreateDocxTemplate failed: %v", err)
+		t.Fatalf("NewPackage returned an error: %v", err)
 	}
-
-	if err := zipWriter.Close(); err != nil {
-		t.Fatalf("Failed to close zip writer: %v", err)
+	for _, name := range []string{utils.ContentTypesPath, "_rels/.rels", "docProps/core.xml", "docProps/app.xml"} {
+		if content, ok := pkg.Part(name); !ok || len(content) == 0 {
+			t.Errorf("Expected part %s", name)
+		}
 	}
+	if contentType := pkg.ContentType("docProps/core.xml"); contentType != packageParts[0].contentType {
+		t.Errorf("Expected the core properties content type, got %q", contentType)
+	}
+	if related := pkg.Related("", packageParts[1].relType); related != "docProps/app.xml" {
+		t.Errorf("Expected the package to relate to the app properties, got %q", related)
+	}
+}
 
-	zipReader, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+func TestAddWordParts(t *testing.T) {
+	files := fstest.MapFS{
+		"template/document.xml": &fstest.MapFile{Data: []byte("<w:document/>")},
+		"template/styles.xml":   &fstest.MapFile{Data: []byte("<w:styles/>")},
+	}
+	pkg, err := NewPackage()
 	if err != nil {
-		t.Fatalf("Failed to read zip content: %v", err)
+		t.Fatalf("NewPackage returned an error: %v", err)
 	}
-
-	foundRels := false
-	foundNonRels := false
-
-	for _, file := range zipReader.File {
-		if strings.Contains(file.Name, ".rels") {
-			if !strings.HasPrefix(file.Name, "_rels/") {
-				t.Errorf("RELs file %s is not in _rels directory", file.Name)
-			} else {
-				foundRels = true
-			}
-		} else {
-			foundNonRels = true
-		}
+	if err := AddWordParts(pkg, files); err != nil {
+		t.Fatalf("AddWordParts returned an error: %v", err)
 	}
 
-	if !foundRels {
-		t.Errorf("No .rels files found in _rels directory")
+	if related := pkg.Related("", OfficeDocumentRelType); related != DocumentPath {
+		t.Errorf("Expected the main document to be related to the package, got %q", related)
 	}
-
-	if !foundNonRels {
-		t.Errorf("No non-.rels files found outside _rels directory")
+	if related := pkg.Related(DocumentPath, StylesRelType); related != "word/styles.xml" {
+		t.Errorf("Expected the styles to be related to the main document, got %q", related)
+	}
+	if contentType := pkg.ContentType("word/styles.xml"); contentType != StylesContentType {
+		t.Errorf("Expected the styles content type, got %q", contentType)
+	}
+	for _, part := range pkg.Parts() {
+		if strings.HasSuffix(part.Name, ".rels") && !strings.Contains(part.Name, "_rels/") {
+			t.Errorf("Relationships part %s is not in a _rels folder", part.Name)
+		}
 	}
 }
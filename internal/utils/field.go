@@ -0,0 +1,214 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	fldCharPattern   = regexp.MustCompile(`<w:fldChar\s[^>]*?w:fldCharType="(begin|separate|end)"`)
+	instrTextPattern = regexp.MustCompile(`<w:instrText(?:\s[^>]*)?>([^<]*)</w:instrText>`)
+)
+
+// Field is a Word field in a part: either a simple field (w:fldSimple) or a
+// complex field whose begin, separate and end characters sit in their own runs.
+// content[Start:End] covers the whole field and content[ResultStart:ResultEnd]
+// the runs holding its last calculated result. ResultStart is -1 for a complex
+// field without a separate character.
+type Field struct {
+	Instr       string
+	Start       int
+	End         int
+	ResultStart int
+	ResultEnd   int
+	Simple      bool
+}
+
+// Name returns the field type, such as "DOCPROPERTY" or "MERGEFIELD".
+func (f Field) Name() string {
+	args := FieldArgs(f.Instr)
+	if len(args) == 0 {
+		return ""
+	}
+	return strings.ToUpper(args[0])
+}
+
+// FieldArgs splits a field instruction into its words. Quoted arguments are
+// returned without their quotes.
+func FieldArgs(instr string) []string {
+	var args []string
+	var current strings.Builder
+	inQuotes, hasArg := false, false
+	for _, c := range instr {
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+			hasArg = true
+		case (c == ' ' || c == '\t') && !inQuotes:
+			if hasArg {
+				args = append(args, current.String())
+				current.Reset()
+				hasArg = false
+			}
+		default:
+			current.WriteRune(c)
+			hasArg = true
+		}
+	}
+	if hasArg {
+		args = append(args, current.String())
+	}
+	return args
+}
+
+// FindFields returns the outermost fields of a part in document order.
+func FindFields(content string) []Field {
+	var fields []Field
+	for _, element := range FindElements(content, "w:fldSimple") {
+		instr, _ := Attr(element.StartTag(content), "w:instr")
+		fields = append(fields, Field{
+			Instr:       instr,
+			Start:       element.Start,
+			End:         element.End,
+			ResultStart: element.InnerStart,
+			ResultEnd:   element.InnerEnd,
+			Simple:      true,
+		})
+	}
+
+	depth := 0
+	var current Field
+	var instr strings.Builder
+	instrFrom := 0
+	for _, match := range fldCharPattern.FindAllStringSubmatchIndex(content, -1) {
+		switch content[match[2]:match[3]] {
+		case "begin":
+			depth++
+			if depth == 1 {
+				current = Field{Start: runStart(content, match[0]), ResultStart: -1}
+				instr.Reset()
+				instrFrom = match[1]
+			}
+		case "separate":
+			if depth == 1 {
+				instr.WriteString(instructions(content[instrFrom:match[0]]))
+				current.ResultStart = runEnd(content, match[1])
+				instrFrom = -1
+			}
+		case "end":
+			if depth == 0 {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				if instrFrom != -1 {
+					instr.WriteString(instructions(content[instrFrom:match[0]]))
+				}
+				current.End = runEnd(content, match[1])
+				current.ResultEnd = runStart(content, match[0])
+				if current.ResultStart == -1 {
+					current.ResultEnd = current.ResultStart
+				}
+				current.Instr = strings.TrimSpace(instr.String())
+				fields = append(fields, current)
+			}
+		}
+	}
+
+	// Simple fields and complex fields were collected separately.
+	for i := 1; i < len(fields); i++ {
+		for j := i; j > 0 && fields[j].Start < fields[j-1].Start; j-- {
+			fields[j], fields[j-1] = fields[j-1], fields[j]
+		}
+	}
+	return fields
+}
+
+// instructions joins the instruction text of a field, leaving out the
+// instructions of nested fields.
+func instructions(markup string) string {
+	var result strings.Builder
+	depth := 0
+	last := 0
+	for _, match := range fldCharPattern.FindAllStringSubmatchIndex(markup, -1) {
+		if depth == 0 {
+			for _, text := range instrTextPattern.FindAllStringSubmatch(markup[last:match[0]], -1) {
+				result.WriteString(UnescapeXML(text[1]))
+			}
+		}
+		switch markup[match[2]:match[3]] {
+		case "begin":
+			depth++
+		case "end":
+			depth--
+		}
+		last = match[1]
+	}
+	if depth == 0 {
+		for _, text := range instrTextPattern.FindAllStringSubmatch(markup[last:], -1) {
+			result.WriteString(UnescapeXML(text[1]))
+		}
+	}
+	return result.String()
+}
+
+// runStart returns the offset of the w:r start tag enclosing pos.
+func runStart(content string, pos int) int {
+	for i := pos; i > 0; {
+		i = strings.LastIndex(content[:i], "<w:r")
+		if i == -1 {
+			return pos
+		}
+		if next := content[i+len("<w:r")]; next == '>' || next == ' ' {
+			return i
+		}
+	}
+	return pos
+}
+
+// runEnd returns the offset just after the w:r end tag enclosing pos.
+func runEnd(content string, pos int) int {
+	i := strings.Index(content[pos:], "</w:r>")
+	if i == -1 {
+		return pos
+	}
+	return pos + i + len("</w:r>")
+}
+
+// SetFieldResult replaces the cached result of a field with a single run of
+// text, formatted like the first run of the old result.
+func SetFieldResult(content string, field Field, text string) string {
+	run := TextRun(resultProperties(content, field), text)
+	if field.ResultStart == -1 {
+		run = `<w:r><w:fldChar w:fldCharType="separate"/></w:r>` + run
+		endRun := runStart(content, strings.LastIndex(content[:field.End], "<w:fldChar"))
+		return content[:endRun] + run + content[endRun:]
+	}
+	return content[:field.ResultStart] + run + content[field.ResultEnd:]
+}
+
+// ReplaceField replaces a whole field, code and result, with a single run of
+// text formatted like the first run of its result. An empty text removes the
+// field.
+func ReplaceField(content string, field Field, text string) string {
+	run := ""
+	if text != "" {
+		run = TextRun(resultProperties(content, field), text)
+	}
+	return content[:field.Start] + run + content[field.End:]
+}
+
+// resultProperties returns the w:rPr of the first run of the result of a
+// field, or of the run starting a complex field without a result.
+func resultProperties(content string, field Field) string {
+	markup := content[field.Start:field.End]
+	if field.ResultStart != -1 {
+		markup = content[field.ResultStart:field.ResultEnd]
+	}
+	if run, ok := FindElement(markup, "w:r", 0); ok {
+		if rPr, ok := FindElement(run.Outer(markup), "w:rPr", 0); ok {
+			return rPr.Outer(run.Outer(markup))
+		}
+	}
+	return ""
+}
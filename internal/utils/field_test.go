@@ -0,0 +1,78 @@
+package utils
+
+import "testing"
+
+func TestFindFields(t *testing.T) {
+	content := `<w:p><w:fldSimple w:instr=" DOCPROPERTY  Title  \* MERGEFORMAT "><w:r><w:t>Old</w:t></w:r></w:fldSimple>` +
+		`<w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText xml:space="preserve"> MERGEFIELD </w:instrText></w:r><w:r><w:instrText>Name</w:instrText></w:r>` +
+		`<w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:t>«Name»</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r></w:p>`
+
+	fields := FindFields(content)
+	if len(fields) != 2 {
+		t.Fatalf("Expected 2 fields, got %d", len(fields))
+	}
+
+	simple := fields[0]
+	if !simple.Simple || simple.Name() != "DOCPROPERTY" || content[simple.ResultStart:simple.ResultEnd] != "<w:r><w:t>Old</w:t></w:r>" {
+		t.Errorf("Unexpected simple field %+v", simple)
+	}
+
+	complexField := fields[1]
+	if complexField.Simple || complexField.Instr != "MERGEFIELD Name" {
+		t.Errorf("Unexpected complex field instruction %q", complexField.Instr)
+	}
+	if got := content[complexField.ResultStart:complexField.ResultEnd]; got != "<w:r><w:t>«Name»</w:t></w:r>" {
+		t.Errorf("Unexpected complex field result %q", got)
+	}
+	if got := content[complexField.Start:complexField.End]; got[:len(`<w:r><w:fldChar`)] != `<w:r><w:fldChar` || got[len(got)-len("</w:r>"):] != "</w:r>" {
+		t.Errorf("Expected the field to cover its runs, got %q", got)
+	}
+}
+
+func TestFieldArgs(t *testing.T) {
+	args := FieldArgs(` DOCPROPERTY "Client Name" \* MERGEFORMAT`)
+	expected := []string{"DOCPROPERTY", "Client Name", `\*`, "MERGEFORMAT"}
+	if len(args) != len(expected) {
+		t.Fatalf("FieldArgs returned %q, want %q", args, expected)
+	}
+	for i := range expected {
+		if args[i] != expected[i] {
+			t.Errorf("FieldArgs()[%d] = %q, want %q", i, args[i], expected[i])
+		}
+	}
+}
+
+func TestSetFieldResult(t *testing.T) {
+	content := `<w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText>TITLE</w:instrText></w:r><w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>Old</w:t></w:r><w:r><w:t> title</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r>`
+	expected := `<w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText>TITLE</w:instrText></w:r><w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">New &amp; shiny</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r>`
+
+	got := SetFieldResult(content, FindFields(content)[0], "New & shiny")
+	if got != expected {
+		t.Errorf("SetFieldResult() = %q, want %q", got, expected)
+	}
+
+	withoutResult := `<w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText>TITLE</w:instrText></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r>`
+	expected = `<w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText>TITLE</w:instrText></w:r><w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:t xml:space="preserve">New</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r>`
+	if got := SetFieldResult(withoutResult, FindFields(withoutResult)[0], "New"); got != expected {
+		t.Errorf("SetFieldResult() without result = %q, want %q", got, expected)
+	}
+}
+
+func TestReplaceField(t *testing.T) {
+	content := `<w:p><w:r><w:t>Dear </w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText> MERGEFIELD Name </w:instrText></w:r><w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>«Name»</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r></w:p>`
+	expected := `<w:p><w:r><w:t>Dear </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">Ali</w:t></w:r></w:p>`
+	if got := ReplaceField(content, FindFields(content)[0], "Ali"); got != expected {
+		t.Errorf("ReplaceField() = %q, want %q", got, expected)
+	}
+
+	simple := `<w:p><w:fldSimple w:instr=" MERGEFIELD Name "><w:r><w:t>«Name»</w:t></w:r></w:fldSimple></w:p>`
+	if got := ReplaceField(simple, FindFields(simple)[0], ""); got != `<w:p></w:p>` {
+		t.Errorf("ReplaceField() with empty text = %q, want the field removed", got)
+	}
+
+	withoutResult := `<w:r><w:rPr><w:i/></w:rPr><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText>MERGEFIELD Name</w:instrText></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r>`
+	expected = `<w:r><w:rPr><w:i/></w:rPr><w:t xml:space="preserve">Ali</w:t></w:r>`
+	if got := ReplaceField(withoutResult, FindFields(withoutResult)[0], "Ali"); got != expected {
+		t.Errorf("ReplaceField() without result = %q, want %q", got, expected)
+	}
+}
@@ -0,0 +1,257 @@
+package utils
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/aliamerj/docxer/internal/docxerr"
+)
+
+const (
+	ContentTypesPath = "[Content_Types].xml"
+
+	emptyRelationships = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"></Relationships>`
+)
+
+// DocxPart is a single file stored inside a DOCX package.
+type DocxPart struct {
+	Name    string
+	Content []byte
+}
+
+// OpenDocx opens a DOCX file for reading. A missing file is reported as
+// docxerr.ErrTemplateNotFound and a file that is not a ZIP archive as a
+// *docxerr.InvalidPackageError.
+func OpenDocx(filePath string) (*zip.ReadCloser, error) {
+	zipReader, err := zip.OpenReader(filePath)
+	if err == nil {
+		return zipReader, nil
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %w", docxerr.ErrTemplateNotFound, err)
+	}
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return nil, err
+	}
+	return nil, &docxerr.InvalidPackageError{Path: filePath, Err: err}
+}
+
+// ReadZipPart returns the content of a file of an open DOCX package. Read
+// failures are reported as a *docxerr.InvalidPackageError.
+func ReadZipPart(filePath string, file *zip.File) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, &docxerr.InvalidPackageError{Path: filePath, Part: file.Name, Err: err}
+	}
+	defer rc.Close()
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, &docxerr.InvalidPackageError{Path: filePath, Part: file.Name, Err: err}
+	}
+	return content, nil
+}
+
+// ReadDocx returns every part of the DOCX file in archive order.
+func ReadDocx(filePath string) ([]DocxPart, error) {
+	zipReader, err := OpenDocx(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer zipReader.Close()
+	return readParts(filePath, &zipReader.Reader)
+}
+
+// ReadDocxFrom returns every part of a DOCX package of size bytes read from r,
+// in archive order.
+func ReadDocxFrom(r io.ReaderAt, size int64) ([]DocxPart, error) {
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, &docxerr.InvalidPackageError{Err: err}
+	}
+	return readParts("", zipReader)
+}
+
+func readParts(filePath string, zipReader *zip.Reader) ([]DocxPart, error) {
+	parts := make([]DocxPart, 0, len(zipReader.File))
+	for _, file := range zipReader.File {
+		content, err := ReadZipPart(filePath, file)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, DocxPart{Name: file.Name, Content: content})
+	}
+	return parts, nil
+}
+
+// WriteDocx replaces the DOCX file with the given parts. The package is written
+// to a temporary file first so that a failure leaves the original untouched.
+func WriteDocx(filePath string, parts []DocxPart) error {
+	tempFilePath := filePath + ".tmp"
+	outputFile, err := os.Create(tempFilePath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFilePath)
+	defer outputFile.Close()
+
+	zipWriter := zip.NewWriter(outputFile)
+	for _, part := range parts {
+		newFile, err := zipWriter.Create(part.Name)
+		if err != nil {
+			return fmt.Errorf("error creating file '%s' in ZIP archive: %w", part.Name, err)
+		}
+		if _, err := newFile.Write(part.Content); err != nil {
+			return fmt.Errorf("error writing contents to '%s' in ZIP archive: %w", part.Name, err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		return err
+	}
+	if err := outputFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tempFilePath, filePath)
+}
+
+// FindPart returns the index of the part called name, or -1.
+func FindPart(parts []DocxPart, name string) int {
+	for i, part := range parts {
+		if part.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetPart replaces the content of the part called name, appending the part
+// when the package does not contain it yet.
+func SetPart(parts []DocxPart, name string, content []byte) []DocxPart {
+	if i := FindPart(parts, name); i != -1 {
+		parts[i].Content = content
+		return parts
+	}
+	return append(parts, DocxPart{Name: name, Content: content})
+}
+
+// RelsPath returns the relationships part that belongs to a part, for example
+// "word/_rels/document.xml.rels" for "word/document.xml".
+func RelsPath(partName string) string {
+	dir, file := path.Split(partName)
+	return dir + "_rels/" + file + ".rels"
+}
+
+// ResolveTarget returns the name of the part that the internal relationship
+// target of the part called partName points to. Targets are relative to the
+// folder of the part unless they start with "/".
+func ResolveTarget(partName, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	return path.Join(path.Dir(partName), target)
+}
+
+// AddRelationship adds a relationship to a relationships part and returns the
+// updated part together with the id of the relationship. An existing
+// relationship with the same type and target is reused.
+func AddRelationship(rels, relType, target string, external bool) (string, string) {
+	if strings.TrimSpace(rels) == "" {
+		rels = emptyRelationships
+	}
+
+	maxID := 0
+	for _, element := range FindElements(rels, "Relationship") {
+		tag := element.StartTag(rels)
+		id, _ := Attr(tag, "Id")
+		existingType, _ := Attr(tag, "Type")
+		existingTarget, _ := Attr(tag, "Target")
+		if existingType == relType && existingTarget == target {
+			return rels, id
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(id, "rId")); err == nil && n > maxID {
+			maxID = n
+		}
+	}
+
+	id := "rId" + strconv.Itoa(maxID+1)
+	relationship := `<Relationship Id="` + id + `" Type="` + EscapeXML(relType) + `" Target="` + EscapeXML(target) + `"`
+	if external {
+		relationship += ` TargetMode="External"`
+	}
+	relationship += "/>"
+	end := strings.LastIndex(rels, "</Relationships>")
+	if end == -1 {
+		return rels, ""
+	}
+	return rels[:end] + relationship + rels[end:], id
+}
+
+// AddContentTypeOverride registers the content type of a part in
+// [Content_Types].xml, replacing an existing override for the same part.
+func AddContentTypeOverride(contentTypes, partName, contentType string) string {
+	if !strings.HasPrefix(partName, "/") {
+		partName = "/" + partName
+	}
+	for _, element := range FindElements(contentTypes, "Override") {
+		tag := element.StartTag(contentTypes)
+		if name, _ := Attr(tag, "PartName"); name == partName {
+			updated := SetAttr(tag, "ContentType", contentType)
+			return contentTypes[:element.Start] + updated + contentTypes[element.Start+len(tag):]
+		}
+	}
+	override := `<Override PartName="` + EscapeXML(partName) + `" ContentType="` + EscapeXML(contentType) + `"/>`
+	end := strings.LastIndex(contentTypes, "</Types>")
+	if end == -1 {
+		return contentTypes
+	}
+	return contentTypes[:end] + override + contentTypes[end:]
+}
+
+// AddContentTypeDefault registers the content type of the parts with an
+// extension in [Content_Types].xml, unless it already has a default for it.
+func AddContentTypeDefault(contentTypes, extension, contentType string) string {
+	for _, element := range FindElements(contentTypes, "Default") {
+		if value, _ := Attr(element.StartTag(contentTypes), "Extension"); strings.EqualFold(value, extension) {
+			return contentTypes
+		}
+	}
+	def := `<Default Extension="` + EscapeXML(extension) + `" ContentType="` + EscapeXML(contentType) + `"/>`
+	start, ok := FindElement(contentTypes, "Types", 0)
+	if !ok {
+		return contentTypes
+	}
+	at := start.Start + len(start.StartTag(contentTypes))
+	return contentTypes[:at] + def + contentTypes[at:]
+}
+
+// ContentType returns the content type [Content_Types].xml gives a part: its
+// override, or else the default for its extension.
+func ContentType(contentTypes, partName string) string {
+	if !strings.HasPrefix(partName, "/") {
+		partName = "/" + partName
+	}
+	for _, element := range FindElements(contentTypes, "Override") {
+		tag := element.StartTag(contentTypes)
+		if name, _ := Attr(tag, "PartName"); strings.EqualFold(name, partName) {
+			contentType, _ := Attr(tag, "ContentType")
+			return contentType
+		}
+	}
+	extension := strings.TrimPrefix(path.Ext(partName), ".")
+	for _, element := range FindElements(contentTypes, "Default") {
+		tag := element.StartTag(contentTypes)
+		if value, _ := Attr(tag, "Extension"); extension != "" && strings.EqualFold(value, extension) {
+			contentType, _ := Attr(tag, "ContentType")
+			return contentType
+		}
+	}
+	return ""
+}
@@ -0,0 +1,42 @@
+package utils
+
+// Transformer processes one part of a DOCX package while it is written. It
+// gets the part name, such as "word/header1.xml", the content type the package
+// gives the part and its content, and returns the new content. An error aborts
+// writing the package.
+type Transformer interface {
+	Transform(name, contentType string, content []byte) ([]byte, error)
+}
+
+// TransformerFunc adapts a function to the Transformer interface.
+type TransformerFunc func(name, contentType string, content []byte) ([]byte, error)
+
+func (f TransformerFunc) Transform(name, contentType string, content []byte) ([]byte, error) {
+	return f(name, contentType, content)
+}
+
+// Transform applies the DocxWriter to every part, whatever its name.
+func (w DocxWriter) Transform(name, contentType string, content []byte) ([]byte, error) {
+	updated, err := w(string(content))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(updated), nil
+}
+
+// Chain returns a Transformer that runs transformers in order, each one on the
+// output of the previous one. Nil transformers are skipped.
+func Chain(transformers ...Transformer) Transformer {
+	return TransformerFunc(func(name, contentType string, content []byte) ([]byte, error) {
+		for _, transformer := range transformers {
+			if transformer == nil {
+				continue
+			}
+			var err error
+			if content, err = transformer.Transform(name, contentType, content); err != nil {
+				return nil, err
+			}
+		}
+		return content, nil
+	})
+}
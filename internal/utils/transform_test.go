@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	var names []string
+	upper := TransformerFunc(func(name, contentType string, content []byte) ([]byte, error) {
+		names = append(names, name+" "+contentType)
+		return []byte(strings.ToUpper(string(content))), nil
+	})
+	suffix := DocxWriter(func(content string) (string, error) {
+		return content + "!", nil
+	})
+
+	content, err := Chain(upper, nil, suffix).Transform("word/document.xml", "application/xml", []byte("hello"))
+	if err != nil {
+		t.Fatalf("Transform returned an error: %v", err)
+	}
+	if string(content) != "HELLO!" {
+		t.Errorf("Expected 'HELLO!', got '%s'", content)
+	}
+	if len(names) != 1 || names[0] != "word/document.xml application/xml" {
+		t.Errorf("Expected the part name and content type to be passed on, got %v", names)
+	}
+}
+
+func TestChain_StopsOnError(t *testing.T) {
+	failure := errors.New("bad part")
+	calls := 0
+	failing := TransformerFunc(func(name, contentType string, content []byte) ([]byte, error) {
+		return nil, failure
+	})
+	counting := TransformerFunc(func(name, contentType string, content []byte) ([]byte, error) {
+		calls++
+		return content, nil
+	})
+
+	if _, err := Chain(failing, counting).Transform("word/document.xml", "", nil); !errors.Is(err, failure) {
+		t.Errorf("Expected the transformer error, got %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("Expected later transformers to be skipped, got %d calls", calls)
+	}
+}
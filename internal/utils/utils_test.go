@@ -3,9 +3,13 @@ package utils
 import (
 	"archive/zip"
 	"bytes"
+	"errors"
+	"io/fs"
 	"os"
+	"strings"
 	"testing"
-	"testing/fstest"
+
+	"github.com/aliamerj/docxer/internal/docxerr"
 )
 
 func TestValidateFilePath(t *testing.T) {
@@ -22,9 +26,8 @@ func TestValidateFilePath(t *testing.T) {
 	}
 
 	// Scenario 3: Test with a valid directory
-	// Create a temporary directory and defer its removal
-	tempDir := os.TempDir()
-	defer os.RemoveAll(tempDir)
+	// Create a temporary directory that is removed after the test
+	tempDir := t.TempDir()
 
 	err = ValidateFilePath(tempDir)
 	if err != nil {
@@ -47,56 +50,124 @@ func TestValidateFilePath(t *testing.T) {
 	}
 }
 
-// MockDocxWriter simply appends "Processed" to any input text.
-func MockDocxWriter(input string) string {
-	return input + "Processed"
-}
+func TestReadDocx(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "testReadDocx")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	filePath := tempDir + "/test.docx"
+	file, err := os.Create(filePath)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	zipWriter := zip.NewWriter(file)
+	for _, name := range []string{"[Content_Types].xml", "word/document.xml"} {
+		writer, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		writer.Write([]byte("Content of " + name))
+	}
+	zipWriter.Close()
+	file.Close()
 
-func TestCreateDocx(t *testing.T) {
-	// Create an in-memory file system (mock FS) with template files.
-	memFS := fstest.MapFS{
-		"template/document.xml": &fstest.MapFile{Data: []byte("Content of document.xml")},
-		// Add more files as needed.
+	parts, err := ReadDocx(filePath)
+	if err != nil {
+		t.Fatalf("ReadDocx failed: %v", err)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("Expected 2 parts, got %d", len(parts))
 	}
+	if parts[1].Name != "word/document.xml" || string(parts[1].Content) != "Content of word/document.xml" {
+		t.Errorf("Unexpected part %s: %q", parts[1].Name, parts[1].Content)
+	}
+}
 
-	// Create an in-memory ZIP writer to capture the output.
+func TestReadDocxFrom(t *testing.T) {
 	var buf bytes.Buffer
 	zipWriter := zip.NewWriter(&buf)
+	writer, _ := zipWriter.Create("word/document.xml")
+	writer.Write([]byte("<w:document/>"))
+	zipWriter.Close()
 
-	// Call the function under test.
-	err := CreateDocx(memFS, zipWriter, MockDocxWriter)
+	parts, err := ReadDocxFrom(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
 	if err != nil {
-		t.Fatalf("CreateDocx failed: %v", err)
+		t.Fatalf("ReadDocxFrom failed: %v", err)
+	}
+	if len(parts) != 1 || parts[0].Name != "word/document.xml" || string(parts[0].Content) != "<w:document/>" {
+		t.Errorf("Unexpected parts %+v", parts)
 	}
 
-	// Ensure everything is written to the buffer.
-	if err := zipWriter.Close(); err != nil {
-		t.Fatalf("Failed to close zip writer: %v", err)
+	_, err = ReadDocxFrom(strings.NewReader("not a zip"), 9)
+	if !errors.Is(err, docxerr.ErrInvalidPackage) || !errors.Is(err, zip.ErrFormat) {
+		t.Errorf("Expected an InvalidPackageError wrapping zip.ErrFormat, got %v", err)
 	}
+}
 
-	// Read back the ZIP content to verify.
-	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
-	if err != nil {
-		t.Fatalf("Failed to read zip content: %v", err)
-	}
-
-	// Verify the contents of the document.xml in the ZIP file.
-	for _, f := range zr.File {
-		if f.Name == "word/document.xml" {
-			rc, err := f.Open()
-			if err != nil {
-				t.Fatalf("Failed to open %s: %v", f.Name, err)
-			}
-			var fileContent bytes.Buffer
-			if _, err := fileContent.ReadFrom(rc); err != nil {
-				t.Fatalf("Failed to read %s: %v", f.Name, err)
-			}
-			rc.Close() // Close the file after reading
-
-			expectedContent := "Content of document.xmlProcessed"
-			if fileContent.String() != expectedContent {
-				t.Errorf("Expected content %q; got %q", expectedContent, fileContent.String())
-			}
+func TestResolveTarget(t *testing.T) {
+	tests := []struct{ part, target, expected string }{
+		{"", "word/document.xml", "word/document.xml"},
+		{"word/document.xml", "media/image1.png", "word/media/image1.png"},
+		{"word/document.xml", "../customXml/item1.xml", "customXml/item1.xml"},
+		{"word/document.xml", "/word/header1.xml", "word/header1.xml"},
+	}
+	for _, test := range tests {
+		if resolved := ResolveTarget(test.part, test.target); resolved != test.expected {
+			t.Errorf("ResolveTarget(%q, %q) = %q, want %q", test.part, test.target, resolved, test.expected)
 		}
 	}
 }
+
+func TestContentType(t *testing.T) {
+	contentTypes := `<Types><Default Extension="xml" ContentType="application/xml"/><Default Extension="PNG" ContentType="image/png"/>` +
+		`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/></Types>`
+
+	tests := map[string]string{
+		"word/document.xml":     "application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml",
+		"word/styles.xml":       "application/xml",
+		"word/media/image1.png": "image/png",
+		"word/fonts/font.odttf": "",
+	}
+	for partName, expected := range tests {
+		if contentType := ContentType(contentTypes, partName); contentType != expected {
+			t.Errorf("Expected '%s' for %s, got '%s'", expected, partName, contentType)
+		}
+	}
+}
+
+func TestAddContentTypeDefault(t *testing.T) {
+	contentTypes := `<?xml version="1.0"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="xml" ContentType="application/xml"/></Types>`
+
+	updated := AddContentTypeDefault(contentTypes, "png", "image/png")
+	expected := `<?xml version="1.0"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="png" ContentType="image/png"/><Default Extension="xml" ContentType="application/xml"/></Types>`
+	if updated != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, updated)
+	}
+	if again := AddContentTypeDefault(updated, "PNG", "image/png"); again != updated {
+		t.Errorf("Expected an existing default to be kept, got '%s'", again)
+	}
+}
+
+func TestOpenDocx_Errors(t *testing.T) {
+	tempDir := t.TempDir()
+
+	if _, err := OpenDocx(tempDir + "/missing.docx"); !errors.Is(err, docxerr.ErrTemplateNotFound) || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected ErrTemplateNotFound wrapping the cause, got %v", err)
+	}
+
+	corrupt := tempDir + "/corrupt.docx"
+	if err := os.WriteFile(corrupt, []byte("not a zip"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	_, err := ReadDocx(corrupt)
+	var packageErr *docxerr.InvalidPackageError
+	if !errors.As(err, &packageErr) || packageErr.Path != corrupt || !errors.Is(err, zip.ErrFormat) {
+		t.Errorf("Expected an InvalidPackageError wrapping zip.ErrFormat, got %v", err)
+	}
+
+	if err := ValidateFilePath(tempDir + "/nope"); !errors.Is(err, docxerr.ErrInvalidPath) {
+		t.Errorf("Expected ErrInvalidPath, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayouts are the layouts ToTime reads dates given as text with.
+var dateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"}
+
+// ToTime returns the time a value holds: a time.Time, a non-nil *time.Time or
+// text in RFC 3339 or ISO 8601 date form, such as "2024-03-05".
+func ToTime(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case *time.Time:
+		if v != nil {
+			return *v, true
+		}
+	case string:
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// ToNumber returns the number a value holds: any integer or float, or text
+// that parses as one.
+func ToNumber(value any) (float64, bool) {
+	if s, ok := value.(string); ok {
+		number, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		return number, err == nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// FormatValue writes a value as text without a locale: dates as "2006-01-02"
+// and anything else with fmt.
+func FormatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format("2006-01-02")
+	case *time.Time:
+		if v != nil {
+			return v.Format("2006-01-02")
+		}
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestToTime(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+	for _, value := range []any{date, &date, "2024-03-05", " 2024-03-05T00:00:00Z "} {
+		if got, ok := ToTime(value); !ok || !got.Equal(date) {
+			t.Errorf("ToTime(%#v) = %v, %v; want %v", value, got, ok, date)
+		}
+	}
+	var missing *time.Time
+	for _, value := range []any{missing, "05/03/2024", 42} {
+		if _, ok := ToTime(value); ok {
+			t.Errorf("Expected ToTime(%#v) to find no date", value)
+		}
+	}
+}
+
+func TestToNumber(t *testing.T) {
+	tests := []struct {
+		value    any
+		expected float64
+		ok       bool
+	}{
+		{3, 3, true},
+		{uint8(7), 7, true},
+		{float32(1.5), 1.5, true},
+		{" 12.25 ", 12.25, true},
+		{"twelve", 0, false},
+		{true, 0, false},
+	}
+	for _, test := range tests {
+		if got, ok := ToNumber(test.value); got != test.expected || ok != test.ok {
+			t.Errorf("ToNumber(%#v) = %v, %v; want %v, %v", test.value, got, ok, test.expected, test.ok)
+		}
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 14, 7, 0, 0, time.UTC)
+	tests := []struct {
+		value    any
+		expected string
+	}{
+		{"text", "text"},
+		{date, "2024-03-05"},
+		{&date, "2024-03-05"},
+		{12.5, "12.5"},
+		{nil, "<nil>"},
+	}
+	for _, test := range tests {
+		if got := FormatValue(test.value); got != test.expected {
+			t.Errorf("FormatValue(%#v) = %q, want %q", test.value, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package utils
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	DrawingNamespace = "http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"
+	// emuPerTwip converts twentieths of a point to the English Metric Units
+	// of DrawingML.
+	emuPerTwip = 635
+)
+
+var (
+	textPattern      = regexp.MustCompile(`<w:t(?:\s[^>]*)?>([^<]*)</w:t>|<w:t\s*/>|<w:tab\s*/>|<w:br(?:\s[^>]*)?/>|<w:cr\s*/>|</w:p>`)
+	storyPartPattern = regexp.MustCompile(`^word/(document|header\d*|footer\d*|footnotes|endnotes)\.xml$`)
+)
+
+// runPropertyOrder is the order WordprocessingML requires for the children of w:rPr.
+var runPropertyOrder = []string{
+	"w:rStyle", "w:rFonts", "w:b", "w:bCs", "w:i", "w:iCs", "w:caps", "w:smallCaps",
+	"w:strike", "w:dstrike", "w:outline", "w:shadow", "w:emboss", "w:imprint",
+	"w:noProof", "w:snapToGrid", "w:vanish", "w:webHidden", "w:color", "w:spacing",
+	"w:w", "w:kern", "w:position", "w:sz", "w:szCs", "w:highlight", "w:u", "w:effect",
+	"w:bdr", "w:shd", "w:fitText", "w:vertAlign", "w:rtl", "w:cs", "w:em", "w:lang",
+	"w:eastAsianLayout", "w:specVanish", "w:oMath",
+}
+
+// IsStoryPart reports whether a package part holds document text: the main
+// document, headers, footers, footnotes or endnotes.
+func IsStoryPart(name string) bool {
+	return storyPartPattern.MatchString(name)
+}
+
+// ExtractText returns the visible text of WordprocessingML markup, with tabs,
+// line breaks and paragraph ends turned into "\t" and "\n".
+func ExtractText(content string) string {
+	var result strings.Builder
+	for _, match := range textPattern.FindAllStringSubmatch(content, -1) {
+		switch {
+		case strings.HasPrefix(match[0], "<w:tab"):
+			result.WriteString("\t")
+		case strings.HasPrefix(match[0], "<w:t"):
+			result.WriteString(UnescapeXML(match[1]))
+		default:
+			result.WriteString("\n")
+		}
+	}
+	return strings.TrimRight(result.String(), "\n")
+}
+
+// TextRun builds a run holding text with the given run properties. Line breaks
+// and tabs in text become w:br and w:tab elements.
+func TextRun(runProps, text string) string {
+	var result strings.Builder
+	result.WriteString("<w:r>" + runProps)
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			result.WriteString("<w:br/>")
+		}
+		for j, part := range strings.Split(line, "\t") {
+			if j > 0 {
+				result.WriteString("<w:tab/>")
+			}
+			if part != "" {
+				result.WriteString(`<w:t xml:space="preserve">` + EscapeXML(part) + "</w:t>")
+			}
+		}
+	}
+	result.WriteString("</w:r>")
+	return result.String()
+}
+
+// RunProperties returns the w:rPr element of a run, or "" when it has none.
+func RunProperties(run string) string {
+	if rPr, ok := FindElement(run, "w:rPr", 0); ok {
+		return rPr.Outer(run)
+	}
+	return ""
+}
+
+// propertyOrder is the order WordprocessingML requires for the children of
+// the property elements.
+var propertyOrder = map[string][]string{
+	"w:rPr": runPropertyOrder,
+	"w:pPr": {
+		"w:pStyle", "w:keepNext", "w:keepLines", "w:pageBreakBefore", "w:framePr",
+		"w:widowControl", "w:numPr", "w:suppressLineNumbers", "w:pBdr", "w:shd", "w:tabs",
+		"w:suppressAutoHyphens", "w:kinsoku", "w:wordWrap", "w:overflowPunct",
+		"w:topLinePunct", "w:autoSpaceDE", "w:autoSpaceDN", "w:bidi", "w:adjustRightInd",
+		"w:snapToGrid", "w:spacing", "w:ind", "w:contextualSpacing", "w:mirrorIndents",
+		"w:suppressOverlap", "w:jc", "w:textDirection", "w:textAlignment",
+		"w:textboxTightWrap", "w:outlineLvl", "w:divId", "w:cnfStyle", "w:rPr",
+		"w:sectPr", "w:pPrChange",
+	},
+	"w:tblPr": {
+		"w:tblStyle", "w:tblpPr", "w:tblOverlap", "w:bidiVisual", "w:tblStyleRowBandSize",
+		"w:tblStyleColBandSize", "w:tblW", "w:jc", "w:tblCellSpacing", "w:tblInd",
+		"w:tblBorders", "w:shd", "w:tblLayout", "w:tblCellMar", "w:tblLook",
+		"w:tblCaption", "w:tblDescription", "w:tblPrChange",
+	},
+	"w:trPr": {
+		"w:cnfStyle", "w:divId", "w:gridBefore", "w:gridAfter", "w:wBefore", "w:wAfter",
+		"w:cantSplit", "w:trHeight", "w:tblHeader", "w:tblCellSpacing", "w:jc", "w:hidden",
+		"w:ins", "w:del", "w:trPrChange",
+	},
+	"w:tcPr": {
+		"w:cnfStyle", "w:tcW", "w:gridSpan", "w:hMerge", "w:vMerge", "w:tcBorders", "w:shd",
+		"w:noWrap", "w:tcMar", "w:textDirection", "w:tcFitText", "w:vAlign", "w:hideMark",
+		"w:headers", "w:cellIns", "w:cellDel", "w:cellMerge", "w:tcPrChange",
+	},
+	"w:sectPr": {
+		"w:headerReference", "w:footerReference", "w:footnotePr", "w:endnotePr", "w:type",
+		"w:pgSz", "w:pgMar", "w:paperSrc", "w:pgBorders", "w:lnNumType", "w:pgNumType",
+		"w:cols", "w:formProt", "w:vAlign", "w:noEndnote", "w:titlePg", "w:textDirection",
+		"w:bidi", "w:rtlGutter", "w:docGrid", "w:printerSettings", "w:sectPrChange",
+	},
+}
+
+// MergeRunProperties combines two w:rPr elements. Properties in extra replace
+// those of the same name in base and the result keeps the schema order.
+func MergeRunProperties(base, extra string) string {
+	var inners []string
+	for _, rPr := range []string{base, extra} {
+		if element, ok := FindElement(rPr, "w:rPr", 0); ok {
+			inners = append(inners, element.Inner(rPr))
+		}
+	}
+	return OrderProperties("w:rPr", inners...)
+}
+
+// OrderProperties builds a property element such as w:pPr from the children
+// in each of markups, sorted in the order the schema requires. Elements of a
+// name in a later markup replace all those of the same name before it, so
+// repeated elements such as the w:headerReference of a w:sectPr stay
+// together. It returns "" when there are no children.
+func OrderProperties(name string, markups ...string) string {
+	properties := map[string][]string{}
+	var names []string
+	for _, markup := range markups {
+		replaced := map[string]bool{}
+		for _, child := range Children(markup) {
+			childName := child.Name(markup)
+			if _, seen := properties[childName]; !seen {
+				names = append(names, childName)
+			}
+			if !replaced[childName] {
+				properties[childName] = nil
+				replaced[childName] = true
+			}
+			properties[childName] = append(properties[childName], child.Outer(markup))
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+
+	order := propertyOrder[name]
+	position := func(name string) int {
+		for i, ordered := range order {
+			if ordered == name {
+				return i
+			}
+		}
+		return len(order)
+	}
+	sort.SliceStable(names, func(i, j int) bool { return position(names[i]) < position(names[j]) })
+
+	var result strings.Builder
+	result.WriteString("<" + name + ">")
+	for _, childName := range names {
+		for _, property := range properties[childName] {
+			result.WriteString(property)
+		}
+	}
+	result.WriteString("</" + name + ">")
+	return result.String()
+}
+
+// HasStyle reports whether a styles part defines the style with the given id.
+func HasStyle(styles, styleID string) bool {
+	return strings.Contains(styles, `w:styleId="`+EscapeXML(styleID)+`"`)
+}
+
+// AddStyle appends a w:style definition to a styles part unless a style with
+// the same id is already defined.
+func AddStyle(styles, styleID, definition string) string {
+	if HasStyle(styles, styleID) {
+		return styles
+	}
+	end := strings.LastIndex(styles, "</w:styles>")
+	if end == -1 {
+		return styles
+	}
+	return styles[:end] + definition + styles[end:]
+}
+
+// NextDrawingID returns an id for the wp:docPr of a new drawing that no
+// drawing of the part uses yet.
+func NextDrawingID(content string) int {
+	next := 1
+	for _, element := range FindElements(content, "wp:docPr") {
+		value, _ := Attr(element.StartTag(content), "id")
+		if id, err := strconv.Atoi(value); err == nil && id >= next {
+			next = id + 1
+		}
+	}
+	return next
+}
+
+// InlinePicture returns a run showing the image with relationship id rID
+// inline with the text, width by height twentieths of a point, with
+// description as its alternative text. The part
+// must declare the wp prefix as DrawingNamespace and r as the relationships
+// namespace.
+func InlinePicture(runProps, rID string, id int, name, description string, width, height int) string {
+	return PictureRun(runProps, Picture{RelID: rID, ID: id, Name: name, Description: description, Width: width, Height: height})
+}
+
+// Picture is a picture drawn by PictureRun. RelID relates to the image and
+// SVGRelID, when set, to an SVG version of it, which the image stands in for
+// where SVG is not supported. Width and Height are in twentieths of a point.
+// A picture with an Anchor floats instead of being inline with the text.
+type Picture struct {
+	RelID, SVGRelID          string
+	ID                       int
+	Name, Description, Title string
+	Width, Height            int
+	Anchor                   *PictureAnchor
+}
+
+// PictureAnchor places a floating picture. Wrap is "square", "tight",
+// "topAndBottom", "behind" or "inFront" of the text. Align is "left",
+// "center" or "right" of the column; without it the picture is X
+// twentieths of a point from the left of the column. The picture is Y
+// twentieths of a point below the top of its paragraph and Distance away
+// from the text.
+type PictureAnchor struct {
+	Wrap     string
+	Align    string
+	X, Y     int
+	Distance int
+}
+
+// PictureRun returns a run showing a picture. The part must declare the wp
+// prefix as DrawingNamespace and r as the relationships namespace.
+func PictureRun(runProps string, picture Picture) string {
+	cx := strconv.Itoa(picture.Width * emuPerTwip)
+	cy := strconv.Itoa(picture.Height * emuPerTwip)
+	name := EscapeXML(picture.Name)
+	docPr := `<wp:docPr id="` + strconv.Itoa(picture.ID) + `" name="` + name + `" descr="` + EscapeXML(picture.Description) + `"`
+	if picture.Title != "" {
+		docPr += ` title="` + EscapeXML(picture.Title) + `"`
+	}
+	docPr += `/>`
+	blip := `<a:blip r:embed="` + picture.RelID + `"/>`
+	if picture.SVGRelID != "" {
+		blip = `<a:blip r:embed="` + picture.RelID + `"><a:extLst><a:ext uri="{96DAC541-7B7A-43D3-8B79-37D633B846F1}">` +
+			`<asvg:svgBlip xmlns:asvg="http://schemas.microsoft.com/office/drawing/2016/SVG/main" r:embed="` + picture.SVGRelID + `"/>` +
+			`</a:ext></a:extLst></a:blip>`
+	}
+	graphic := `<wp:cNvGraphicFramePr><a:graphicFrameLocks xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" noChangeAspect="1"/></wp:cNvGraphicFramePr>` +
+		`<a:graphic xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main">` +
+		`<a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/picture">` +
+		`<pic:pic xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture">` +
+		`<pic:nvPicPr><pic:cNvPr id="0" name="` + name + `"/><pic:cNvPicPr/></pic:nvPicPr>` +
+		`<pic:blipFill>` + blip + `<a:stretch><a:fillRect/></a:stretch></pic:blipFill>` +
+		`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="` + cx + `" cy="` + cy + `"/></a:xfrm>` +
+		`<a:prstGeom prst="rect"><a:avLst/></a:prstGeom></pic:spPr>` +
+		`</pic:pic></a:graphicData></a:graphic>`
+	extent := `<wp:extent cx="` + cx + `" cy="` + cy + `"/>`
+
+	anchor := picture.Anchor
+	if anchor == nil {
+		return `<w:r>` + runProps + `<w:drawing>` +
+			`<wp:inline distT="0" distB="0" distL="0" distR="0">` + extent + docPr + graphic +
+			`</wp:inline></w:drawing></w:r>`
+	}
+	distance := strconv.Itoa(anchor.Distance * emuPerTwip)
+	behind := "0"
+	var wrap string
+	switch anchor.Wrap {
+	case "tight":
+		wrap = `<wp:wrapTight wrapText="bothSides"><wp:wrapPolygon edited="0"><wp:start x="0" y="0"/>` +
+			`<wp:lineTo x="0" y="21600"/><wp:lineTo x="21600" y="21600"/><wp:lineTo x="21600" y="0"/><wp:lineTo x="0" y="0"/>` +
+			`</wp:wrapPolygon></wp:wrapTight>`
+	case "topAndBottom":
+		wrap = `<wp:wrapTopAndBottom/>`
+	case "behind":
+		wrap, behind = `<wp:wrapNone/>`, "1"
+	case "inFront":
+		wrap = `<wp:wrapNone/>`
+	default:
+		wrap = `<wp:wrapSquare wrapText="bothSides"/>`
+	}
+	positionH := `<wp:positionH relativeFrom="column"><wp:posOffset>` + strconv.Itoa(anchor.X*emuPerTwip) + `</wp:posOffset></wp:positionH>`
+	if anchor.Align != "" {
+		positionH = `<wp:positionH relativeFrom="column"><wp:align>` + EscapeXML(anchor.Align) + `</wp:align></wp:positionH>`
+	}
+	return `<w:r>` + runProps + `<w:drawing>` +
+		`<wp:anchor distT="` + distance + `" distB="` + distance + `" distL="` + distance + `" distR="` + distance + `"` +
+		` simplePos="0" relativeHeight="` + strconv.Itoa(picture.ID) + `" behindDoc="` + behind + `" locked="0" layoutInCell="1" allowOverlap="1">` +
+		`<wp:simplePos x="0" y="0"/>` + positionH +
+		`<wp:positionV relativeFrom="paragraph"><wp:posOffset>` + strconv.Itoa(anchor.Y*emuPerTwip) + `</wp:posOffset></wp:positionV>` +
+		extent + wrap + docPr + graphic +
+		`</wp:anchor></w:drawing></w:r>`
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeRunProperties(t *testing.T) {
+	base := `<w:rPr><w:sz w:val="28"/><w:b/></w:rPr>`
+	extra := `<w:rPr><w:sz w:val="20"/><w:rStyle w:val="Hyperlink"/></w:rPr>`
+	expected := `<w:rPr><w:rStyle w:val="Hyperlink"/><w:b/><w:sz w:val="20"/></w:rPr>`
+
+	if merged := MergeRunProperties(base, extra); merged != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, merged)
+	}
+	if merged := MergeRunProperties("", ""); merged != "" {
+		t.Errorf("Expected no properties, got '%s'", merged)
+	}
+}
+
+func TestOrderProperties(t *testing.T) {
+	base := `<w:jc w:val="center"/><w:pStyle w:val="Title"/><w:sectPr/>`
+	extra := `<w:jc w:val="right"/><w:keepNext/>`
+	expected := `<w:pPr><w:pStyle w:val="Title"/><w:keepNext/><w:jc w:val="right"/><w:sectPr/></w:pPr>`
+	if ordered := OrderProperties("w:pPr", base, extra); ordered != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, ordered)
+	}
+
+	sectPr := `<w:pgSz w:w="1"/><w:headerReference r:id="rId1"/><w:headerReference r:id="rId2"/>`
+	expected = `<w:sectPr><w:headerReference r:id="rId1"/><w:headerReference r:id="rId2"/><w:pgSz w:w="1"/></w:sectPr>`
+	if ordered := OrderProperties("w:sectPr", sectPr); ordered != expected {
+		t.Errorf("Expected repeated elements to be kept, got '%s'", ordered)
+	}
+	if ordered := OrderProperties("w:tcPr", "", ""); ordered != "" {
+		t.Errorf("Expected no properties, got '%s'", ordered)
+	}
+}
+
+func TestAddStyle(t *testing.T) {
+	styles := `<w:styles><w:style w:styleId="Normal"/></w:styles>`
+	definition := `<w:style w:styleId="Hyperlink"/>`
+
+	updated := AddStyle(styles, "Hyperlink", definition)
+	if updated != `<w:styles><w:style w:styleId="Normal"/><w:style w:styleId="Hyperlink"/></w:styles>` {
+		t.Errorf("Expected the style to be appended, got '%s'", updated)
+	}
+	if again := AddStyle(updated, "Hyperlink", definition); again != updated {
+		t.Errorf("Expected an existing style to be kept, got '%s'", again)
+	}
+}
+
+func TestExtractText(t *testing.T) {
+	content := `<w:p><w:r><w:t>A &amp; B</w:t><w:tab/><w:t>C</w:t></w:r></w:p><w:p><w:r><w:t>D</w:t><w:br/><w:t>E</w:t></w:r></w:p>`
+	expected := "A & B\tC\nD\nE"
+
+	if text := ExtractText(content); text != expected {
+		t.Errorf("Expected '%q', got '%q'", expected, text)
+	}
+}
+
+func TestInlinePicture(t *testing.T) {
+	picture := InlinePicture(`<w:rPr><w:b/></w:rPr>`, "rId4", 3, "Logo & co", "A \"logo\"", 1440, 720)
+	for _, expected := range []string{
+		`<w:r><w:rPr><w:b/></w:rPr><w:drawing><wp:inline `,
+		`<wp:extent cx="914400" cy="457200"/><wp:docPr id="3" name="Logo &amp; co" descr="A &quot;logo&quot;"/>`,
+		`<a:blip r:embed="rId4"/>`,
+	} {
+		if !strings.Contains(picture, expected) {
+			t.Errorf("Expected '%s' in '%s'", expected, picture)
+		}
+	}
+
+	if id := NextDrawingID(picture + `<wp:docPr id="9" name="x"/>`); id != 10 {
+		t.Errorf("Expected the next drawing id to be 10, got %d", id)
+	}
+	if id := NextDrawingID(`<w:p/>`); id != 1 {
+		t.Errorf("Expected the first drawing id to be 1, got %d", id)
+	}
+}
+
+func TestPictureRun_Floating(t *testing.T) {
+	picture := PictureRun("", Picture{
+		RelID: "rId1", SVGRelID: "rId2", ID: 4, Name: "Chart", Title: "Sales", Width: 1440, Height: 1440,
+		Anchor: &PictureAnchor{Wrap: "behind", Align: "center", Y: 20, Distance: 10},
+	})
+	for _, expected := range []string{
+		`<wp:anchor distT="6350" distB="6350" distL="6350" distR="6350" simplePos="0" relativeHeight="4" behindDoc="1" `,
+		`<wp:positionH relativeFrom="column"><wp:align>center</wp:align></wp:positionH>` +
+			`<wp:positionV relativeFrom="paragraph"><wp:posOffset>12700</wp:posOffset></wp:positionV>` +
+			`<wp:extent cx="914400" cy="914400"/><wp:wrapNone/><wp:docPr id="4" name="Chart" descr="" title="Sales"/>`,
+		`<a:blip r:embed="rId1"><a:extLst><a:ext uri="{96DAC541-7B7A-43D3-8B79-37D633B846F1}">`,
+		`<asvg:svgBlip xmlns:asvg="http://schemas.microsoft.com/office/drawing/2016/SVG/main" r:embed="rId2"/>`,
+		`</wp:anchor></w:drawing></w:r>`,
+	} {
+		if !strings.Contains(picture, expected) {
+			t.Errorf("Expected '%s' in '%s'", expected, picture)
+		}
+	}
+
+	square := PictureRun("", Picture{RelID: "rId1", Anchor: &PictureAnchor{X: 100}})
+	if !strings.Contains(square, `<wp:posOffset>63500</wp:posOffset></wp:positionH>`) || !strings.Contains(square, `<wp:wrapSquare wrapText="bothSides"/>`) {
+		t.Errorf("Expected an offset picture wrapped in a square, got '%s'", square)
+	}
+}
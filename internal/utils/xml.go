@@ -0,0 +1,274 @@
+package utils
+
+import (
+	"html"
+	"strings"
+)
+
+// XMLElement is the position of a single element inside an XML part.
+// content[Start:End] is the whole element and content[InnerStart:InnerEnd]
+// its children. For a self-closing element InnerStart and InnerEnd equal End.
+type XMLElement struct {
+	Start      int
+	End        int
+	InnerStart int
+	InnerEnd   int
+}
+
+// Outer returns the full markup of the element.
+func (e XMLElement) Outer(content string) string {
+	return content[e.Start:e.End]
+}
+
+// Inner returns the markup between the start and end tags of the element.
+func (e XMLElement) Inner(content string) string {
+	return content[e.InnerStart:e.InnerEnd]
+}
+
+// StartTag returns the start tag of the element, including its attributes.
+func (e XMLElement) StartTag(content string) string {
+	end := strings.IndexByte(content[e.Start:], '>')
+	return content[e.Start : e.Start+end+1]
+}
+
+// Name returns the qualified name of the element, such as "w:p".
+func (e XMLElement) Name(content string) string {
+	tag := content[e.Start+1:]
+	end := strings.IndexAny(tag, " \t\r\n/>")
+	if end == -1 {
+		return tag
+	}
+	return tag[:end]
+}
+
+// Children returns the elements at the top level of markup, skipping text,
+// comments and processing instructions.
+func Children(markup string) []XMLElement {
+	var children []XMLElement
+	for i := 0; i < len(markup); {
+		start := strings.IndexByte(markup[i:], '<')
+		if start == -1 {
+			break
+		}
+		start += i
+		if start+1 >= len(markup) || strings.ContainsRune("/?!", rune(markup[start+1])) {
+			i = start + 1
+			continue
+		}
+		name := XMLElement{Start: start}.Name(markup)
+		element, ok := FindElement(markup, name, start)
+		if !ok || element.Start != start {
+			break
+		}
+		children = append(children, element)
+		i = element.End
+	}
+	return children
+}
+
+// EnclosingElement returns the innermost element called name that contains
+// the offset pos.
+func EnclosingElement(content, name string, pos int) (XMLElement, bool) {
+	for end := pos; end > 0; {
+		start := strings.LastIndex(content[:end], "<"+name)
+		if start == -1 {
+			return XMLElement{}, false
+		}
+		end = start
+		if indexStartTag(content, name, start) != start {
+			continue
+		}
+		element, ok := FindElement(content, name, start)
+		if ok && element.Start == start && pos < element.End {
+			return element, true
+		}
+	}
+	return XMLElement{}, false
+}
+
+// EnsureNamespace declares a namespace prefix on the root element of a part
+// when it is not declared yet.
+func EnsureNamespace(content, prefix, uri string) string {
+	for i := 0; i < len(content); {
+		start := strings.IndexByte(content[i:], '<')
+		if start == -1 {
+			return content
+		}
+		start += i
+		if start+1 < len(content) && strings.ContainsRune("?!", rune(content[start+1])) {
+			i = start + 1
+			continue
+		}
+		end := strings.IndexByte(content[start:], '>')
+		if end == -1 {
+			return content
+		}
+		tag := content[start : start+end+1]
+		if _, ok := Attr(tag, "xmlns:"+prefix); ok {
+			return content
+		}
+		return content[:start] + SetAttr(tag, "xmlns:"+prefix, uri) + content[start+end+1:]
+	}
+	return content
+}
+
+// FindElements returns the outermost elements called name (for example
+// "w:sdt") in document order. Elements nested inside a match are not returned.
+func FindElements(content, name string) []XMLElement {
+	var elements []XMLElement
+	offset := 0
+	for {
+		element, ok := FindElement(content, name, offset)
+		if !ok {
+			return elements
+		}
+		elements = append(elements, element)
+		offset = element.End
+	}
+}
+
+// FindElement returns the first element called name that starts at or after from.
+func FindElement(content, name string, from int) (XMLElement, bool) {
+	start := indexStartTag(content, name, from)
+	if start == -1 {
+		return XMLElement{}, false
+	}
+	tagEnd := strings.IndexByte(content[start:], '>')
+	if tagEnd == -1 {
+		return XMLElement{}, false
+	}
+	tagEnd += start + 1
+	if content[tagEnd-2] == '/' {
+		return XMLElement{Start: start, End: tagEnd, InnerStart: tagEnd, InnerEnd: tagEnd}, true
+	}
+
+	closing := "</" + name + ">"
+	depth := 1
+	pos := tagEnd
+	for depth > 0 {
+		nextClose := strings.Index(content[pos:], closing)
+		if nextClose == -1 {
+			return XMLElement{}, false
+		}
+		nextClose += pos
+		nextOpen := indexStartTag(content, name, pos)
+		if nextOpen != -1 && nextOpen < nextClose {
+			openEnd := strings.IndexByte(content[nextOpen:], '>')
+			if openEnd == -1 {
+				return XMLElement{}, false
+			}
+			openEnd += nextOpen + 1
+			if content[openEnd-2] != '/' {
+				depth++
+			}
+			pos = openEnd
+			continue
+		}
+		depth--
+		pos = nextClose + len(closing)
+		if depth == 0 {
+			return XMLElement{Start: start, End: pos, InnerStart: tagEnd, InnerEnd: nextClose}, true
+		}
+	}
+	return XMLElement{}, false
+}
+
+// indexStartTag finds "<name" followed by whitespace, '>' or '/', so that
+// looking for "w:sdt" does not match "w:sdtPr".
+func indexStartTag(content, name string, from int) int {
+	open := "<" + name
+	for from < len(content) {
+		i := strings.Index(content[from:], open)
+		if i == -1 {
+			return -1
+		}
+		i += from
+		next := i + len(open)
+		if next < len(content) {
+			switch content[next] {
+			case ' ', '\t', '\r', '\n', '>', '/':
+				return i
+			}
+		}
+		from = next
+	}
+	return -1
+}
+
+// Attr returns the value of the attribute called name in a start tag.
+func Attr(tag, name string) (string, bool) {
+	for offset := 0; ; {
+		i := strings.Index(tag[offset:], name+"=")
+		if i == -1 {
+			return "", false
+		}
+		i += offset
+		offset = i + len(name) + 1
+		if i > 0 && !isSpace(tag[i-1]) {
+			continue
+		}
+		if offset >= len(tag) {
+			return "", false
+		}
+		quote := tag[offset]
+		if quote != '"' && quote != '\'' {
+			continue
+		}
+		end := strings.IndexByte(tag[offset+1:], quote)
+		if end == -1 {
+			return "", false
+		}
+		return UnescapeXML(tag[offset+1 : offset+1+end]), true
+	}
+}
+
+// SetAttr returns the start tag with the attribute called name set to value,
+// adding it when it is not present yet.
+func SetAttr(tag, name, value string) string {
+	escaped := EscapeXML(value)
+	for offset := 0; ; {
+		i := strings.Index(tag[offset:], name+"=")
+		if i == -1 {
+			break
+		}
+		i += offset
+		offset = i + len(name) + 1
+		if i > 0 && !isSpace(tag[i-1]) || offset >= len(tag) {
+			continue
+		}
+		quote := tag[offset]
+		end := strings.IndexByte(tag[offset+1:], quote)
+		if end == -1 {
+			break
+		}
+		return tag[:offset+1] + escaped + tag[offset+1+end:]
+	}
+	insertAt := len(tag) - 1
+	if strings.HasSuffix(tag, "/>") {
+		insertAt = len(tag) - 2
+	}
+	return strings.TrimRight(tag[:insertAt], " ") + " " + name + `="` + escaped + `"` + tag[insertAt:]
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// EscapeXML replaces the characters that are special in XML text and
+// attribute values with their entities.
+func EscapeXML(text string) string {
+	text = strings.ReplaceAll(text, "&", "&amp;")
+	text = strings.ReplaceAll(text, "<", "&lt;")
+	text = strings.ReplaceAll(text, ">", "&gt;")
+	text = strings.ReplaceAll(text, "\"", "&quot;")
+	text = strings.ReplaceAll(text, "'", "&apos;")
+	return text
+}
+
+// UnescapeXML resolves the entities and character references in XML text.
+func UnescapeXML(text string) string {
+	if !strings.Contains(text, "&") {
+		return text
+	}
+	return html.UnescapeString(text)
+}
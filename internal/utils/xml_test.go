@@ -0,0 +1,82 @@
+package utils
+
+import "testing"
+
+func TestFindElements(t *testing.T) {
+	content := `<w:body><w:sdt><w:sdtPr/><w:sdtContent><w:sdt><w:sdtPr/></w:sdt></w:sdtContent></w:sdt><w:p/><w:sdt/></w:body>`
+
+	elements := FindElements(content, "w:sdt")
+	if len(elements) != 2 {
+		t.Fatalf("Expected 2 outermost elements, got %d", len(elements))
+	}
+
+	expectedFirst := `<w:sdt><w:sdtPr/><w:sdtContent><w:sdt><w:sdtPr/></w:sdt></w:sdtContent></w:sdt>`
+	if got := elements[0].Outer(content); got != expectedFirst {
+		t.Errorf("Expected first element %q, got %q", expectedFirst, got)
+	}
+	expectedInner := `<w:sdtPr/><w:sdtContent><w:sdt><w:sdtPr/></w:sdt></w:sdtContent>`
+	if got := elements[0].Inner(content); got != expectedInner {
+		t.Errorf("Expected inner markup %q, got %q", expectedInner, got)
+	}
+	if got := elements[1].Outer(content); got != "<w:sdt/>" {
+		t.Errorf("Expected self-closing element, got %q", got)
+	}
+	if elements[1].Inner(content) != "" {
+		t.Errorf("Expected self-closing element to have no children")
+	}
+}
+
+func TestFindElement_DoesNotMatchPrefix(t *testing.T) {
+	content := `<w:sdtPr><w:tag w:val="x"/></w:sdtPr>`
+	if _, ok := FindElement(content, "w:sdt", 0); ok {
+		t.Errorf("Expected w:sdtPr not to match w:sdt")
+	}
+}
+
+func TestAttr(t *testing.T) {
+	tests := []struct {
+		tag      string
+		name     string
+		expected string
+		found    bool
+	}{
+		{`<w:tag w:val="customer"/>`, "w:val", "customer", true},
+		{`<w14:checked w14:val="1"/>`, "w:val", "", false},
+		{`<w:listItem w:displayText="A &amp; B" w:value="ab"/>`, "w:displayText", "A & B", true},
+		{`<w:date w:fullDate='2024-01-02T00:00:00Z'>`, "w:fullDate", "2024-01-02T00:00:00Z", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, found := Attr(tt.tag, tt.name)
+			if got != tt.expected || found != tt.found {
+				t.Errorf("Attr(%q, %q) = %q, %v; want %q, %v", tt.tag, tt.name, got, found, tt.expected, tt.found)
+			}
+		})
+	}
+}
+
+func TestSetAttr(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+	}{
+		{`<w:date w:fullDate="2020-01-01T00:00:00Z">`, `<w:date w:fullDate="2024-05-01">`},
+		{`<w:date>`, `<w:date w:fullDate="2024-05-01">`},
+		{`<w:date/>`, `<w:date w:fullDate="2024-05-01"/>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if got := SetAttr(tt.tag, "w:fullDate", "2024-05-01"); got != tt.expected {
+				t.Errorf("SetAttr(%q) = %q, want %q", tt.tag, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUnescapeXML(t *testing.T) {
+	if got := UnescapeXML("&lt;Tag&gt; &amp; &#169;"); got != "<Tag> & ©" {
+		t.Errorf("UnescapeXML returned %q", got)
+	}
+}
@@ -1,26 +1,234 @@
 package docxer
 
 import (
+	"context"
+	"io"
 	"path/filepath"
 
+	"github.com/aliamerj/docxer/internal/contentcontrol"
 	"github.com/aliamerj/docxer/internal/document"
+	"github.com/aliamerj/docxer/internal/docxerr"
+	"github.com/aliamerj/docxer/internal/lint"
+	"github.com/aliamerj/docxer/internal/locale"
 	"github.com/aliamerj/docxer/internal/markdown"
+	"github.com/aliamerj/docxer/internal/model"
 	"github.com/aliamerj/docxer/internal/placeholder"
+	"github.com/aliamerj/docxer/internal/properties"
 	"github.com/aliamerj/docxer/internal/utils"
 )
 
+var (
+	ErrInvalidPath      = docxerr.ErrInvalidPath
+	ErrTemplateNotFound = docxerr.ErrTemplateNotFound
+	ErrInvalidPackage   = docxerr.ErrInvalidPackage
+	ErrTemplateSyntax   = docxerr.ErrTemplateSyntax
+	ErrMissingKey       = docxerr.ErrMissingKey
+)
+
+type TemplateSyntaxError = docxerr.TemplateSyntaxError
+type MissingKeyError = docxerr.MissingKeyError
+type InvalidPackageError = docxerr.InvalidPackageError
+
+type LintIssue = lint.Issue
+type LintOptions = lint.Options
+
+type Properties = properties.Properties
+type CoreProperties = properties.Core
+type AppProperties = properties.App
+
+type RichValue = placeholder.RichValue
+type RichParagraph = placeholder.RichParagraph
+type RichRun = placeholder.RichRun
+type Link = placeholder.Link
+type Transformer = utils.Transformer
+type TransformerFunc = utils.TransformerFunc
+type Scope = placeholder.Scope
+type Resolver = placeholder.Resolver
+type Table = placeholder.Table
+type TableColumn = placeholder.TableColumn
+type Barcode = placeholder.Barcode
+
+type Document = model.Document
+type Section = model.Section
+type Block = model.Block
+type Paragraph = model.Paragraph
+type ParagraphProperties = model.ParagraphProperties
+type Inline = model.Inline
+type Run = model.Run
+type RunProperties = model.RunProperties
+type Fonts = model.Fonts
+type Spacing = model.Spacing
+type Indent = model.Indent
+type TabStop = model.TabStop
+type ParagraphBorders = model.ParagraphBorders
+type Break = model.Break
+type BreakType = model.BreakType
+type Image = model.Image
+type Floating = model.Floating
+type WrapType = model.WrapType
+type TableBlock = model.Table
+type TableProperties = model.TableProperties
+type Row = model.Row
+type RowProperties = model.RowProperties
+type Cell = model.Cell
+type CellProperties = model.CellProperties
+type Width = model.Width
+type WidthType = model.WidthType
+type VerticalMerge = model.VerticalMerge
+type Border = model.Border
+type Borders = model.Borders
+type Padding = model.Padding
+type TableLook = model.TableLook
+type RawBlock = model.RawBlock
+type RawInline = model.RawInline
+type HeaderFooter = model.HeaderFooter
+type HeaderFooterType = model.HeaderFooterType
+type Field = model.Field
+type PageSetup = model.PageSetup
+type PageSize = model.PageSize
+type Margins = model.Margins
+type SectionStart = model.SectionStart
+type Style = model.Style
+type Relationship = model.Relationship
+type List = model.List
+type ListLevel = model.ListLevel
+
+const (
+	LineBreak   = model.LineBreak
+	PageBreak   = model.PageBreak
+	ColumnBreak = model.ColumnBreak
+
+	WidthUnset   = model.WidthUnset
+	WidthAuto    = model.WidthAuto
+	WidthTwips   = model.WidthTwips
+	WidthPercent = model.WidthPercent
+
+	DefaultPages = model.DefaultPages
+	FirstPage    = model.FirstPage
+	EvenPages    = model.EvenPages
+
+	StartNextPage   = model.StartNextPage
+	StartContinuous = model.StartContinuous
+	StartNextColumn = model.StartNextColumn
+	StartEvenPage   = model.StartEvenPage
+	StartOddPage    = model.StartOddPage
+
+	WrapSquare        = model.WrapSquare
+	WrapTight         = model.WrapTight
+	WrapTopAndBottom  = model.WrapTopAndBottom
+	WrapBehindText    = model.WrapBehindText
+	WrapInFrontOfText = model.WrapInFrontOfText
+
+	NoMerge       = model.NoMerge
+	MergeRestart  = model.MergeRestart
+	MergeContinue = model.MergeContinue
+)
+
+var AutoWidth = model.AutoWidth
+
+var (
+	A3     = model.A3
+	A4     = model.A4
+	A5     = model.A5
+	Letter = model.Letter
+	Legal  = model.Legal
+)
+
+// BulletList returns the levels of a bulleted list for Document.AddList,
+// using bullets in turn.
+func BulletList(bullets ...string) []ListLevel {
+	return model.BulletList(bullets...)
+}
+
+// DecimalList returns the levels of a list numbered 1., 2., 3.
+func DecimalList() []ListLevel {
+	return model.DecimalList()
+}
+
+// LetterList returns the levels of a list numbered a., b., c.
+func LetterList() []ListLevel {
+	return model.LetterList()
+}
+
+// RomanList returns the levels of a list numbered i., ii., iii.
+func RomanList() []ListLevel {
+	return model.RomanList()
+}
+
+// LegalList returns the levels of a list numbered 1., 1.1., 1.1.1.
+func LegalList() []ListLevel {
+	return model.LegalList()
+}
+
+// PageLayout returns a transformer that gives the last section of documents
+// made with CreateNewDocx or CreateMarkdownDocx the page setup page. Zero
+// sizes and margins keep those of the template.
+func PageLayout(page PageSetup) Transformer {
+	return model.PageTransformer(page)
+}
+
+// Twips returns a table, column or cell width in twentieths of a point.
+func Twips(twips int) Width {
+	return model.Twips(twips)
+}
+
+// Percent returns a table, column or cell width in percent of the available
+// width.
+func Percent(percent float64) Width {
+	return model.Percent(percent)
+}
+
+// AllBorders returns table or cell borders drawing border on every side.
+func AllBorders(border Border) *Borders {
+	return model.AllBorders(border)
+}
+
 type docxer struct {
-	Title string
-	Body  string
+	Title        string
+	Body         string
+	Transformers []Transformer
 }
 type holder struct {
-	filePath string
+	filePath  string
+	locale    *locale.Locale
+	localeErr error
 }
 
 func Placeholder(filePath string) *holder {
 	return &holder{filePath: filePath}
 }
 
+// WithLocale returns a holder whose Text, Resolve and Tables write numbers,
+// amounts of money and dates for a BCP 47 locale such as "de-DE" or "ar-SA".
+// Dates are written in English, German and Arabic only, so a locale of
+// another language is invalid. An invalid locale is reported by those methods.
+func (h *holder) WithLocale(name string) *holder {
+	localized := *h
+	localized.locale, localized.localeErr = locale.Parse(name)
+	return &localized
+}
+
+// NewDocument returns an empty document to build with AddParagraph,
+// AddTable and the other builder methods, and to write with Save.
+func NewDocument() *Document {
+	return model.New()
+}
+
+// Open reads an existing DOCX file into a document. Markup the document model
+// does not know is kept, so saving the document without changes writes the
+// same content.
+func Open(filePath string) (*Document, error) {
+	if err := utils.ValidateFilePath(filepath.Dir(filePath)); err != nil {
+		return nil, err
+	}
+	return model.Open(filePath)
+}
+
+// OpenReader reads a DOCX package of size bytes from r into a document.
+func OpenReader(r io.ReaderAt, size int64) (*Document, error) {
+	return model.Read(r, size)
+}
+
 func NewDocx() *docxer {
 	return &docxer{}
 }
@@ -28,19 +236,19 @@ func (d *docxer) CreateNewDocx(filePath string) (string, error) {
 	if err := utils.ValidateFilePath(filePath); err != nil {
 		return "", err
 	}
-	path, err := document.CreateNewDocx(filePath, d.Title, d.Body)
+	path, err := document.CreateNewDocx(filePath, d.Title, d.Body, d.Transformers...)
 	if err != nil {
 		return "", err
 	}
 	return path, nil
 }
 
-func CreateMarkdownDocx(filePath string, markdownText string) (string, error) {
+func CreateMarkdownDocx(filePath string, markdownText string, transformers ...Transformer) (string, error) {
 	if err := utils.ValidateFilePath(filePath); err != nil {
 		return "", err
 	}
 
-	path, err := markdown.CreateMarkdownDocx(filePath, markdownText)
+	path, err := markdown.CreateMarkdownDocx(filePath, markdownText, transformers...)
 	if err != nil {
 		return "", err
 	}
@@ -52,7 +260,10 @@ func (h *holder) Text(replacements map[string]string) error {
 	if err := utils.ValidateFilePath(dirPath); err != nil {
 		return err
 	}
-	action := placeholder.TextPlaceholderWriter(replacements)
+	if h.localeErr != nil {
+		return h.localeErr
+	}
+	action := placeholder.TextPlaceholderWriter(replacements, h.locale)
 	err := placeholder.UpdateDocx(h.filePath, action)
 	if err != nil {
 		return err
@@ -72,3 +283,130 @@ func (h *holder) Loop(loop map[string]interface{}) error {
 
 	return nil
 }
+
+func (h *holder) Transform(transformers ...Transformer) error {
+	dirPath := filepath.Dir(h.filePath)
+	if err := utils.ValidateFilePath(dirPath); err != nil {
+		return err
+	}
+	return placeholder.TransformDocx(h.filePath, utils.Chain(transformers...))
+}
+
+func MapResolver(values map[string]any) Resolver {
+	return placeholder.MapResolver(values)
+}
+
+func (h *holder) Resolve(ctx context.Context, resolver Resolver) error {
+	dirPath := filepath.Dir(h.filePath)
+	if err := utils.ValidateFilePath(dirPath); err != nil {
+		return err
+	}
+	if h.localeErr != nil {
+		return h.localeErr
+	}
+	return placeholder.UpdateDocx(h.filePath, placeholder.ResolverWriter(ctx, resolver, h.locale))
+}
+
+func (h *holder) RichText(values map[string]RichValue) error {
+	dirPath := filepath.Dir(h.filePath)
+	if err := utils.ValidateFilePath(dirPath); err != nil {
+		return err
+	}
+	return placeholder.UpdateParts(h.filePath, placeholder.RichTextWriter(values))
+}
+
+func (h *holder) Links(links map[string]Link) error {
+	dirPath := filepath.Dir(h.filePath)
+	if err := utils.ValidateFilePath(dirPath); err != nil {
+		return err
+	}
+	return placeholder.UpdateParts(h.filePath, placeholder.HyperlinkWriter(links))
+}
+
+func (h *holder) Tables(tables map[string]Table) error {
+	dirPath := filepath.Dir(h.filePath)
+	if err := utils.ValidateFilePath(dirPath); err != nil {
+		return err
+	}
+	if h.localeErr != nil {
+		return h.localeErr
+	}
+	writer, err := placeholder.TableWriter(tables, h.locale)
+	if err != nil {
+		return err
+	}
+	return placeholder.UpdateParts(h.filePath, writer)
+}
+
+func (h *holder) Barcodes(barcodes map[string]Barcode) error {
+	dirPath := filepath.Dir(h.filePath)
+	if err := utils.ValidateFilePath(dirPath); err != nil {
+		return err
+	}
+	writer, err := placeholder.BarcodeWriter(barcodes)
+	if err != nil {
+		return err
+	}
+	return placeholder.UpdateParts(h.filePath, writer)
+}
+
+func (h *holder) ContentControls(values map[string]any) error {
+	dirPath := filepath.Dir(h.filePath)
+	if err := utils.ValidateFilePath(dirPath); err != nil {
+		return err
+	}
+	action := contentcontrol.ContentControlWriter(values)
+	err := placeholder.UpdateDocx(h.filePath, action)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func Lint(template string, options ...LintOptions) ([]LintIssue, error) {
+	dirPath := filepath.Dir(template)
+	if err := utils.ValidateFilePath(dirPath); err != nil {
+		return nil, err
+	}
+	var opts LintOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	return lint.Lint(template, opts)
+}
+
+func ExtractData(filePath string) (map[string]any, error) {
+	dirPath := filepath.Dir(filePath)
+	if err := utils.ValidateFilePath(dirPath); err != nil {
+		return nil, err
+	}
+	return contentcontrol.ExtractData(filePath)
+}
+
+func (h *holder) CustomXML(payload any) error {
+	dirPath := filepath.Dir(h.filePath)
+	if err := utils.ValidateFilePath(dirPath); err != nil {
+		return err
+	}
+	data, err := contentcontrol.CustomXMLPayload(payload)
+	if err != nil {
+		return err
+	}
+	return contentcontrol.UpdateCustomXML(h.filePath, data)
+}
+
+func (h *holder) Properties() (Properties, error) {
+	dirPath := filepath.Dir(h.filePath)
+	if err := utils.ValidateFilePath(dirPath); err != nil {
+		return Properties{}, err
+	}
+	return properties.Read(h.filePath)
+}
+
+func (h *holder) SetProperties(props Properties) error {
+	dirPath := filepath.Dir(h.filePath)
+	if err := utils.ValidateFilePath(dirPath); err != nil {
+		return err
+	}
+	return properties.Write(h.filePath, props)
+}
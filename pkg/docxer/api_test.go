@@ -0,0 +1,28 @@
+package docxer
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "report.docx")
+	doc := NewDocument()
+	doc.AddParagraph("Quarterly report")
+	if err := doc.Save(filePath); err != nil {
+		t.Fatalf("Expected no error saving the document, got %v", err)
+	}
+
+	opened, err := Open(filePath)
+	if err != nil {
+		t.Fatalf("Expected no error opening %s, got %v", filePath, err)
+	}
+	if paragraphs := opened.Paragraphs(); len(paragraphs) != 1 || paragraphs[0].Text() != "Quarterly report" {
+		t.Errorf("Expected the saved paragraph, got %+v", paragraphs)
+	}
+
+	if _, err := Open(filepath.Join(t.TempDir(), "missing", "report.docx")); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("Expected ErrInvalidPath for a missing directory, got %v", err)
+	}
+}